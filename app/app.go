@@ -0,0 +1,601 @@
+// Package app implements the logic of the datos command line tool: finding
+// the datasets matching a query, downloading them and processing the
+// downloaded files. It can be used to embed the tool in other programs
+// without executing the binary.
+package app
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/erizocosmico/datos"
+	"github.com/sirupsen/logrus"
+)
+
+// Config of the datasets to find and how to download and process them.
+type Config struct {
+	// Output is the directory where datasets are downloaded. It is created
+	// if it does not exist.
+	Output string
+	// Max is the maximum number of datasets to download. If it's 0, all the
+	// datasets found are downloaded.
+	Max int
+	// Verbose logs the datasets skipped and the reason why.
+	Verbose bool
+	// Concurrency is the number of datasets downloaded at the same time.
+	// By default, 1.
+	Concurrency int
+	// Retries is the number of times a failed download is retried.
+	Retries int
+	// ConnectTimeout limits the time until a server starts sending a file.
+	ConnectTimeout time.Duration
+	// IdleTimeout limits the time without receiving data of a file.
+	IdleTimeout time.Duration
+	// Timeout limits the total time of a download, to which TimeoutPerMB
+	// is added for every megabyte of the file.
+	Timeout time.Duration
+	// TimeoutPerMB is added to Timeout for every megabyte of a file.
+	TimeoutPerMB time.Duration
+	// Sync flushes downloaded files to disk before closing them.
+	Sync bool
+	// Stamp sets the modification time of downloaded files to the time the
+	// dataset was modified and stores their source URL in the
+	// user.xdg.origin.url extended attribute, where supported.
+	Stamp bool
+
+	// Title filters datasets by title. It may contain * and ? wildcards.
+	Title string
+	// TitleRegexp filters datasets by titles matching a regular expression.
+	TitleRegexp string
+	// Keyword filters datasets by keyword. It may contain * and ? wildcards.
+	Keyword string
+	// Theme filters datasets by theme.
+	Theme string
+	// Publisher filters datasets by publisher.
+	Publisher string
+	// Format filters datasets by format (csv, json, xml, kml or shp).
+	Format string
+	// Filter is a boolean expression to filter datasets, such as:
+	// theme == "salud" && modified > "2024-01-01".
+	Filter string
+
+	// OnlyNewer skips the datasets not modified since the local copy was
+	// downloaded.
+	OnlyNewer bool
+	// OnlyUpdated skips the datasets not modified since the version
+	// recorded in the manifest of the output directory was downloaded.
+	OnlyUpdated bool
+	// FlattenJSON converts JSON files with a list of records to CSV.
+	FlattenJSON bool
+	// JSONPath is the dot separated path of the list of records in JSON
+	// files. By default, it's guessed.
+	JSONPath string
+	// ConvertXML converts XML files to the given format (csv or json).
+	ConvertXML string
+	// XMLPath is the path of the records in XML files, using a subset of
+	// XPath. By default, the children of the root element.
+	XMLPath string
+	// GeoJSON converts KML files and zipped shapefiles to GeoJSON.
+	GeoJSON bool
+	// Normalize converts CSV files to comma separated values with dot
+	// decimals and ISO 8601 dates.
+	Normalize bool
+	// ColumnRules is the path of a file with rules to rename and coerce the
+	// columns of CSV files.
+	ColumnRules string
+	// Redact is a comma separated list of patterns (dni, nie, phone, email,
+	// iban or a regexp) whose matching columns are removed from CSV files.
+	Redact string
+	// Sample is the number of rows of a random sample of CSV files to keep.
+	Sample int
+	// SampleDatasets is the number of datasets of a random sample of the
+	// datasets found to download, instead of all of them. No filter is
+	// required when sampling, so the whole catalog can be sampled.
+	SampleDatasets int
+	// Seed of the random numbers used to sample datasets and rows, so runs
+	// can be reproduced. If it's 0, a random seed is used and logged.
+	Seed int64
+	// StratifyBy keeps the proportion of datasets of every theme or
+	// publisher in the sample of datasets. It can be theme or publisher.
+	StratifyBy string
+	// PIIReport is the path of the file where the personal data found in
+	// CSV files is reported.
+	PIIReport string
+	// Feather writes a Feather file next to every CSV file, with the types
+	// of the columns inferred from their values, to be read with pandas.
+	Feather bool
+	// Readr writes a CSV file for R's readr next to every CSV file, with
+	// a JSON file describing the types of its columns and its encoding.
+	Readr bool
+}
+
+// App finds, downloads and processes the datasets described by a Config.
+type App struct {
+	client   *datos.Client
+	config   Config
+	query    queryFunc
+	filters  []datasetFilter
+	pipeline pipeline
+	report   *piiReport
+	warnings datos.Warnings
+	outcome  Outcome
+	rnd      *rand.Rand
+	seed     int64
+}
+
+type queryFunc func(context.Context, datos.Params) ([]datos.Dataset, error)
+
+// New creates a new App using the given client and configuration.
+func New(client *datos.Client, config Config) (*App, error) {
+	c := config
+	if c.Title == "" && c.TitleRegexp == "" && c.Filter == "" && c.Keyword == "" &&
+		c.Theme == "" && c.Publisher == "" && c.Format == "" && c.SampleDatasets <= 0 {
+		return nil, fmt.Errorf("at least one of title, title regexp, filter, keyword, theme, publisher or format must be provided")
+	}
+
+	if c.StratifyBy != "" && c.StratifyBy != "theme" && c.StratifyBy != "publisher" {
+		return nil, fmt.Errorf("invalid stratification: %s, must be theme or publisher", c.StratifyBy)
+	}
+
+	seed := c.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+		if c.Sample > 0 || c.SampleDatasets > 0 {
+			logrus.Infof("sampling with seed %d", seed)
+		}
+	}
+
+	app := &App{
+		client: client,
+		config: config,
+		rnd:    rand.New(rand.NewSource(seed)),
+		seed:   seed,
+	}
+	if err := app.buildFilters(); err != nil {
+		return nil, err
+	}
+
+	if err := app.buildPipeline(); err != nil {
+		return nil, err
+	}
+
+	app.buildQuery()
+	return app, nil
+}
+
+func (a *App) buildFilters() error {
+	c := a.config
+	if c.Title != "" {
+		a.filters = append(a.filters, textFilter(c.Title, datasetTitles))
+	}
+
+	if c.TitleRegexp != "" {
+		re, err := compileFilterRegexp(c.TitleRegexp)
+		if err != nil {
+			return err
+		}
+		a.filters = append(a.filters, regexpFilter(re, datasetTitles))
+	}
+
+	if c.Keyword != "" {
+		a.filters = append(a.filters, textFilter(c.Keyword, datasetKeywords))
+	}
+
+	if c.Theme != "" {
+		a.filters = append(a.filters, linkFilter(c.Theme, datasetThemes))
+	}
+
+	if c.Publisher != "" {
+		a.filters = append(a.filters, linkFilter(c.Publisher, datasetPublishers))
+	}
+
+	if c.Filter != "" {
+		f, err := parseFilterExpr(c.Filter)
+		if err != nil {
+			return err
+		}
+		a.filters = append(a.filters, f)
+	}
+
+	return nil
+}
+
+func (a *App) buildPipeline() error {
+	c := a.config
+	if c.FlattenJSON {
+		a.pipeline.convert = append(a.pipeline.convert, flattenJSON(c.JSONPath))
+	}
+
+	if c.GeoJSON {
+		a.pipeline.convert = append(a.pipeline.convert, convertGeo())
+	}
+
+	if c.ConvertXML != "" {
+		convert, err := convertXML(c.ConvertXML, c.XMLPath)
+		if err != nil {
+			return err
+		}
+		a.pipeline.convert = append(a.pipeline.convert, convert)
+	}
+
+	if c.Normalize {
+		a.pipeline.process = append(a.pipeline.process, normalize())
+	}
+
+	if c.ColumnRules != "" {
+		rules, err := parseColumnRules(c.ColumnRules)
+		if err != nil {
+			return err
+		}
+		a.pipeline.process = append(a.pipeline.process, rules.process())
+	}
+
+	if c.Redact != "" {
+		patterns, err := parsePatterns(c.Redact)
+		if err != nil {
+			return err
+		}
+		a.pipeline.process = append(a.pipeline.process, redact(patterns))
+	}
+
+	if c.Sample > 0 {
+		a.pipeline.process = append(a.pipeline.process, sampleRows(c.Sample, a.seed))
+	}
+
+	if c.PIIReport != "" {
+		a.report = new(piiReport)
+		a.pipeline.process = append(a.pipeline.process, a.report.process())
+	}
+
+	// Feather and readr files are written last, so they have the final
+	// contents of the CSV files.
+	if c.Feather {
+		a.pipeline.process = append(a.pipeline.process, exportFeather())
+	}
+
+	if c.Readr {
+		a.pipeline.process = append(a.pipeline.process, exportReadr())
+	}
+
+	return nil
+}
+
+// buildQuery chooses the API endpoint used to find datasets. The API has
+// no endpoints combining several filters, so the most selective one is used
+// and all the filters are applied locally. The format is applied when
+// selecting the distribution of each dataset.
+func (a *App) buildQuery() {
+	c := a.config
+	switch {
+	case c.Title != "" && globLiteral(c.Title) != "":
+		a.query = func(ctx context.Context, p datos.Params) ([]datos.Dataset, error) {
+			return a.client.DatasetsByTitle(ctx, globLiteral(c.Title), p)
+		}
+	case c.Keyword != "" && globLiteral(c.Keyword) != "":
+		a.query = func(ctx context.Context, p datos.Params) ([]datos.Dataset, error) {
+			return a.client.DatasetsByKeyword(ctx, globLiteral(c.Keyword), p)
+		}
+	case c.Publisher != "":
+		a.query = func(ctx context.Context, p datos.Params) ([]datos.Dataset, error) {
+			return a.client.DatasetsByPublisher(ctx, c.Publisher, p)
+		}
+	case c.Theme != "":
+		a.query = func(ctx context.Context, p datos.Params) ([]datos.Dataset, error) {
+			return a.client.DatasetsByTheme(ctx, c.Theme, p)
+		}
+	case c.Format != "":
+		a.query = func(ctx context.Context, p datos.Params) ([]datos.Dataset, error) {
+			return a.client.DatasetsByFormat(ctx, c.Format, p)
+		}
+	default:
+		a.query = a.client.Datasets
+	}
+}
+
+// Run finds the datasets, downloads them and processes the downloaded
+// files.
+func (a *App) Run(ctx context.Context) error {
+	a.outcome = Outcome{Command: "download", Started: time.Now()}
+	datasets, err := a.Plan(ctx)
+	if err != nil {
+		return err
+	}
+
+	return a.Download(ctx, datasets)
+}
+
+// Plan returns the datasets that Run would download: the ones found or a
+// sample of them.
+func (a *App) Plan(ctx context.Context) ([]Dataset, error) {
+	var datasets []Dataset
+	var err error
+	if a.config.SampleDatasets > 0 {
+		a.outcome.Command = "sample"
+		datasets, err = a.sampleDatasets(ctx)
+	} else {
+		datasets, err = a.Find(ctx)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	a.outcome.Found = len(datasets)
+	a.outcome.Warnings = a.warnings.Len()
+	if n := a.warnings.Len(); n > 0 {
+		logrus.Warnf("skipped %d dataset(s) that could not be decoded", n)
+		if a.config.Verbose {
+			for _, w := range a.warnings.List() {
+				logrus.Warn(w)
+			}
+		}
+	}
+
+	return datasets, nil
+}
+
+// Find returns the datasets matching the configuration that have a
+// distribution that can be downloaded.
+func (a *App) Find(ctx context.Context) ([]Dataset, error) {
+	var result []Dataset
+	format := formats[strings.ToLower(a.config.Format)]
+	err := a.each(ctx, func(ds datos.Dataset) bool {
+		d, ok := a.selectDistribution(ds, format)
+		if !ok {
+			return true
+		}
+
+		result = append(result, d)
+		return a.config.Max <= 0 || len(result) < a.config.Max
+	})
+	return result, err
+}
+
+// Search returns the metadata of the datasets matching the configuration,
+// including the ones that have no distribution that can be downloaded.
+func (a *App) Search(ctx context.Context) ([]datos.Dataset, error) {
+	var result []datos.Dataset
+	format := formats[strings.ToLower(a.config.Format)]
+	err := a.each(ctx, func(ds datos.Dataset) bool {
+		if format != "" && !hasFormat(ds, format) {
+			return true
+		}
+
+		result = append(result, ds)
+		return a.config.Max <= 0 || len(result) < a.config.Max
+	})
+	return result, err
+}
+
+// each calls fn with every dataset matching the filters until it returns
+// false or there are no more datasets.
+func (a *App) each(ctx context.Context, fn func(datos.Dataset) bool) error {
+	ctx = datos.CollectWarnings(ctx, &a.warnings)
+	var page datos.Page
+	ctx = datos.CapturePage(ctx, &page)
+	params := datos.Params{
+		Page:     0,
+		PageSize: 100,
+	}
+
+	for {
+		page = datos.Page{}
+		datasets, err := a.query(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		for _, ds := range datasets {
+			if !matchAll(a.filters, ds) {
+				continue
+			}
+
+			if !fn(ds) {
+				return nil
+			}
+		}
+
+		// Items that could not be decoded are skipped, so the paging
+		// information is used when there is any.
+		if page.Size > 0 && !page.HasNext() || page.Size == 0 && len(datasets) < int(params.PageSize) {
+			return nil
+		}
+
+		params.Page++
+	}
+}
+
+func hasFormat(ds datos.Dataset, format string) bool {
+	for _, d := range ds.Distribution {
+		if d.Format.Value == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Outcome returns the summary of the last run. It must be finished by the
+// caller with the error returned by Run.
+func (a *App) Outcome() Outcome {
+	return a.outcome
+}
+
+// Warnings returns the datasets that were skipped while finding datasets
+// because they could not be decoded, and the reason why.
+func (a *App) Warnings() []datos.DecodeWarning {
+	return a.warnings.List()
+}
+
+func (a *App) selectDistribution(ds datos.Dataset, format string) (Dataset, bool) {
+	var url string
+	var size int64
+	for _, d := range ds.Distribution {
+		if (format == "" && allowedFormats[d.Format.Value]) || d.Format.Value == format {
+			url = d.AccessURL
+			size = int64(d.ByteSize)
+			break
+		}
+	}
+
+	var id = ds.Identifier
+	title := ds.Title.Default()
+	if id == "" {
+		id = title
+	}
+
+	if ds.Identifier == "" {
+		if a.config.Verbose {
+			logrus.Warn("found dataset with no identifier")
+		}
+		return Dataset{}, false
+	}
+
+	if url == "" {
+		if a.config.Verbose {
+			var txt = id
+			if id == "" {
+				txt = title
+			}
+
+			logrus.Warnf("no suitable distribution found for dataset: %s", txt)
+		}
+		return Dataset{}, false
+	}
+
+	return Dataset{
+		URL:      url,
+		Title:    title,
+		ID:       slugify(id, ds.Issued.Time),
+		Modified: ds.Modified.Time,
+		Size:     size,
+	}, true
+}
+
+// Download downloads the given datasets into the output directory and
+// processes the downloaded files. Datasets that fail do not stop the rest
+// from being downloaded; they are returned at the end in a DownloadError.
+func (a *App) Download(ctx context.Context, datasets []Dataset) error {
+	if err := ensureDir(a.config.Output); err != nil {
+		return err
+	}
+
+	manifest, err := ReadManifest(a.config.Output)
+	if err != nil {
+		return err
+	}
+
+	var jobs []datos.DownloadJob
+	byID := make(map[string]Dataset)
+	for _, d := range datasets {
+		if (a.config.OnlyNewer && isUpToDate(d, a.config.Output)) ||
+			(a.config.OnlyUpdated && !manifest.isUpdated(d)) {
+			if a.config.Verbose {
+				logrus.Infof("skipping dataset %q, local copy is up to date", d.Title)
+			}
+			a.outcome.Skipped++
+			continue
+		}
+
+		// Datasets with the same ID would be downloaded to the same file.
+		if _, ok := byID[d.ID]; ok {
+			continue
+		}
+
+		jobs = append(jobs, downloadJob(d, a.config.Output))
+		byID[d.ID] = d
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	downloader := datos.NewDownloader(a.client, a.config.Concurrency, a.config.Retries)
+	downloader.SetOptions(datos.DownloadOptions{
+		ConnectTimeout: a.config.ConnectTimeout,
+		IdleTimeout:    a.config.IdleTimeout,
+		Timeout:        a.config.Timeout,
+		TimeoutPerMB:   a.config.TimeoutPerMB,
+		Sync:           a.config.Sync,
+	})
+
+	var failed DownloadError
+	for r := range downloader.Download(ctx, jobs) {
+		d := byID[r.Job.ID]
+		path, err := a.finish(d, r, manifest)
+		if err != nil {
+			a.outcome.Failed++
+			logrus.Errorf("error downloading dataset %s: %s", d.ID, err)
+			failed = append(failed, Failure{d, err})
+		} else {
+			a.outcome.Downloaded++
+			a.outcome.Files = append(a.outcome.Files, path)
+		}
+	}
+
+	if err := manifest.write(a.config.Output); err != nil {
+		return err
+	}
+
+	if a.report != nil {
+		if err := a.report.write(a.config.PIIReport); err != nil {
+			return err
+		}
+	}
+
+	if len(failed) > 0 {
+		return failed
+	}
+
+	return nil
+}
+
+// finish moves a downloaded file to its final path, records it in the
+// manifest and processes it.
+func (a *App) finish(d Dataset, r datos.DownloadResult, manifest *Manifest) (string, error) {
+	if r.Err != nil {
+		return "", r.Err
+	}
+
+	path, err := finishDownload(d, a.config.Output, r.Info)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := a.pipeline.run(path)
+	if err != nil {
+		return "", err
+	}
+
+	// The file may have been processed in place or converted to another
+	// one, removing the downloaded file, so the resulting file is hashed
+	// after processing to be able to verify it later.
+	if err := manifest.add(d, result); err != nil {
+		return "", err
+	}
+
+	if a.config.Stamp {
+		if err := stamp(result, d); err != nil {
+			logrus.Warnf("unable to stamp %s: %s", result, err)
+		}
+	}
+
+	return result, nil
+}
+
+func ensureDir(dir string) error {
+	fi, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return os.MkdirAll(dir, 0755)
+	} else if err != nil {
+		return err
+	}
+
+	if !fi.IsDir() {
+		return fmt.Errorf("output directory %s exists and is not a directory", dir)
+	}
+
+	return nil
+}
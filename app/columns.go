@@ -0,0 +1,235 @@
+package app
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/erizocosmico/datos"
+	"github.com/sirupsen/logrus"
+)
+
+// columnRules are declarative rules to canonicalize the columns of
+// downloaded CSV files. They are read from a file with one rule per line:
+//
+//	# lines starting with # are ignored
+//	headers trim lower ascii snake
+//	rename "Código Postal" cp
+//	type cp int
+//
+// The headers rule applies the given transformations to all headers, rename
+// renames a column (matching either its original or transformed name) and
+// type coerces the values of a column to int, float, bool or date. Values
+// that cannot be coerced are left empty.
+type columnRules struct {
+	headers []string
+	rename  map[string]string
+	types   map[string]string
+}
+
+var headerTransforms = map[string]func(string) string{
+	"trim":  strings.TrimSpace,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"ascii": datos.StripAccents,
+	"snake": snakeCase,
+}
+
+var coercions = map[string]func(string) (string, bool){
+	"int":   coerceInt,
+	"float": coerceFloat,
+	"bool":  coerceBool,
+	"date":  coerceDate,
+}
+
+func parseColumnRules(path string) (*columnRules, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rules := &columnRules{
+		rename: make(map[string]string),
+		types:  make(map[string]string),
+	}
+
+	s := bufio.NewScanner(f)
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSpace(s.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		r := csv.NewReader(strings.NewReader(text))
+		r.Comma = ' '
+		r.TrimLeadingSpace = true
+		rule, err := r.Read()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err)
+		}
+
+		switch rule[0] {
+		case "headers":
+			for _, t := range rule[1:] {
+				if _, ok := headerTransforms[t]; !ok {
+					return nil, fmt.Errorf("line %d: unknown header transformation %q", line, t)
+				}
+			}
+			rules.headers = append(rules.headers, rule[1:]...)
+		case "rename":
+			if len(rule) != 3 {
+				return nil, fmt.Errorf("line %d: rename expects a column and a new name", line)
+			}
+			rules.rename[rule[1]] = rule[2]
+		case "type":
+			if len(rule) != 3 {
+				return nil, fmt.Errorf("line %d: type expects a column and a type", line)
+			}
+
+			if _, ok := coercions[rule[2]]; !ok {
+				return nil, fmt.Errorf("line %d: unknown type %q", line, rule[2])
+			}
+			rules.types[rule[1]] = rule[2]
+		default:
+			return nil, fmt.Errorf("line %d: unknown rule %q", line, rule[0])
+		}
+	}
+
+	return rules, s.Err()
+}
+
+func (r *columnRules) header(name string) string {
+	original := name
+	for _, t := range r.headers {
+		name = headerTransforms[t](name)
+	}
+
+	if n, ok := r.rename[original]; ok {
+		return n
+	}
+
+	if n, ok := r.rename[name]; ok {
+		return n
+	}
+
+	return name
+}
+
+// process returns a process function that applies the rules to downloaded
+// CSV files.
+func (r *columnRules) process() processFunc {
+	return func(path string) error {
+		if filepath.Ext(path) != ".csv" {
+			return nil
+		}
+
+		delim, err := detectDelimiter(path)
+		if err != nil {
+			return err
+		}
+
+		var types []func(string) (string, bool)
+		var invalid int
+		err = rewriteCSV(path, delim, func(i int, record []string) []string {
+			if i == 0 {
+				types = make([]func(string) (string, bool), len(record))
+				for j, h := range record {
+					record[j] = r.header(strings.TrimPrefix(h, "\ufeff"))
+					if t, ok := r.types[record[j]]; ok {
+						types[j] = coercions[t]
+					}
+				}
+				return record
+			}
+
+			for j, v := range record {
+				if j >= len(types) || types[j] == nil || strings.TrimSpace(v) == "" {
+					continue
+				}
+
+				var ok bool
+				if record[j], ok = types[j](v); !ok {
+					invalid++
+				}
+			}
+			return record
+		})
+		if err != nil {
+			return err
+		}
+
+		if invalid > 0 {
+			logrus.Warnf("%d value(s) could not be coerced to their column type in %s", invalid, path)
+		}
+		return nil
+	}
+}
+
+// thousandsDot matches integers with dots as thousands separator. These
+// are ambiguous for normalizeValue, but not when the value must be an int.
+var thousandsDot = regexp.MustCompile(`^-?\d{1,3}(\.\d{3})+$`)
+
+func snakeCase(s string) string {
+	var result []rune
+	for _, r := range strings.TrimSpace(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			result = append(result, unicode.ToLower(r))
+		} else if len(result) > 0 && result[len(result)-1] != '_' {
+			result = append(result, '_')
+		}
+	}
+	return strings.TrimSuffix(string(result), "_")
+}
+
+func coerceInt(v string) (string, bool) {
+	v = normalizeValue(v)
+	if thousandsDot.MatchString(v) {
+		v = strings.Replace(v, ".", "", -1)
+	}
+
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return "", false
+	}
+	return strconv.FormatInt(n, 10), true
+}
+
+func coerceFloat(v string) (string, bool) {
+	n, err := strconv.ParseFloat(normalizeValue(v), 64)
+	if err != nil {
+		return "", false
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64), true
+}
+
+func coerceBool(v string) (string, bool) {
+	switch strings.ToLower(datos.StripAccents(strings.TrimSpace(v))) {
+	case "1", "s", "si", "true", "verdadero", "x":
+		return "true", true
+	case "0", "n", "no", "false", "falso":
+		return "false", true
+	default:
+		return "", false
+	}
+}
+
+func coerceDate(v string) (string, bool) {
+	v = normalizeValue(v)
+	for _, layout := range []string{"2006-01-02", "2006-01-02T15:04:05", time.RFC3339} {
+		if t, err := time.Parse(layout, v); err == nil {
+			if layout == "2006-01-02" {
+				return t.Format(layout), true
+			}
+			return t.Format(time.RFC3339), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,163 @@
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseColumnRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos-columns")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		name  string
+		rules string
+		err   string
+	}{
+		{"valid", "# comment\n\nheaders trim lower\nheaders ascii\nrename \"Código Postal\" cp\ntype cp int\n", ""},
+		{"unknown rule", "drop cp\n", `line 1: unknown rule "drop"`},
+		{"unknown transformation", "headers trim camel\n", `line 1: unknown header transformation "camel"`},
+		{"rename without name", "# a\nrename cp\n", "line 2: rename expects a column and a new name"},
+		{"type without type", "type cp\n", "line 1: type expects a column and a type"},
+		{"unknown type", "type cp decimal\n", `line 1: unknown type "decimal"`},
+		{"unbalanced quotes", "rename \"cp x\n", "line 1:"},
+	}
+
+	for _, c := range cases {
+		path := filepath.Join(dir, "rules")
+		if err := ioutil.WriteFile(path, []byte(c.rules), 0644); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		rules, err := parseColumnRules(path)
+		if c.err != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), c.err) {
+				t.Errorf("%s: expected error %q, got: %v", c.name, c.err, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+			continue
+		}
+
+		if strings.Join(rules.headers, " ") != "trim lower ascii" {
+			t.Errorf("%s: wrong header transformations: %v", c.name, rules.headers)
+		}
+
+		if rules.rename["Código Postal"] != "cp" || rules.types["cp"] != "int" {
+			t.Errorf("%s: wrong rules: %v %v", c.name, rules.rename, rules.types)
+		}
+	}
+
+	if _, err := parseColumnRules(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("expected error reading missing rules file")
+	}
+}
+
+func TestColumnRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos-columns")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	rules := &columnRules{
+		headers: []string{"trim", "ascii", "snake"},
+		rename: map[string]string{
+			"Código Postal": "cp",
+			"poblacion":     "habitantes",
+			"inexistente":   "otra",
+		},
+		types: map[string]string{
+			"cp":         "int",
+			"habitantes": "int",
+			"superficie": "float",
+			"capital":    "bool",
+			"fecha":      "date",
+			"ausente":    "int",
+		},
+	}
+
+	cases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			"rules",
+			"\ufeffCódigo Postal;Población;Superficie;¿Capital?;Fecha\n" +
+				"28001;3.223.334;604,3;Sí;18/11/2012\n" +
+				"08001;1.620.343;;no;2012-11-18\n",
+			"cp;habitantes;superficie;capital;fecha\n" +
+				"28001;3223334;604.3;true;2012-11-18\n" +
+				"8001;1620343;;false;2012-11-18\n",
+		},
+		{
+			"invalid values",
+			"cp,capital,fecha\nnone,tal vez,ayer\n",
+			"cp,capital,fecha\n,,\n",
+		},
+		{
+			"short rows",
+			"Nombre,CP,Extra\nAna\nLuis,28001,x,y\n",
+			"nombre,cp,extra\nAna\nLuis,28001,x,y\n",
+		},
+	}
+
+	for _, c := range cases {
+		path := filepath.Join(dir, "data.csv")
+		if err := ioutil.WriteFile(path, []byte(c.input), 0644); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if err := rules.process()(path); err != nil {
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+			continue
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if string(data) != c.expected {
+			t.Errorf("%s: wrong result, expected:\n%s\ngot:\n%s", c.name, c.expected, data)
+		}
+	}
+}
+
+func TestCoercions(t *testing.T) {
+	cases := []struct {
+		typ      string
+		value    string
+		expected string
+		ok       bool
+	}{
+		{"int", "1.234", "1234", true},
+		{"int", "-12", "-12", true},
+		{"int", "1,5", "", false},
+		{"float", "1.234,56", "1234.56", true},
+		{"float", "abc", "", false},
+		{"bool", "SÍ", "true", true},
+		{"bool", "Falso", "false", true},
+		{"bool", "quizá", "", false},
+		{"date", "01/02/2019", "2019-02-01", true},
+		{"date", "1/2/2019 9:05", "2019-02-01T09:05:00Z", true},
+		{"date", "32/01/2019", "", false},
+	}
+
+	for _, c := range cases {
+		result, ok := coercions[c.typ](c.value)
+		if result != c.expected || ok != c.ok {
+			t.Errorf("wrong %s coercion of %q, expected: %q %v, got: %q %v", c.typ, c.value, c.expected, c.ok, result, ok)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// detectDelimiter guesses the delimiter of a CSV file by looking at its
+// first line. Most spanish publishers use semicolons instead of commas.
+func detectDelimiter(path string) (rune, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	return guessDelimiter(line), nil
+}
+
+// guessDelimiter returns the delimiter used in the given CSV line.
+func guessDelimiter(line string) rune {
+	if strings.Count(line, ";") > strings.Count(line, ",") {
+		return ';'
+	}
+
+	return ','
+}
+
+func newCSVReader(r io.Reader, delim rune) *csv.Reader {
+	cr := csv.NewReader(r)
+	cr.Comma = delim
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	return cr
+}
+
+// rewriteCSV replaces the CSV file at path with the records returned by fn,
+// which is called once for every record in the file. If fn returns a nil
+// record, the record is dropped. The resulting file uses out as delimiter.
+func rewriteCSV(path string, out rune, fn func(i int, record []string) []string) error {
+	delim, err := detectDelimiter(path)
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp, err := os.Create(filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp"))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	r := newCSVReader(in, delim)
+	w := csv.NewWriter(tmp)
+	w.Comma = out
+
+	for i := 0; ; i++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+
+		if record = fn(i, record); record == nil {
+			continue
+		}
+
+		if err := w.Write(record); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// readCSV calls fn for every record in the CSV file at path.
+func readCSV(path string, fn func(i int, record []string) error) error {
+	delim, err := detectDelimiter(path)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := newCSVReader(f, delim)
+	for i := 0; ; i++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := fn(i, record); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/quotedprintable"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/erizocosmico/datos"
+)
+
+// DigestQuery is a saved query whose new and updated datasets are included
+// in a digest. At least one of the filters must be set.
+type DigestQuery struct {
+	// Name of the query, used as the title of its section in the digest.
+	Name      string `json:"name"`
+	Title     string `json:"title,omitempty"`
+	Keyword   string `json:"keyword,omitempty"`
+	Theme     string `json:"theme,omitempty"`
+	Publisher string `json:"publisher,omitempty"`
+	Format    string `json:"format,omitempty"`
+	Filter    string `json:"filter,omitempty"`
+}
+
+// ReadDigestQueries reads the saved queries from a JSON file with a list of
+// queries, such as:
+//
+//	[{"name": "Salud", "theme": "salud", "format": "csv"}]
+func ReadDigestQueries(path string) ([]DigestQuery, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var queries []DigestQuery
+	if err := json.Unmarshal(data, &queries); err != nil {
+		return nil, fmt.Errorf("invalid queries file %s: %s", path, err)
+	}
+
+	for i, q := range queries {
+		if q.Name == "" {
+			return nil, fmt.Errorf("query %d of %s has no name", i+1, path)
+		}
+	}
+
+	return queries, nil
+}
+
+func (q DigestQuery) config() Config {
+	return Config{
+		Title:     q.Title,
+		Keyword:   q.Keyword,
+		Theme:     q.Theme,
+		Publisher: q.Publisher,
+		Format:    q.Format,
+		Filter:    q.Filter,
+	}
+}
+
+// Digest contains the datasets published or updated in a period of time
+// matching some saved queries, meant to be sent as a newsletter.
+type Digest struct {
+	// From is the start of the period.
+	From time.Time
+	// To is the end of the period.
+	To time.Time
+	// Sections of the digest, one per query.
+	Sections []DigestSection
+}
+
+// DigestSection contains the datasets of a query in a digest.
+type DigestSection struct {
+	// Query is the name of the query.
+	Query string
+	// New datasets, published in the period of the digest.
+	New []DigestDataset
+	// Updated datasets, published before and modified in the period of the
+	// digest.
+	Updated []DigestDataset
+}
+
+// DigestDataset is a dataset in a digest.
+type DigestDataset struct {
+	DatasetMetadata
+	// Link to the page of the dataset in the portal.
+	Link string
+}
+
+// Datasets returns the number of datasets in the digest.
+func (d *Digest) Datasets() int {
+	var n int
+	for _, s := range d.Sections {
+		n += len(s.New) + len(s.Updated)
+	}
+	return n
+}
+
+// BuildDigest returns the digest of the datasets modified between from and
+// to that match the queries. The datasets modified in the period are only
+// requested once and the queries are applied to them.
+func BuildDigest(ctx context.Context, client *datos.Client, queries []DigestQuery, from, to time.Time) (*Digest, error) {
+	apps := make([]*App, len(queries))
+	for i, q := range queries {
+		a, err := New(client, q.config())
+		if err != nil {
+			return nil, fmt.Errorf("invalid query %q: %s", q.Name, err)
+		}
+		apps[i] = a
+	}
+
+	digest := &Digest{From: from, To: to, Sections: make([]DigestSection, len(queries))}
+	for i, q := range queries {
+		digest.Sections[i].Query = q.Name
+	}
+
+	var page datos.Page
+	ctx = datos.CapturePage(ctx, &page)
+	params := datos.Params{PageSize: 100, Sort: "-modified"}
+	for {
+		page = datos.Page{}
+		datasets, err := client.DatasetsModifiedBetween(ctx, from, to, params)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ds := range datasets {
+			for i, a := range apps {
+				if !a.matches(ds) {
+					continue
+				}
+
+				s := &digest.Sections[i]
+				dd := DigestDataset{NewDatasetMetadata(ds), ds.PortalURL()}
+				if !ds.Issued.IsZero() && !ds.Issued.Before(from) {
+					s.New = append(s.New, dd)
+				} else {
+					s.Updated = append(s.Updated, dd)
+				}
+			}
+		}
+
+		if page.Size > 0 && !page.HasNext() || page.Size == 0 && len(datasets) < int(params.PageSize) {
+			return digest, nil
+		}
+		params.Page++
+	}
+}
+
+// matches reports whether the dataset matches the filters of the app,
+// including its format.
+func (a *App) matches(ds datos.Dataset) bool {
+	if !matchAll(a.filters, ds) {
+		return false
+	}
+
+	format := formats[strings.ToLower(a.config.Format)]
+	return format == "" || hasFormat(ds, format)
+}
+
+var digestTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("02/01/2006")
+	},
+	"join": strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Subject}}</title></head>
+<body style="font-family: sans-serif; color: #222; max-width: 720px">
+<h1 style="font-size: 22px">{{.Subject}}</h1>
+<p>{{.Datasets}} dataset(s) published or updated between {{date .From}} and {{date .To}}.</p>
+{{range .Sections}}
+<h2 style="font-size: 18px; border-bottom: 1px solid #ddd">{{.Query}}</h2>
+{{if and (not .New) (not .Updated)}}<p style="color: #666">No new or updated datasets.</p>{{end}}
+{{if .New}}<h3 style="font-size: 15px">New</h3>
+<ul>{{range .New}}{{template "dataset" .}}{{end}}</ul>{{end}}
+{{if .Updated}}<h3 style="font-size: 15px">Updated</h3>
+<ul>{{range .Updated}}{{template "dataset" .}}{{end}}</ul>{{end}}
+{{end}}
+</body>
+</html>
+{{define "dataset"}}<li style="margin-bottom: 8px"><a href="{{.Link}}">{{.Title}}</a><br>
+<small style="color: #666">{{.Publisher}}{{if .Formats}} · {{join .Formats ", "}}{{end}}{{if not .Modified.IsZero}} · {{date .Modified}}{{end}}</small></li>
+{{end}}`))
+
+// Subject returns the subject of the digest email.
+func (d *Digest) Subject() string {
+	return fmt.Sprintf("datos.gob.es digest %s - %s", d.From.Format("02/01/2006"), d.To.Format("02/01/2006"))
+}
+
+// WriteHTML writes the digest as an HTML document.
+func (d *Digest) WriteHTML(w io.Writer) error {
+	return digestTemplate.Execute(w, struct {
+		*Digest
+		Subject  string
+		Datasets int
+	}{d, d.Subject(), d.Datasets()})
+}
+
+// WriteEmail writes the digest as an HTML email message from and to the
+// given addresses.
+func (d *Digest) WriteEmail(w io.Writer, from string, to []string) error {
+	var body bytes.Buffer
+	qw := quotedprintable.NewWriter(&body)
+	if err := d.WriteHTML(qw); err != nil {
+		return err
+	}
+
+	if err := qw.Close(); err != nil {
+		return err
+	}
+
+	headers := []string{
+		"From: " + from,
+		"To: " + strings.Join(to, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", d.Subject()),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		`Content-Type: text/html; charset="utf-8"`,
+		"Content-Transfer-Encoding: quoted-printable",
+	}
+
+	if _, err := io.WriteString(w, strings.Join(headers, "\r\n")+"\r\n\r\n"); err != nil {
+		return err
+	}
+
+	_, err := body.WriteTo(w)
+	return err
+}
+
+// Send sends the digest by email using the SMTP server at addr, which must
+// include the port, e.g. "smtp.example.com:587". If auth is nil, no
+// authentication is used.
+func (d *Digest) Send(addr string, auth smtp.Auth, from string, to []string) error {
+	if len(to) == 0 {
+		return fmt.Errorf("no recipients for the digest")
+	}
+
+	var msg bytes.Buffer
+	if err := d.WriteEmail(&msg, from, to); err != nil {
+		return err
+	}
+
+	return smtp.SendMail(addr, auth, from, to, msg.Bytes())
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erizocosmico/datos"
+)
+
+const digestResponse = `{"result": {"items": [
+	{
+		"_about": "http://datos.gob.es/apidata/catalog/dataset/nuevo",
+		"title": "Centros de salud",
+		"theme": "http://datos.gob.es/kos/sector-publico/sector/salud",
+		"issued": "2020-03-03T10:00:00Z",
+		"modified": "2020-03-03T10:00:00Z"
+	},
+	{
+		"_about": "http://datos.gob.es/apidata/catalog/dataset/viejo",
+		"title": "Farmacias & boticas",
+		"theme": "http://datos.gob.es/kos/sector-publico/sector/salud",
+		"issued": "2015-01-01T00:00:00Z",
+		"modified": "2020-03-04T10:00:00Z"
+	},
+	{
+		"_about": "http://datos.gob.es/apidata/catalog/dataset/transporte",
+		"title": "Paradas de autobus",
+		"theme": "http://datos.gob.es/kos/sector-publico/sector/transporte",
+		"issued": "2020-03-02T00:00:00Z",
+		"modified": "2020-03-02T00:00:00Z"
+	}
+]}}`
+
+func TestBuildDigest(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(digestResponse))
+	}))
+	defer srv.Close()
+
+	client, err := datos.NewClient(datos.WithBaseURL(srv.URL), datos.WithSystemCertsOnly())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	queries := []DigestQuery{
+		{Name: "Salud", Theme: "salud"},
+		{Name: "Empleo", Theme: "empleo"},
+	}
+	from := time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(7 * 24 * time.Hour)
+	d, err := BuildDigest(context.Background(), client, queries, from, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(paths) != 1 || !strings.Contains(paths[0], "/modified/begin/") {
+		t.Errorf("expected a single request of the modified datasets, got: %v", paths)
+	}
+
+	if len(d.Sections) != 2 {
+		t.Fatalf("wrong number of sections, expected: 2, got: %d", len(d.Sections))
+	}
+
+	salud := d.Sections[0]
+	if len(salud.New) != 1 || salud.New[0].ID != "nuevo" {
+		t.Errorf("wrong new datasets: %v", salud.New)
+	}
+
+	if len(salud.Updated) != 1 || salud.Updated[0].ID != "viejo" {
+		t.Errorf("wrong updated datasets: %v", salud.Updated)
+	}
+
+	if s := d.Sections[1]; len(s.New)+len(s.Updated) != 0 {
+		t.Errorf("expected no datasets for %s, got: %v", s.Query, s)
+	}
+
+	if d.Datasets() != 2 {
+		t.Errorf("wrong number of datasets, expected: 2, got: %d", d.Datasets())
+	}
+
+	var b bytes.Buffer
+	if err := d.WriteHTML(&b); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	html := b.String()
+	for _, expected := range []string{
+		`<h2 style="font-size: 18px; border-bottom: 1px solid #ddd">Salud</h2>`,
+		`<a href="` + datos.PortalURL("nuevo") + `">Centros de salud</a>`,
+		`Farmacias &amp; boticas`,
+		`No new or updated datasets.`,
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("expected %s in digest:\n%s", expected, html)
+		}
+	}
+
+	b.Reset()
+	if err := d.WriteEmail(&b, "datos@example.com", []string{"a@example.com", "b@example.com"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	email := b.String()
+	for _, expected := range []string{
+		"To: a@example.com, b@example.com\r\n",
+		"Content-Type: text/html; charset=\"utf-8\"\r\n",
+		"\r\n\r\n<!DOCTYPE html>",
+	} {
+		if !strings.Contains(email, expected) {
+			t.Errorf("expected %q in email:\n%s", expected, email)
+		}
+	}
+
+	if _, err := BuildDigest(context.Background(), client, []DigestQuery{{Name: "Todo"}}, from, to); err == nil {
+		t.Errorf("expected error with a query without filters")
+	}
+}
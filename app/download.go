@@ -0,0 +1,211 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode"
+
+	"github.com/erizocosmico/datos"
+	"github.com/sirupsen/logrus"
+)
+
+// Dataset to download.
+type Dataset struct {
+	// URL of the distribution to download.
+	URL string
+	// Title of the dataset.
+	Title string
+	// ID of the dataset, used as name of the downloaded file.
+	ID string
+	// Modified is the last time the dataset was modified.
+	Modified time.Time
+	// Size of the distribution in bytes according to the catalog, or 0 if
+	// it's unknown.
+	Size int64
+}
+
+var formats = map[string]string{
+	"csv":  "text/csv",
+	"json": "application/json",
+	"xml":  "application/xml",
+	"kml":  "application/vnd.google-earth.kml+xml",
+	"shp":  "application/x-zipped-shp",
+}
+
+var allowedFormats = map[string]bool{
+	"text/csv":         true,
+	"application/json": true,
+	"application/xml":  true,
+}
+
+// WritePlan writes the datasets that would be downloaded, with their
+// estimated size and URL, followed by the total size.
+func WritePlan(w io.Writer, datasets []Dataset) error {
+	var total int64
+	var unknown int
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSIZE\tURL")
+	for _, d := range datasets {
+		size := "?"
+		if d.Size > 0 {
+			size = formatBytes(d.Size)
+			total += d.Size
+		} else {
+			unknown++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", d.ID, size, d.URL)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(
+		w, "\n%d dataset(s), %s estimated, %d of unknown size\n",
+		len(datasets), formatBytes(total), unknown,
+	)
+	return err
+}
+
+// formatBytes formats a number of bytes using binary units.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// Failure is a dataset that could not be downloaded or processed.
+type Failure struct {
+	// Dataset that failed.
+	Dataset Dataset
+	// Err is the reason why it failed.
+	Err error
+}
+
+// DownloadError is returned when some datasets could not be downloaded or
+// processed.
+type DownloadError []Failure
+
+func (e DownloadError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d dataset(s) could not be downloaded:", len(e))
+	for _, f := range e {
+		fmt.Fprintf(&b, "\n- %s: %s", f.Dataset.ID, f.Err)
+	}
+	return b.String()
+}
+
+// isUpToDate reports whether there is a local copy of the dataset retrieved
+// after the dataset was last modified.
+func isUpToDate(d Dataset, output string) bool {
+	if d.Modified.IsZero() {
+		return false
+	}
+
+	matches, err := filepath.Glob(filepath.Join(output, d.ID+"*"))
+	if err != nil {
+		return false
+	}
+
+	for _, m := range matches {
+		fi, err := os.Stat(m)
+		if err != nil || fi.IsDir() {
+			continue
+		}
+
+		name := fi.Name()
+		if name != d.ID && strings.TrimSuffix(name, filepath.Ext(name)) != d.ID {
+			continue
+		}
+
+		// Stamped files have the same modification time as the dataset.
+		if !fi.ModTime().Before(d.Modified) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// downloadJob returns the job to download the dataset into a temporary
+// file in the output directory, which is renamed once the format of the
+// file is known by finishDownload. If the temporary file was left by an
+// interrupted download, it's resumed, unless the dataset has been modified
+// since then.
+func downloadJob(d Dataset, output string) datos.DownloadJob {
+	path := tmpPath(d, output)
+	if fi, err := os.Stat(path); err == nil && (d.Modified.IsZero() || fi.ModTime().Before(d.Modified)) {
+		os.Remove(path)
+	}
+
+	return datos.DownloadJob{
+		ID:           d.ID,
+		Distribution: datos.Distribution{AccessURL: d.URL},
+		Path:         path,
+	}
+}
+
+func tmpPath(d Dataset, output string) string {
+	return filepath.Join(output, "."+d.ID+".tmp")
+}
+
+// finishDownload moves the file downloaded by a job to its final path,
+// whose extension depends on the content type of the file.
+func finishDownload(d Dataset, output string, info datos.DownloadInfo) (string, error) {
+	var ext string
+	typ := info.ContentType
+	if strings.Contains(typ, "kml") {
+		ext = ".kml"
+	} else if strings.Contains(typ, "zip") || strings.Contains(typ, "shp") {
+		ext = ".zip"
+	} else if strings.Contains(typ, "xml") {
+		ext = ".xml"
+	} else if strings.Contains(typ, "json") {
+		ext = ".json"
+	} else if strings.Contains(typ, "csv") {
+		ext = ".csv"
+	}
+
+	path := filepath.Join(output, d.ID+ext)
+	if err := os.Rename(tmpPath(d, output), path); err != nil {
+		return "", err
+	}
+
+	logrus.Infof("downloaded dataset %q to %s", d.Title, path)
+
+	return path, nil
+}
+
+func slugify(name string, issued time.Time) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i:]
+	}
+
+	var result []rune
+	var last rune
+	for _, r := range name {
+		if unicode.IsLetter(r) ||
+			unicode.IsDigit(r) ||
+			r == '_' || r == '-' {
+			result = append(result, r)
+		} else if last != '-' {
+			result = append(result, '-')
+		}
+
+		last = result[len(result)-1]
+	}
+	return fmt.Sprintf("%s-%d", string(result), issued.Unix())
+}
@@ -0,0 +1,159 @@
+package app
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erizocosmico/datos"
+)
+
+func TestDownloadError(t *testing.T) {
+	err := DownloadError{
+		{Dataset{ID: "foo"}, errors.New("status 404")},
+		{Dataset{ID: "bar"}, errors.New("timeout")},
+	}
+
+	expected := "2 dataset(s) could not be downloaded:\n- foo: status 404\n- bar: timeout"
+	if err.Error() != expected {
+		t.Errorf("wrong error, expected:\n%s\ngot:\n%s", expected, err.Error())
+	}
+}
+
+func TestWritePlan(t *testing.T) {
+	var b strings.Builder
+	err := WritePlan(&b, []Dataset{
+		{ID: "foo", URL: "http://example.com/foo.csv", Size: 1536},
+		{ID: "bar", URL: "http://example.com/bar.csv"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := "ID   SIZE     URL\n" +
+		"foo  1.5 KiB  http://example.com/foo.csv\n" +
+		"bar  ?        http://example.com/bar.csv\n" +
+		"\n2 dataset(s), 1.5 KiB estimated, 1 of unknown size\n"
+	if b.String() != expected {
+		t.Errorf("wrong plan, expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
+
+func TestFinishConverted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	client, err := datos.NewClient(datos.WithSystemCertsOnly())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	a, err := New(client, Config{Theme: "salud", Output: dir, FlattenJSON: true, Stamp: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	d := Dataset{ID: "foo", Modified: time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC)}
+	if err := ioutil.WriteFile(tmpPath(d, dir), []byte(`[{"a":1},{"a":2}]`), 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	m, err := ReadManifest(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	result := datos.DownloadResult{Info: datos.DownloadInfo{ContentType: "application/json"}}
+	path, err := a.finish(d, result, m)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if expected := filepath.Join(dir, "foo.csv"); path != expected {
+		t.Errorf("wrong path, expected: %s, got: %s", expected, path)
+	}
+
+	if fi, err := os.Stat(path); err != nil || !fi.ModTime().Equal(d.Modified) {
+		t.Errorf("expected converted file to be stamped, got: %v", err)
+	}
+
+	if err := m.write(dir); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	r, err := Verify(dir, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !r.OK() || r.Checked != 1 || m.Datasets["foo"].File != "foo.csv" {
+		t.Errorf("wrong report: %+v", r)
+	}
+}
+
+func TestIsUpToDate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	modified := time.Date(2019, time.March, 1, 10, 0, 0, 0, time.UTC)
+	files := []struct {
+		name  string
+		mtime time.Time
+	}{
+		{"newer.csv", modified.Add(time.Hour)},
+		{"same.csv", modified},
+		{"older.csv", modified.Add(-time.Second)},
+		{"noext", modified.Add(time.Hour)},
+		{"prefix-other.csv", modified.Add(time.Hour)},
+		{"mixed.csv", modified.Add(-time.Hour)},
+		{"mixed.json", modified.Add(time.Hour)},
+	}
+
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		if err := ioutil.WriteFile(path, []byte("a\n1\n"), 0644); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if err := os.Chtimes(path, f.mtime, f.mtime); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, "folder"), 0755); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cases := []struct {
+		id       string
+		modified time.Time
+		expected bool
+	}{
+		{"newer", modified, true},
+		{"same", modified, true},
+		{"older", modified, false},
+		{"noext", modified, true},
+		{"mixed", modified, true},
+		{"missing", modified, false},
+		{"prefix", modified, false},
+		{"folder", time.Time{}.Add(time.Hour), false},
+		{"newer", time.Time{}, false},
+	}
+
+	for _, c := range cases {
+		d := Dataset{ID: c.id, Modified: c.modified}
+		if result := isUpToDate(d, dir); result != c.expected {
+			t.Errorf("wrong result for %s modified at %s, expected: %v, got: %v", c.id, c.modified, c.expected, result)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/erizocosmico/datos"
+	"github.com/sirupsen/logrus"
+)
+
+// EstimateOptions controls how the cost of downloading datasets is
+// estimated.
+type EstimateOptions struct {
+	// Samples is the maximum number of datasets of unknown size whose size
+	// is requested to their servers.
+	Samples int
+	// Bandwidth is the expected download speed of every connection, in
+	// bytes per second. If it's 0, the duration is not estimated.
+	Bandwidth float64
+}
+
+// Estimate is the expected cost of downloading a set of datasets.
+type Estimate struct {
+	// Datasets is the number of datasets to download.
+	Datasets int
+	// Bytes is the estimated total size of the datasets.
+	Bytes int64
+	// Known is the number of datasets whose size is in the catalog.
+	Known int
+	// Sampled is the number of datasets whose size was requested to their
+	// servers.
+	Sampled int
+	// Extrapolated is the number of datasets whose size is assumed to be
+	// the average of the known and sampled ones.
+	Extrapolated int
+	// Unknown is the number of datasets whose size could not be estimated.
+	Unknown int
+	// Concurrency is the number of datasets downloaded at the same time.
+	Concurrency int
+	// Duration is the estimated time to download all the datasets.
+	Duration time.Duration
+}
+
+// Estimate estimates the total size of the given datasets and the time it
+// takes to download them with the configured concurrency. The size of a
+// random sample of the datasets whose size is not in the catalog is
+// requested to their servers, and the rest are assumed to be of the
+// average size.
+func (a *App) Estimate(ctx context.Context, datasets []Dataset, opts EstimateOptions) (Estimate, error) {
+	e := estimate(datasets, opts, a.config.Concurrency, a.rnd, func(d Dataset) int64 {
+		size, err := a.client.DistributionSize(ctx, datos.Distribution{AccessURL: d.URL})
+		if err != nil && a.config.Verbose {
+			logrus.Warnf("unable to get size of dataset %q: %s", d.Title, err)
+		}
+		return size
+	})
+
+	return e, ctx.Err()
+}
+
+func estimate(
+	datasets []Dataset,
+	opts EstimateOptions,
+	concurrency int,
+	rnd *rand.Rand,
+	sizeOf func(Dataset) int64,
+) Estimate {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	e := Estimate{Datasets: len(datasets), Concurrency: concurrency}
+	var sizes []int64
+	var unknown []Dataset
+	for _, d := range datasets {
+		if d.Size > 0 {
+			e.Known++
+			sizes = append(sizes, d.Size)
+		} else {
+			unknown = append(unknown, d)
+		}
+	}
+
+	rnd.Shuffle(len(unknown), func(i, j int) {
+		unknown[i], unknown[j] = unknown[j], unknown[i]
+	})
+
+	var rest int
+	for i, d := range unknown {
+		if i >= opts.Samples {
+			rest += len(unknown) - i
+			break
+		}
+
+		if size := sizeOf(d); size >= 0 {
+			e.Sampled++
+			sizes = append(sizes, size)
+		} else {
+			rest++
+		}
+	}
+
+	if len(sizes) > 0 {
+		var total int64
+		for _, s := range sizes {
+			total += s
+		}
+
+		avg := total / int64(len(sizes))
+		for i := 0; i < rest; i++ {
+			sizes = append(sizes, avg)
+		}
+		e.Extrapolated = rest
+	} else {
+		e.Unknown = rest
+	}
+
+	for _, s := range sizes {
+		e.Bytes += s
+	}
+
+	if opts.Bandwidth > 0 {
+		e.Duration = time.Duration(float64(makespan(sizes, concurrency)) / opts.Bandwidth * float64(time.Second))
+	}
+
+	return e
+}
+
+// makespan returns the number of bytes downloaded by the busiest of the
+// given number of workers, giving every file to the least busy worker
+// from the largest to the smallest file.
+func makespan(sizes []int64, workers int) int64 {
+	sorted := make([]int64, len(sizes))
+	copy(sorted, sizes)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] > sorted[j] })
+
+	loads := make([]int64, workers)
+	for _, s := range sorted {
+		min := 0
+		for i := range loads {
+			if loads[i] < loads[min] {
+				min = i
+			}
+		}
+		loads[min] += s
+	}
+
+	var max int64
+	for _, l := range loads {
+		if l > max {
+			max = l
+		}
+	}
+	return max
+}
+
+// WriteEstimate writes a summary of the estimated cost of a download.
+func WriteEstimate(w io.Writer, e Estimate) error {
+	_, err := fmt.Fprintf(
+		w,
+		"estimated size: %s (%d from the catalog, %d sampled, %d extrapolated, %d unknown)\n",
+		formatBytes(e.Bytes), e.Known, e.Sampled, e.Extrapolated, e.Unknown,
+	)
+	if err != nil || e.Duration == 0 {
+		return err
+	}
+
+	_, err = fmt.Fprintf(
+		w, "estimated time: %s with %d download(s) at a time\n",
+		e.Duration.Round(time.Second), e.Concurrency,
+	)
+	return err
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestEstimate(t *testing.T) {
+	datasets := []Dataset{
+		{ID: "a", Size: 4000},
+		{ID: "b", Size: 2000},
+		{ID: "c"},
+		{ID: "d"},
+		{ID: "e"},
+	}
+
+	var requested int
+	e := estimate(
+		datasets,
+		EstimateOptions{Samples: 2, Bandwidth: 1000},
+		2,
+		rand.New(rand.NewSource(1)),
+		func(d Dataset) int64 {
+			requested++
+			return 3000
+		},
+	)
+
+	if requested != 2 {
+		t.Errorf("wrong number of sampled datasets, expected: 2, got: %d", requested)
+	}
+
+	expected := Estimate{
+		Datasets:     5,
+		Bytes:        15000,
+		Known:        2,
+		Sampled:      2,
+		Extrapolated: 1,
+		Concurrency:  2,
+		Duration:     8 * time.Second,
+	}
+	if e != expected {
+		t.Errorf("wrong estimate, expected: %+v, got: %+v", expected, e)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteEstimate(&buf, e); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	out := "estimated size: 14.6 KiB (2 from the catalog, 2 sampled, 1 extrapolated, 0 unknown)\n" +
+		"estimated time: 8s with 2 download(s) at a time\n"
+	if buf.String() != out {
+		t.Errorf("wrong output:\n%s", buf.String())
+	}
+}
+
+func TestEstimateUnknown(t *testing.T) {
+	e := estimate(
+		[]Dataset{{ID: "a"}, {ID: "b"}},
+		EstimateOptions{Samples: 1},
+		1,
+		rand.New(rand.NewSource(1)),
+		func(d Dataset) int64 { return -1 },
+	)
+
+	if e.Bytes != 0 || e.Unknown != 2 || e.Duration != 0 {
+		t.Errorf("wrong estimate: %+v", e)
+	}
+}
@@ -0,0 +1,385 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/erizocosmico/datos"
+)
+
+// Filter expressions are boolean expressions evaluated over every dataset,
+// such as:
+//
+//	theme == "salud" && (format == "text/csv" || format == "application/json") && modified > "2024-01-01"
+//
+// Comparisons are made between a field and a string literal. The supported
+// operators are ==, !=, <, <=, >, >= and =~ (regular expression match), and
+// comparisons can be combined with &&, || and !. Fields with multiple values
+// match if any of their values matches. Fields whose values are links, such
+// as theme or publisher, can be compared with the link or its last segment.
+// String comparisons ignore case and accents, and modified and issued are
+// compared as dates. The license can also be compared with the identifier
+// of known licenses, such as CC-BY-4.0, or with unknown or none.
+var exprFields = map[string]func(datos.Dataset) []string{
+	"id":        func(ds datos.Dataset) []string { return []string{ds.Identifier} },
+	"title":     datasetTitles,
+	"keyword":   datasetKeywords,
+	"theme":     func(ds datos.Dataset) []string { return ds.Theme },
+	"spatial":   func(ds datos.Dataset) []string { return ds.Spatial },
+	"publisher": func(ds datos.Dataset) []string { return []string{ds.Publisher} },
+	"language":  func(ds datos.Dataset) []string { return []string{ds.Language} },
+	"license":   datasetLicense,
+	"format": func(ds datos.Dataset) []string {
+		var result []string
+		for _, d := range ds.Distribution {
+			result = append(result, d.Format.Value)
+		}
+		return result
+	},
+}
+
+var exprDateFields = map[string]func(datos.Dataset) time.Time{
+	"modified": func(ds datos.Dataset) time.Time { return ds.Modified.Time },
+	"issued":   func(ds datos.Dataset) time.Time { return ds.Issued.Time },
+}
+
+type exprNode interface {
+	eval(datos.Dataset) bool
+}
+
+type andNode struct{ left, right exprNode }
+
+func (n andNode) eval(ds datos.Dataset) bool { return n.left.eval(ds) && n.right.eval(ds) }
+
+type orNode struct{ left, right exprNode }
+
+func (n orNode) eval(ds datos.Dataset) bool { return n.left.eval(ds) || n.right.eval(ds) }
+
+type notNode struct{ expr exprNode }
+
+func (n notNode) eval(ds datos.Dataset) bool { return !n.expr.eval(ds) }
+
+type textComparison struct {
+	field func(datos.Dataset) []string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+func (n textComparison) eval(ds datos.Dataset) bool {
+	if n.op == "!=" {
+		return !textComparison{n.field, "==", n.value, nil}.eval(ds)
+	}
+
+	for _, v := range n.field(ds) {
+		if v == "" {
+			continue
+		}
+
+		if n.compare(normalizeText(v)) || n.compare(normalizeText(lastSegment(v))) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n textComparison) compare(v string) bool {
+	switch n.op {
+	case "==":
+		return v == n.value
+	case "=~":
+		return n.re.MatchString(v)
+	case "<":
+		return v < n.value
+	case "<=":
+		return v <= n.value
+	case ">":
+		return v > n.value
+	case ">=":
+		return v >= n.value
+	}
+	return false
+}
+
+type dateComparison struct {
+	field func(datos.Dataset) time.Time
+	op    string
+	value time.Time
+}
+
+func (n dateComparison) eval(ds datos.Dataset) bool {
+	t := n.field(ds)
+	if t.IsZero() {
+		return false
+	}
+
+	switch n.op {
+	case "==":
+		return t.Equal(n.value)
+	case "!=":
+		return !t.Equal(n.value)
+	case "<":
+		return t.Before(n.value)
+	case "<=":
+		return !t.After(n.value)
+	case ">":
+		return t.After(n.value)
+	case ">=":
+		return !t.Before(n.value)
+	}
+	return false
+}
+
+func lastSegment(uri string) string {
+	uri = strings.TrimRight(uri, "/")
+	if i := strings.LastIndex(uri, "/"); i >= 0 {
+		return uri[i+1:]
+	}
+	return uri
+}
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+type tokenKind byte
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokOp
+)
+
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := rune(expr[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"':
+			end := i + 1
+			for ; end < len(expr) && expr[end] != '"'; end++ {
+				if expr[end] == '\\' {
+					end++
+				}
+			}
+
+			if end >= len(expr) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+
+			s, err := strconv.Unquote(expr[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at position %d: %s", i, err)
+			}
+
+			tokens = append(tokens, token{tokString, s, i})
+			i = end + 1
+		case unicode.IsLetter(c) || c == '_':
+			end := i
+			for end < len(expr) && (unicode.IsLetter(rune(expr[end])) || expr[end] == '_') {
+				end++
+			}
+			tokens = append(tokens, token{tokIdent, expr[i:end], i})
+			i = end
+		default:
+			var op string
+			for _, o := range []string{"&&", "||", "==", "!=", "=~", "<=", ">=", "<", ">", "!", "(", ")"} {
+				if strings.HasPrefix(expr[i:], o) {
+					op = o
+					break
+				}
+			}
+
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+
+			tokens = append(tokens, token{tokOp, op, i})
+			i += len(op)
+		}
+	}
+
+	return append(tokens, token{tokEOF, "", len(expr)}), nil
+}
+
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+// parseFilterExpr parses a filter expression and returns the resulting
+// filter.
+func parseFilterExpr(expr string) (datasetFilter, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter expression: %s", err)
+	}
+
+	p := &exprParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err == nil && p.peek().kind != tokEOF {
+		err = p.errorf("unexpected %q", p.peek().value)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter expression: %s", err)
+	}
+
+	return node.eval, nil
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("position %d: %s", p.peek().pos, fmt.Sprintf(format, args...))
+}
+
+func (p *exprParser) isOp(op string) bool {
+	t := p.peek()
+	return t.kind == tokOp && t.value == op
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOp("||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOp("&&") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.isOp("!") {
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{expr}, nil
+	}
+
+	if p.isOp("(") {
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.isOp(")") {
+			return nil, p.errorf("expecting )")
+		}
+		p.next()
+		return expr, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	field := p.peek()
+	if field.kind != tokIdent {
+		return nil, p.errorf("expecting field name")
+	}
+	p.next()
+
+	op := p.peek()
+	switch op.value {
+	case "==", "!=", "=~", "<", "<=", ">", ">=":
+		if op.kind != tokOp {
+			return nil, p.errorf("expecting comparison operator")
+		}
+	default:
+		return nil, p.errorf("expecting comparison operator")
+	}
+	p.next()
+
+	value := p.peek()
+	if value.kind != tokString {
+		return nil, p.errorf("expecting string")
+	}
+	p.next()
+
+	name := strings.ToLower(field.value)
+	if fn, ok := exprDateFields[name]; ok {
+		if op.value == "=~" {
+			return nil, fmt.Errorf("position %d: =~ cannot be used with dates", op.pos)
+		}
+
+		t, err := parseExprDate(value.value)
+		if err != nil {
+			return nil, fmt.Errorf("position %d: %s", value.pos, err)
+		}
+		return dateComparison{fn, op.value, t}, nil
+	}
+
+	fn, ok := exprFields[name]
+	if !ok {
+		return nil, fmt.Errorf("position %d: unknown field %q", field.pos, field.value)
+	}
+
+	n := textComparison{fn, op.value, normalizeText(value.value), nil}
+	if op.value == "=~" {
+		re, err := compileFilterRegexp(value.value)
+		if err != nil {
+			return nil, fmt.Errorf("position %d: %s", value.pos, err)
+		}
+		n.re = re
+	}
+
+	return n, nil
+}
+
+func parseExprDate(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", "2006-01-02T15:04:05", time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, expecting YYYY-MM-DD", s)
+}
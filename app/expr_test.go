@@ -0,0 +1,89 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erizocosmico/datos"
+)
+
+func TestParseFilterExpr(t *testing.T) {
+	ds := datos.Dataset{
+		Title:    datos.LangStrings{{Text: "Calidad del aire"}},
+		Theme:    datos.Strings{"http://datos.gob.es/kos/sector-publico/sector/salud"},
+		Modified: datos.Datetime{Time: time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)},
+		License:  "https://creativecommons.org/licenses/by/4.0/",
+	}
+	ds.Distribution = datos.Distributions{{}}
+	ds.Distribution[0].Format.Value = "text/csv"
+
+	testCases := []struct {
+		expr     string
+		expected bool
+	}{
+		{`theme == "salud"`, true},
+		{`theme == "Salud"`, true},
+		{`theme != "salud"`, false},
+		{`format == "text/csv" || format == "application/json"`, true},
+		{`format == "csv"`, true},
+		{`modified > "2024-01-01"`, true},
+		{`modified < "2024-01-01"`, false},
+		{`title =~ "aíre$"`, true},
+		{`!(title =~ "^aire")`, true},
+		{`theme == "salud" && (format == "application/json" || modified >= "2024-05-01")`, true},
+		{`theme == "salud" && format == "application/json"`, false},
+		{`license == "cc-by-4.0"`, true},
+		{`license == "unknown" || license == "none"`, false},
+		// && binds tighter than ||, without parentheses.
+		{`theme == "salud" || format == "json" && theme == "x"`, true},
+		{`format == "json" && theme == "x" || theme == "salud"`, true},
+		{`(theme == "salud" || format == "json") && theme == "x"`, false},
+		{`theme == "x" || theme == "salud" && format == "csv"`, true},
+		{`theme == "x" || theme == "salud" && format == "json"`, false},
+		// ! only applies to the comparison that follows it.
+		{`!theme == "x" && format == "csv"`, true},
+		{`!theme == "salud" || format == "csv"`, true},
+		{`!theme == "salud" && format == "csv"`, false},
+		{`!!(theme == "salud")`, true},
+	}
+
+	for _, tt := range testCases {
+		f, err := parseFilterExpr(tt.expr)
+		if err != nil {
+			t.Errorf("unexpected error parsing %s: %s", tt.expr, err)
+			continue
+		}
+
+		if result := f(ds); result != tt.expected {
+			t.Errorf("wrong result for %s, expected: %v, got: %v", tt.expr, tt.expected, result)
+		}
+	}
+}
+
+func TestParseFilterExprErrors(t *testing.T) {
+	exprs := []string{
+		`foo == "x"`,
+		`theme == "x" &&`,
+		`title == "a`,
+		`(theme == "x"`,
+		`modified > "yesterday"`,
+		`modified =~ "2024"`,
+		`theme "x"`,
+		``,
+		`theme == "salud" && foo == "x"`,
+		`foo`,
+		`theme == "salud")`,
+		`&& theme == "salud"`,
+		`theme == "salud" || || format == "csv"`,
+		`theme == `,
+		`theme = "salud"`,
+		`title =~ "["`,
+		`"salud" == theme`,
+	}
+
+	for _, expr := range exprs {
+		if _, err := parseFilterExpr(expr); err == nil {
+			t.Errorf("expected error parsing %s", expr)
+		}
+	}
+}
@@ -0,0 +1,513 @@
+package app
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportFeather returns a process function that writes every CSV file as
+// a Feather file next to it, with the type of each column inferred from
+// its values, so it can be read with pandas.read_feather.
+//
+// Feather files are Arrow IPC files. They are written without any Arrow
+// library, so only the types needed for CSV files are supported: 64-bit
+// integers and floats, booleans, dates, timestamps and strings. Empty
+// values are nulls.
+func exportFeather() processFunc {
+	return func(path string) error {
+		if filepath.Ext(path) != ".csv" {
+			return nil
+		}
+
+		var header []string
+		var rows [][]string
+		err := readCSV(path, func(i int, record []string) error {
+			if i == 0 {
+				header = append([]string(nil), record...)
+				if len(header) > 0 {
+					header[0] = strings.TrimPrefix(header[0], "\ufeff")
+				}
+				return nil
+			}
+
+			rows = append(rows, append([]string(nil), record...))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		out := strings.TrimSuffix(path, filepath.Ext(path)) + ".feather"
+		tmp := out + ".tmp"
+		f, err := os.Create(tmp)
+		if err != nil {
+			return err
+		}
+
+		if err := writeFeather(f, header, rows); err != nil {
+			f.Close()
+			os.Remove(tmp)
+			return err
+		}
+
+		if err := f.Close(); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+
+		return os.Rename(tmp, out)
+	}
+}
+
+// arrowType is the type of a column of a Feather file.
+type arrowType int
+
+const (
+	arrowInt64 arrowType = iota
+	arrowFloat64
+	arrowBool
+	arrowDate
+	arrowTimestamp
+	arrowString
+)
+
+// arrowTimestampLayouts are the layouts of the values of timestamp
+// columns, the same ones written by the normalize step.
+var arrowTimestampLayouts = []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", time.RFC3339}
+
+// inferArrowType returns the most specific type all the values can be
+// converted to. Integers with leading zeros, like postal codes, are kept
+// as strings.
+func inferArrowType(values []string) arrowType {
+	candidates := []arrowType{arrowInt64, arrowFloat64, arrowBool, arrowDate, arrowTimestamp}
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+
+		var remaining []arrowType
+		for _, t := range candidates {
+			if _, ok := parseArrowValue(t, v); ok {
+				remaining = append(remaining, t)
+			}
+		}
+
+		candidates = remaining
+		if len(candidates) == 0 {
+			return arrowString
+		}
+	}
+
+	// If all the values are empty, no type was discarded.
+	if len(candidates) == 5 {
+		return arrowString
+	}
+	return candidates[0]
+}
+
+// parseArrowValue returns the bits of the value of the given type, which
+// is the number of days since the epoch for dates and milliseconds for
+// timestamps.
+func parseArrowValue(t arrowType, v string) (uint64, bool) {
+	switch t {
+	case arrowInt64:
+		if leadingZero(v) {
+			return 0, false
+		}
+
+		n, err := strconv.ParseInt(v, 10, 64)
+		return uint64(n), err == nil
+	case arrowFloat64:
+		if leadingZero(v) || strings.ContainsAny(v, "xXpP_") {
+			return 0, false
+		}
+
+		f, err := strconv.ParseFloat(v, 64)
+		return math.Float64bits(f), err == nil
+	case arrowBool:
+		switch strings.ToLower(v) {
+		case "true":
+			return 1, true
+		case "false":
+			return 0, true
+		}
+		return 0, false
+	case arrowDate:
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return 0, false
+		}
+		return uint64(d.Unix() / 86400), true
+	case arrowTimestamp:
+		for _, layout := range arrowTimestampLayouts {
+			if d, err := time.Parse(layout, v); err == nil {
+				return uint64(d.Unix()*1000 + int64(d.Nanosecond())/int64(time.Millisecond)), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// leadingZero reports whether the number has a leading zero that would be
+// lost converting it, as in 08001 but not in 0.5.
+func leadingZero(v string) bool {
+	digits := strings.TrimPrefix(v, "-")
+	return len(digits) > 1 && digits[0] == '0' && digits[1] != '.'
+}
+
+// arrowColumn is a column of a Feather file with its buffers, which are
+// the validity bitmap and the values, preceded by the offsets for strings.
+type arrowColumn struct {
+	name    string
+	typ     arrowType
+	nulls   int
+	buffers [][]byte
+}
+
+func newArrowColumn(name string, values []string) arrowColumn {
+	c := arrowColumn{name: name, typ: inferArrowType(values)}
+	validity := make([]byte, (len(values)+7)/8)
+	var data, offsets bytes.Buffer
+	var bits []byte
+	if c.typ == arrowBool {
+		bits = make([]byte, (len(values)+7)/8)
+	}
+
+	for i, v := range values {
+		if v == "" {
+			c.nulls++
+		} else {
+			validity[i/8] |= 1 << uint(i%8)
+		}
+
+		switch c.typ {
+		case arrowString:
+			binary.Write(&offsets, binary.LittleEndian, int32(data.Len()))
+			data.WriteString(v)
+		case arrowBool:
+			if n, _ := parseArrowValue(c.typ, v); n == 1 {
+				bits[i/8] |= 1 << uint(i%8)
+			}
+		case arrowDate:
+			n, _ := parseArrowValue(c.typ, v)
+			binary.Write(&data, binary.LittleEndian, int32(n))
+		default:
+			n, _ := parseArrowValue(c.typ, v)
+			binary.Write(&data, binary.LittleEndian, n)
+		}
+	}
+
+	// The validity bitmap can be left out when there are no nulls.
+	if c.nulls == 0 {
+		validity = nil
+	}
+
+	switch c.typ {
+	case arrowString:
+		binary.Write(&offsets, binary.LittleEndian, int32(data.Len()))
+		c.buffers = [][]byte{validity, offsets.Bytes(), data.Bytes()}
+	case arrowBool:
+		c.buffers = [][]byte{validity, bits}
+	default:
+		c.buffers = [][]byte{validity, data.Bytes()}
+	}
+	return c
+}
+
+// field returns the Field table of the column in the schema.
+func (c arrowColumn) field() fbTable {
+	var typeID byte
+	var typ fbTable
+	switch c.typ {
+	case arrowInt64:
+		typeID, typ = 2, fbTable{fbInt32(64), fbBool(true)}
+	case arrowFloat64:
+		typeID, typ = 3, fbTable{fbInt16(2)}
+	case arrowBool:
+		typeID, typ = 6, fbTable{}
+	case arrowDate:
+		typeID, typ = 8, fbTable{fbInt16(0)}
+	case arrowTimestamp:
+		typeID, typ = 10, fbTable{fbInt16(1)}
+	default:
+		typeID, typ = 5, fbTable{}
+	}
+
+	return fbTable{fbString(c.name), fbBool(true), fbUint8(typeID), typ, nil, fbVector{}}
+}
+
+// arrowMetadataV5 is the version of the Arrow format written.
+const arrowMetadataV5 = 4
+
+// arrowMagic starts and ends every Arrow file.
+const arrowMagic = "ARROW1"
+
+// writeFeather writes the rows as a Feather version 2 file, that is, an
+// Arrow IPC file with a single record batch.
+func writeFeather(w io.Writer, header []string, rows [][]string) error {
+	columns := make([]arrowColumn, len(header))
+	values := make([]string, len(rows))
+	for j, name := range header {
+		for i, row := range rows {
+			values[i] = ""
+			if j < len(row) {
+				values[i] = strings.TrimSpace(row[j])
+			}
+		}
+		columns[j] = newArrowColumn(name, values)
+	}
+
+	schema := func() fbTable {
+		fields := make(fbVector, len(columns))
+		for i, c := range columns {
+			fields[i] = c.field()
+		}
+		// Little endian, the fields and no custom metadata.
+		return fbTable{fbInt16(0), fields}
+	}
+
+	var body bytes.Buffer
+	var nodes, buffers []byte
+	for _, c := range columns {
+		nodes = appendInt64s(nodes, int64(len(rows)), int64(c.nulls))
+		for _, b := range c.buffers {
+			buffers = appendInt64s(buffers, int64(body.Len()), int64(len(b)))
+			body.Write(b)
+			body.Write(make([]byte, padding(body.Len(), 8)))
+		}
+	}
+
+	batch := fbTable{fbInt64(int64(len(rows))), fbStructs{16, nodes}, fbStructs{16, buffers}}
+
+	var out bytes.Buffer
+	out.WriteString(arrowMagic + "\x00\x00")
+
+	writeArrowMessage(&out, fbTable{fbInt16(arrowMetadataV5), fbUint8(1), schema(), fbInt64(0)}, nil)
+
+	offset := out.Len()
+	metaLen := writeArrowMessage(&out, fbTable{fbInt16(arrowMetadataV5), fbUint8(3), batch, fbInt64(int64(body.Len()))}, body.Bytes())
+
+	// End of stream marker.
+	out.Write([]byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0})
+
+	blocks := appendInt64s(nil, int64(offset))
+	blocks = appendInt32(blocks, int32(metaLen))
+	blocks = appendInt32(blocks, 0)
+	blocks = appendInt64s(blocks, int64(body.Len()))
+	footer := fbTable{fbInt16(arrowMetadataV5), schema(), fbStructs{24, nil}, fbStructs{24, blocks}}
+
+	data := finishFlatbuffer(footer)
+	out.Write(data)
+	binary.Write(&out, binary.LittleEndian, int32(len(data)))
+	out.WriteString(arrowMagic)
+
+	_, err := out.WriteTo(w)
+	return err
+}
+
+// writeArrowMessage writes an encapsulated IPC message with the body and
+// returns the length of its metadata, including the prefix and padding.
+func writeArrowMessage(out *bytes.Buffer, msg fbTable, body []byte) int {
+	data := finishFlatbuffer(msg)
+	data = append(data, make([]byte, padding(len(data)+8, 8))...)
+
+	out.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	binary.Write(out, binary.LittleEndian, int32(len(data)))
+	out.Write(data)
+	out.Write(body)
+	return len(data) + 8
+}
+
+func appendInt64s(b []byte, values ...int64) []byte {
+	for _, v := range values {
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		b = append(b, buf[:]...)
+	}
+	return b
+}
+
+func appendInt32(b []byte, v int32) []byte {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], uint32(v))
+	return append(b, buf[:]...)
+}
+
+func padding(n, align int) int {
+	return (align - n%align) % align
+}
+
+// The Arrow metadata is encoded with FlatBuffers. What follows is a minimal
+// encoder for it, which writes every object after the ones referencing it,
+// so offsets are always positive, and aligns every scalar to its size.
+
+// fbObject is an object that can be referenced by an offset.
+type fbObject interface {
+	write(b *fbBuilder) int
+}
+
+// fbTable is a table whose fields are in the order of their IDs. Nil fields
+// are not written. Unions are written as two fields, the ID of their type
+// and the table.
+type fbTable []interface{}
+
+// fbScalar is a scalar field of a table.
+type fbScalar struct {
+	size  int
+	value uint64
+}
+
+func fbBool(v bool) fbScalar {
+	if v {
+		return fbScalar{1, 1}
+	}
+	return fbScalar{1, 0}
+}
+
+func fbUint8(v byte) fbScalar   { return fbScalar{1, uint64(v)} }
+func fbInt16(v int16) fbScalar  { return fbScalar{2, uint64(uint16(v))} }
+func fbInt32(v int32) fbScalar  { return fbScalar{4, uint64(uint32(v))} }
+func fbInt64(v int64) fbScalar  { return fbScalar{8, uint64(v)} }
+func fbString(s string) fbValue { return fbValue(s) }
+
+// fbValue is a string.
+type fbValue string
+
+// fbVector is a vector of tables.
+type fbVector []fbTable
+
+// fbStructs is a vector of structs of the given size, already encoded.
+type fbStructs struct {
+	size int
+	data []byte
+}
+
+type fbBuilder struct {
+	buf []byte
+}
+
+// finishFlatbuffer returns the encoded buffer with the table as root.
+func finishFlatbuffer(root fbTable) []byte {
+	b := &fbBuilder{buf: make([]byte, 4)}
+	pos := root.write(b)
+	binary.LittleEndian.PutUint32(b.buf, uint32(pos))
+	return b.buf
+}
+
+func (b *fbBuilder) align(n int) {
+	b.buf = append(b.buf, make([]byte, padding(len(b.buf), n))...)
+}
+
+// reserveOffset reserves space for an offset and returns its position.
+func (b *fbBuilder) reserveOffset() int {
+	b.align(4)
+	b.buf = append(b.buf, 0, 0, 0, 0)
+	return len(b.buf) - 4
+}
+
+// writeChild writes the object and sets the offset at pos to it.
+func (b *fbBuilder) writeChild(pos int, obj fbObject) {
+	target := obj.write(b)
+	binary.LittleEndian.PutUint32(b.buf[pos:], uint32(target-pos))
+}
+
+func (t fbTable) write(b *fbBuilder) int {
+	// The vtable is written right before the table: its size, the size of
+	// the table and the offset of every field from the start of the table.
+	b.align(2)
+	vtable := len(b.buf)
+	vsize := 4 + 2*len(t)
+	start := vtable + vsize
+	start += padding(start, 8)
+
+	offsets := make([]int, len(t))
+	pos := start + 4
+	for i, f := range t {
+		var size int
+		switch f := f.(type) {
+		case nil:
+			continue
+		case fbScalar:
+			size = f.size
+		default:
+			size = 4
+		}
+
+		pos += padding(pos, size)
+		offsets[i] = pos - start
+		pos += size
+	}
+
+	b.buf = append(b.buf, make([]byte, vsize)...)
+	binary.LittleEndian.PutUint16(b.buf[vtable:], uint16(vsize))
+	binary.LittleEndian.PutUint16(b.buf[vtable+2:], uint16(pos-start))
+	for i, off := range offsets {
+		binary.LittleEndian.PutUint16(b.buf[vtable+4+2*i:], uint16(off))
+	}
+
+	b.buf = append(b.buf, make([]byte, pos-vtable-vsize)...)
+	binary.LittleEndian.PutUint32(b.buf[start:], uint32(start-vtable))
+
+	var children []int
+	for i, f := range t {
+		switch f := f.(type) {
+		case nil:
+		case fbScalar:
+			var v [8]byte
+			binary.LittleEndian.PutUint64(v[:], f.value)
+			copy(b.buf[start+offsets[i]:], v[:f.size])
+		default:
+			children = append(children, i)
+		}
+	}
+
+	for _, i := range children {
+		b.writeChild(start+offsets[i], t[i].(fbObject))
+	}
+	return start
+}
+
+func (s fbValue) write(b *fbBuilder) int {
+	b.align(4)
+	pos := len(b.buf)
+	b.buf = appendInt32(b.buf, int32(len(s)))
+	b.buf = append(b.buf, s...)
+	b.buf = append(b.buf, 0)
+	return pos
+}
+
+func (v fbVector) write(b *fbBuilder) int {
+	b.align(4)
+	pos := len(b.buf)
+	b.buf = appendInt32(b.buf, int32(len(v)))
+	elems := make([]int, len(v))
+	for i := range v {
+		elems[i] = b.reserveOffset()
+	}
+
+	for i, t := range v {
+		b.writeChild(elems[i], t)
+	}
+	return pos
+}
+
+func (s fbStructs) write(b *fbBuilder) int {
+	// The structs have 64-bit fields, so they are aligned to 8 bytes
+	// after the length of the vector.
+	b.align(4)
+	if len(b.buf)%8 == 0 {
+		b.buf = append(b.buf, 0, 0, 0, 0)
+	}
+
+	pos := len(b.buf)
+	b.buf = appendInt32(b.buf, int32(len(s.data)/s.size))
+	b.buf = append(b.buf, s.data...)
+	return pos
+}
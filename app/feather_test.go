@@ -0,0 +1,201 @@
+package app
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fbReader reads the tables of a FlatBuffers buffer, to check the Feather
+// files written.
+type fbReader []byte
+
+func (r fbReader) u32(pos int) int { return int(binary.LittleEndian.Uint32(r[pos:])) }
+
+func (r fbReader) root() int { return r.u32(0) }
+
+// field returns the position of the field of the table, or -1 if it's not
+// set.
+func (r fbReader) field(table, id int) int {
+	vtable := table - int(int32(r.u32(table)))
+	vsize := int(binary.LittleEndian.Uint16(r[vtable:]))
+	if 4+2*id >= vsize {
+		return -1
+	}
+
+	off := int(binary.LittleEndian.Uint16(r[vtable+4+2*id:]))
+	if off == 0 {
+		return -1
+	}
+	return table + off
+}
+
+func (r fbReader) ref(table, id int) int {
+	pos := r.field(table, id)
+	return pos + r.u32(pos)
+}
+
+func (r fbReader) vector(table, id int) (int, int) {
+	pos := r.ref(table, id)
+	return r.u32(pos), pos + 4
+}
+
+func (r fbReader) str(table, id int) string {
+	pos := r.ref(table, id)
+	return string(r[pos+4 : pos+4+r.u32(pos)])
+}
+
+func TestWriteFeather(t *testing.T) {
+	header := []string{"id", "precio", "activo", "fecha", "hora", "cp", "nombre"}
+	rows := [][]string{
+		{"1", "1.5", "true", "2020-03-01", "2020-03-01T10:00:00", "08001", "Barcelona"},
+		{"-2", "", "FALSE", "", "2020-03-01 10:00:01", "28001", ""},
+		{"3", "2", "true", "1969-12-31", "", "50001", "Zaragoza"},
+	}
+
+	var b bytes.Buffer
+	if err := writeFeather(&b, header, rows); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data := b.Bytes()
+	if string(data[:6]) != arrowMagic || string(data[len(data)-6:]) != arrowMagic {
+		t.Fatalf("missing magic at the start or end of the file")
+	}
+
+	size := int(binary.LittleEndian.Uint32(data[len(data)-10:]))
+	footer := fbReader(data[len(data)-10-size : len(data)-10])
+	root := footer.root()
+
+	n, fields := footer.vector(footer.ref(root, 1), 1)
+	if n != len(header) {
+		t.Fatalf("wrong number of fields, expected: %d, got: %d", len(header), n)
+	}
+
+	types := []byte{2, 3, 6, 8, 10, 5, 5}
+	for i := 0; i < n; i++ {
+		pos := fields + 4*i
+		field := pos + footer.u32(pos)
+		if name := footer.str(field, 0); name != header[i] {
+			t.Errorf("wrong name of field %d, expected: %s, got: %s", i, header[i], name)
+		}
+
+		if typ := footer[footer.field(field, 2)]; typ != types[i] {
+			t.Errorf("wrong type of %s, expected: %d, got: %d", header[i], types[i], typ)
+		}
+
+		if children, _ := footer.vector(field, 5); children != 0 {
+			t.Errorf("expected no children for %s, got: %d", header[i], children)
+		}
+	}
+
+	n, blocks := footer.vector(root, 3)
+	if n != 1 || blocks%8 != 0 {
+		t.Fatalf("expected a single aligned record batch block, got %d at %d", n, blocks)
+	}
+
+	offset := int(binary.LittleEndian.Uint64(footer[blocks:]))
+	metaLen := int(binary.LittleEndian.Uint32(footer[blocks+8:]))
+	bodyLen := int(binary.LittleEndian.Uint64(footer[blocks+16:]))
+	if offset%8 != 0 || metaLen%8 != 0 || binary.LittleEndian.Uint32(data[offset:]) != 0xffffffff {
+		t.Fatalf("invalid record batch block: offset %d, metadata length %d", offset, metaLen)
+	}
+
+	msg := fbReader(data[offset+8 : offset+metaLen])
+	if typ := msg[msg.field(msg.root(), 1)]; typ != 3 {
+		t.Fatalf("expected record batch message, got type %d", typ)
+	}
+
+	batch := msg.ref(msg.root(), 2)
+	if l := binary.LittleEndian.Uint64(msg[msg.field(batch, 0):]); l != 3 {
+		t.Errorf("wrong length of batch, expected: 3, got: %d", l)
+	}
+
+	_, nodes := msg.vector(batch, 1)
+	nulls := []uint64{0, 1, 0, 1, 1, 0, 1}
+	for i, expected := range nulls {
+		if got := binary.LittleEndian.Uint64(msg[nodes+16*i+8:]); got != expected {
+			t.Errorf("wrong null count of %s, expected: %d, got: %d", header[i], expected, got)
+		}
+	}
+
+	body := data[offset+metaLen : offset+metaLen+bodyLen]
+	n, buffers := msg.vector(batch, 2)
+	buffer := func(i int) []byte {
+		off := binary.LittleEndian.Uint64(msg[buffers+16*i:])
+		l := binary.LittleEndian.Uint64(msg[buffers+16*i+8:])
+		if off%8 != 0 {
+			t.Errorf("buffer %d is not aligned", i)
+		}
+		return body[off : off+l]
+	}
+
+	if n != 16 {
+		t.Fatalf("wrong number of buffers, expected: 16, got: %d", n)
+	}
+
+	ids := buffer(1)
+	for i, expected := range []int64{1, -2, 3} {
+		if got := int64(binary.LittleEndian.Uint64(ids[8*i:])); got != expected {
+			t.Errorf("wrong id %d, expected: %d, got: %d", i, expected, got)
+		}
+	}
+
+	if validity := buffer(2); len(validity) != 1 || validity[0] != 0x5 {
+		t.Errorf("wrong validity of precio: %v", validity)
+	}
+
+	if f := math.Float64frombits(binary.LittleEndian.Uint64(buffer(3)[16:])); f != 2 {
+		t.Errorf("wrong precio, expected: 2, got: %v", f)
+	}
+
+	if bits := buffer(5); bits[0] != 0x5 {
+		t.Errorf("wrong booleans: %b", bits[0])
+	}
+
+	if days := int32(binary.LittleEndian.Uint32(buffer(7)[8:])); days != -1 {
+		t.Errorf("wrong date, expected: -1, got: %d", days)
+	}
+
+	if ms := binary.LittleEndian.Uint64(buffer(9)[8:]); ms != 1583056801000 {
+		t.Errorf("wrong timestamp, expected: 1583056801000, got: %d", ms)
+	}
+
+	if offsets, values := buffer(11), buffer(12); binary.LittleEndian.Uint32(offsets[12:]) != 15 || string(values) != "080012800150001" {
+		t.Errorf("wrong postal codes: %v %q", offsets, values)
+	}
+}
+
+func TestExportFeather(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos-feather")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "data.csv")
+	if err := ioutil.WriteFile(path, []byte("\ufeffa;b\n1;x\n2;y\n"), 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := exportFeather()(path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "data.feather"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !bytes.HasPrefix(data, []byte(arrowMagic)) || !bytes.Contains(data, []byte("\x01\x00\x00\x00a\x00")) {
+		t.Errorf("expected Feather file with column a")
+	}
+
+	if err := exportFeather()(filepath.Join(dir, "data.json")); err != nil {
+		t.Errorf("unexpected error with a file that is not CSV: %s", err)
+	}
+}
@@ -0,0 +1,49 @@
+package app
+
+import "sort"
+
+// Feature is an optional capability of the command line tool, which may
+// not be available in every build.
+type Feature struct {
+	Name        string
+	Description string
+	// Enabled reports whether the feature is available in this build.
+	Enabled bool
+	// Requires is what's needed to enable the feature if it's not, such
+	// as a build tag or an operating system.
+	Requires string
+}
+
+var features []Feature
+
+// registerFeature adds a feature to the ones returned by Features. It's
+// meant to be called from init functions, so files with build tags can
+// register the features they provide or the ones they leave out.
+func registerFeature(f Feature) {
+	features = append(features, f)
+}
+
+// Features returns the optional capabilities of this build sorted by
+// name, enabled or not.
+func Features() []Feature {
+	result := append([]Feature(nil), features...)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func init() {
+	for _, f := range []Feature{
+		{Name: "xlsx", Description: "export metadata and reports as Excel workbooks"},
+		{Name: "ods", Description: "export metadata and reports as OpenDocument spreadsheets"},
+		{Name: "feather", Description: "write CSV files as Feather files for pandas"},
+		{Name: "readr", Description: "write CSV files and their column types for R's readr"},
+		{Name: "geojson", Description: "convert KML files and zipped shapefiles to GeoJSON"},
+		{Name: "digest", Description: "send digests of new datasets by email"},
+		{Name: "notify", Description: "post new datasets to Mastodon, X, Matrix or Telegram"},
+	} {
+		f.Enabled = true
+		registerFeature(f)
+	}
+}
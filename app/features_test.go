@@ -0,0 +1,29 @@
+package app
+
+import "testing"
+
+func TestFeatures(t *testing.T) {
+	fs := Features()
+	if len(fs) == 0 {
+		t.Fatalf("expected features, got none")
+	}
+
+	names := make(map[string]bool)
+	for i, f := range fs {
+		if i > 0 && fs[i-1].Name > f.Name {
+			t.Errorf("features not sorted: %s before %s", fs[i-1].Name, f.Name)
+		}
+		names[f.Name] = true
+	}
+
+	for _, name := range []string{"xlsx", "feather", "readr", "xattr"} {
+		if !names[name] {
+			t.Errorf("missing feature %s", name)
+		}
+	}
+
+	fs[0].Name = "changed"
+	if Features()[0].Name == "changed" {
+		t.Errorf("expected a copy of the features")
+	}
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/erizocosmico/datos"
+)
+
+// datasetFilter reports whether a dataset must be kept. Filters are applied
+// locally to the datasets returned by the API.
+type datasetFilter func(datos.Dataset) bool
+
+func matchAll(filters []datasetFilter, ds datos.Dataset) bool {
+	for _, f := range filters {
+		if !f(ds) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeText folds the case and removes the accents of the text, so
+// "València" and "valencia" are considered equal.
+func normalizeText(s string) string {
+	return strings.ToLower(datos.StripAccents(s))
+}
+
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?")
+}
+
+// globLiteral returns the longest part of the glob pattern without any
+// wildcards, which can be used to query the API.
+func globLiteral(pattern string) string {
+	var longest string
+	for _, part := range strings.FieldsFunc(pattern, func(r rune) bool {
+		return r == '*' || r == '?'
+	}) {
+		if len(part) > len(longest) {
+			longest = part
+		}
+	}
+	return strings.TrimSpace(longest)
+}
+
+func globRegexp(pattern string) *regexp.Regexp {
+	expr := regexp.QuoteMeta(normalizeText(pattern))
+	expr = strings.Replace(expr, `\*`, `.*`, -1)
+	expr = strings.Replace(expr, `\?`, `.`, -1)
+	return regexp.MustCompile("^" + expr + "$")
+}
+
+// textFilter returns a filter that keeps the datasets having any of the
+// values returned by field containing the given text or, if it has
+// wildcards, matching the given glob pattern.
+func textFilter(text string, field func(datos.Dataset) []string) datasetFilter {
+	if isGlob(text) {
+		return regexpFilter(globRegexp(text), field)
+	}
+
+	text = normalizeText(text)
+	return func(ds datos.Dataset) bool {
+		for _, v := range field(ds) {
+			if strings.Contains(normalizeText(v), text) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// regexpFilter returns a filter that keeps the datasets having any of the
+// values returned by field matching the given regular expression. Values
+// are normalized before matching.
+func regexpFilter(re *regexp.Regexp, field func(datos.Dataset) []string) datasetFilter {
+	return func(ds datos.Dataset) bool {
+		for _, v := range field(ds) {
+			if re.MatchString(normalizeText(v)) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// linkFilter returns a filter that keeps the datasets having any of the
+// links returned by field with the given identifier as last segment.
+func linkFilter(id string, field func(datos.Dataset) []string) datasetFilter {
+	return func(ds datos.Dataset) bool {
+		for _, v := range field(ds) {
+			if strings.EqualFold(lastSegment(v), id) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// compileFilterRegexp compiles a regular expression used to filter
+// datasets. Matching is case insensitive and ignores accents.
+func compileFilterRegexp(expr string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)" + datos.StripAccents(expr))
+}
+
+func datasetTitles(ds datos.Dataset) []string {
+	return ds.Title.Texts()
+}
+
+func datasetKeywords(ds datos.Dataset) []string {
+	return ds.Keywords
+}
+
+func datasetThemes(ds datos.Dataset) []string {
+	return ds.Theme
+}
+
+func datasetPublishers(ds datos.Dataset) []string {
+	return []string{ds.Publisher}
+}
+
+// datasetLicense returns the link to the license of the dataset and its
+// identifier, or unknown if it's not a known license, or none if the
+// dataset has no license.
+func datasetLicense(ds datos.Dataset) []string {
+	switch {
+	case ds.License == "":
+		return []string{"none"}
+	case ds.License.Known():
+		return []string{string(ds.License), ds.License.ID()}
+	default:
+		return []string{string(ds.License), "unknown"}
+	}
+}
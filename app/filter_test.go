@@ -0,0 +1,77 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/erizocosmico/datos"
+)
+
+func TestTextFilter(t *testing.T) {
+	ds := datos.Dataset{Title: datos.LangStrings{{Text: "Miradores de València"}}}
+
+	testCases := []struct {
+		text     string
+		expected bool
+	}{
+		{"valencia", true},
+		{"MIRADORES", true},
+		{"mirador*", true},
+		{"*valencia", true},
+		{"miradores de ?alencia", true},
+		{"foo*", false},
+		{"castellón", false},
+	}
+
+	for _, tt := range testCases {
+		if result := textFilter(tt.text, datasetTitles)(ds); result != tt.expected {
+			t.Errorf("wrong result for %q, expected: %v, got: %v", tt.text, tt.expected, result)
+		}
+	}
+}
+
+func TestRegexpFilter(t *testing.T) {
+	ds := datos.Dataset{Title: datos.LangStrings{{Text: "Miradores de València"}}}
+	re, err := compileFilterRegexp(`^MIRADOR\S+ DE VALÈNCIA$`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !regexpFilter(re, datasetTitles)(ds) {
+		t.Errorf("expected title to match")
+	}
+}
+
+func TestGlobLiteral(t *testing.T) {
+	if l := globLiteral("*mira?dores*"); l != "dores" {
+		t.Errorf("wrong literal, expected: dores, got: %s", l)
+	}
+}
+
+func TestCombinedFilters(t *testing.T) {
+	a, err := New(nil, Config{Title: "aire", Theme: "medio-ambiente", Publisher: "L01280796"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	match := datos.Dataset{
+		Title:     datos.LangStrings{{Text: "Calidad del aire"}},
+		Theme:     datos.Strings{"http://datos.gob.es/kos/sector-publico/sector/medio-ambiente"},
+		Publisher: "http://datos.gob.es/recurso/sector-publico/org/Organismo/L01280796",
+	}
+
+	if !matchAll(a.filters, match) {
+		t.Errorf("expected dataset to match all filters")
+	}
+
+	other := match
+	other.Publisher = "http://datos.gob.es/recurso/sector-publico/org/Organismo/L01080193"
+	if matchAll(a.filters, other) {
+		t.Errorf("expected dataset of another publisher not to match")
+	}
+
+	other = match
+	other.Theme = datos.Strings{"http://datos.gob.es/kos/sector-publico/sector/salud"}
+	if matchAll(a.filters, other) {
+		t.Errorf("expected dataset of another theme not to match")
+	}
+}
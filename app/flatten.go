@@ -0,0 +1,189 @@
+package app
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// envelopeKeys are the keys commonly used to wrap the records of a JSON
+// document, such as "value" in OData responses.
+var envelopeKeys = []string{"value", "items", "results", "data", "records", "d"}
+
+// flattenJSON returns a convert function that turns downloaded JSON files
+// containing a list of objects into CSV files. The list of records is
+// selected using the given dot separated path. If the path is empty, the
+// document itself or the first list found in a common envelope is used.
+// Nested objects are flattened using dot separated column names.
+func flattenJSON(path string) convertFunc {
+	var keys []string
+	if path != "" {
+		keys = strings.Split(path, ".")
+	}
+
+	return func(file string) (string, error) {
+		if filepath.Ext(file) != ".json" {
+			return file, nil
+		}
+
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.UseNumber()
+		var doc interface{}
+		if err := d.Decode(&doc); err != nil {
+			return "", err
+		}
+
+		records, err := selectRecords(doc, keys)
+		if err != nil {
+			return "", err
+		}
+
+		var rows = make([]map[string]string, len(records))
+		var columns = make(map[string]struct{})
+		for i, r := range records {
+			rows[i] = make(map[string]string)
+			flattenValue("", r, rows[i])
+			for c := range rows[i] {
+				columns[c] = struct{}{}
+			}
+		}
+
+		var header = make([]string, 0, len(columns))
+		for c := range columns {
+			header = append(header, c)
+		}
+		sort.Strings(header)
+
+		out := strings.TrimSuffix(file, filepath.Ext(file)) + ".csv"
+		if err := writeRows(out, header, rows); err != nil {
+			return "", err
+		}
+
+		return out, os.Remove(file)
+	}
+}
+
+func selectRecords(doc interface{}, keys []string) ([]interface{}, error) {
+	for i, k := range keys {
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expecting object at %q", strings.Join(keys[:i], "."))
+		}
+
+		if doc, ok = obj[k]; !ok {
+			return nil, fmt.Errorf("key %q not found", strings.Join(keys[:i+1], "."))
+		}
+	}
+
+	if len(keys) == 0 {
+		doc = findEnvelope(doc)
+	}
+
+	records, ok := doc.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expecting a list of records, got %T", doc)
+	}
+
+	return records, nil
+}
+
+func findEnvelope(doc interface{}) interface{} {
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return doc
+	}
+
+	for _, k := range envelopeKeys {
+		if v, ok := obj[k]; ok {
+			if _, ok := v.([]interface{}); ok {
+				return v
+			}
+			return findEnvelope(v)
+		}
+	}
+
+	return doc
+}
+
+func flattenValue(prefix string, v interface{}, out map[string]string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			flattenValue(key, val, out)
+		}
+	case []interface{}:
+		var scalars []string
+		for _, elem := range v {
+			switch elem.(type) {
+			case map[string]interface{}, []interface{}:
+				b, _ := json.Marshal(v)
+				out[prefix] = string(b)
+				return
+			}
+			scalars = append(scalars, scalarString(elem))
+		}
+		out[prefix] = strings.Join(scalars, "|")
+	default:
+		if prefix == "" {
+			prefix = "value"
+		}
+		out[prefix] = scalarString(v)
+	}
+}
+
+func scalarString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func writeRows(path string, header []string, rows []map[string]string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	var record = make([]string, len(header))
+	for _, r := range rows {
+		for i, c := range header {
+			record[i] = r[c]
+		}
+
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
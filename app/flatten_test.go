@@ -0,0 +1,126 @@
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFlattenJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos-flatten")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		name     string
+		path     string
+		input    string
+		expected string
+		err      string
+	}{
+		{
+			name:     "nested objects",
+			input:    `[{"id":1,"lugar":{"municipio":"Madrid","coords":{"lat":40.4,"lon":-3.7}}}]`,
+			expected: "id,lugar.coords.lat,lugar.coords.lon,lugar.municipio\n1,40.4,-3.7,Madrid\n",
+		},
+		{
+			name:     "arrays",
+			input:    `[{"id":1,"tags":["a","b",2],"horarios":[{"dia":"lunes"}]}]`,
+			expected: "horarios,id,tags\n\"[{\"\"dia\"\":\"\"lunes\"\"}]\",1,a|b|2\n",
+		},
+		{
+			name:     "mixed keys across rows",
+			input:    `[{"a":1,"b":null},{"c":true},{"a":12345678901234567890}]`,
+			expected: "a,b,c\n1,,\n,,true\n12345678901234567890,,\n",
+		},
+		{
+			name:     "scalar records",
+			input:    `[1,"dos"]`,
+			expected: "value\n1\ndos\n",
+		},
+		{
+			name:     "odata envelope",
+			input:    `{"d":{"results":[{"a":1}]}}`,
+			expected: "a\n1\n",
+		},
+		{
+			name:     "path",
+			path:     "respuesta.filas",
+			input:    `{"respuesta":{"filas":[{"a":1}],"value":[{"b":2}]}}`,
+			expected: "a\n1\n",
+		},
+		{
+			name:  "path not found",
+			path:  "respuesta.columnas",
+			input: `{"respuesta":{"filas":[]}}`,
+			err:   `key "respuesta.columnas" not found`,
+		},
+		{
+			name:  "path through a list",
+			path:  "a.b",
+			input: `{"a":[{"b":[]}]}`,
+			err:   `expecting object at "a"`,
+		},
+		{
+			name:  "top-level object",
+			input: `{"nombre":"Madrid"}`,
+			err:   "expecting a list of records",
+		},
+		{
+			name:  "top-level scalar",
+			input: `"Madrid"`,
+			err:   "expecting a list of records",
+		},
+		{
+			name:  "invalid json",
+			input: `[{"a":`,
+			err:   "unexpected EOF",
+		},
+	}
+
+	for _, c := range cases {
+		file := filepath.Join(dir, "data.json")
+		if err := ioutil.WriteFile(file, []byte(c.input), 0644); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		out, err := flattenJSON(c.path)(file)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s: expected error containing %q, got: %v", c.name, c.err, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+			continue
+		}
+
+		if out != filepath.Join(dir, "data.csv") {
+			t.Errorf("%s: wrong output file: %s", c.name, out)
+		}
+
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("%s: expected JSON file to be removed", c.name)
+		}
+
+		data, err := ioutil.ReadFile(out)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if string(data) != c.expected {
+			t.Errorf("%s: wrong result, expected:\n%s\ngot:\n%s", c.name, c.expected, data)
+		}
+	}
+
+	file := filepath.Join(dir, "data.xml")
+	if out, err := flattenJSON("")(file); err != nil || out != file {
+		t.Errorf("expected non JSON file to be left as is, got: %s, %v", out, err)
+	}
+}
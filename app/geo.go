@@ -0,0 +1,532 @@
+package app
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type geoFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   *geoGeometry           `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoGeometry struct {
+	Type        string         `json:"type"`
+	Coordinates interface{}    `json:"coordinates,omitempty"`
+	Geometries  []*geoGeometry `json:"geometries,omitempty"`
+}
+
+type point [2]float64
+
+// convertGeo returns a convert function that turns downloaded KML files and
+// zipped shapefiles into GeoJSON files. Coordinates are not reprojected, so
+// shapefiles using a projection other than WGS84 keep their original
+// coordinate system.
+func convertGeo() convertFunc {
+	return func(file string) (string, error) {
+		var features []geoFeature
+		var err error
+		switch filepath.Ext(file) {
+		case ".kml":
+			features, err = readKML(file)
+		case ".zip":
+			var ok bool
+			features, ok, err = readZippedShapefile(file)
+			if err == nil && !ok {
+				return file, nil
+			}
+		default:
+			return file, nil
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		if features == nil {
+			features = []geoFeature{}
+		}
+
+		out := strings.TrimSuffix(file, filepath.Ext(file)) + ".geojson"
+		f, err := os.Create(out)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+
+		err = json.NewEncoder(f).Encode(struct {
+			Type     string       `json:"type"`
+			Features []geoFeature `json:"features"`
+		}{"FeatureCollection", features})
+		if err != nil {
+			return "", err
+		}
+
+		if err := f.Close(); err != nil {
+			return "", err
+		}
+
+		return out, os.Remove(file)
+	}
+}
+
+func readKML(file string) ([]geoFeature, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	doc, err := parseXML(f)
+	if err != nil {
+		return nil, err
+	}
+
+	var features []geoFeature
+	for _, p := range doc.selectNodes([]xpathStep{{"Placemark", true}}) {
+		var props = make(map[string]interface{})
+		var geom *geoGeometry
+		for _, c := range p.children {
+			switch c.name {
+			case "name", "description":
+				props[c.name] = strings.TrimSpace(c.text.String())
+			case "ExtendedData":
+				for _, d := range c.selectNodes([]xpathStep{{"*", true}}) {
+					name := attr(d, "name")
+					switch {
+					case d.name == "Data" && name != "":
+						if v := d.child("value"); v != nil {
+							props[name] = strings.TrimSpace(v.text.String())
+						}
+					case d.name == "SimpleData" && name != "":
+						props[name] = strings.TrimSpace(d.text.String())
+					}
+				}
+			default:
+				if g := kmlGeometry(c); g != nil {
+					geom = g
+				}
+			}
+		}
+
+		features = append(features, geoFeature{"Feature", geom, props})
+	}
+
+	return features, nil
+}
+
+func attr(n *xmlNode, name string) string {
+	for _, a := range n.attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func (n *xmlNode) child(name string) *xmlNode {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func kmlGeometry(n *xmlNode) *geoGeometry {
+	switch n.name {
+	case "Point":
+		coords := kmlCoordinates(n.child("coordinates"))
+		if len(coords) == 0 {
+			return nil
+		}
+		return &geoGeometry{Type: "Point", Coordinates: coords[0]}
+	case "LineString":
+		return &geoGeometry{Type: "LineString", Coordinates: kmlCoordinates(n.child("coordinates"))}
+	case "Polygon":
+		var rings [][]point
+		for _, b := range n.children {
+			if b.name != "outerBoundaryIs" && b.name != "innerBoundaryIs" {
+				continue
+			}
+
+			if r := b.child("LinearRing"); r != nil {
+				ring := kmlCoordinates(r.child("coordinates"))
+				if b.name == "outerBoundaryIs" {
+					rings = append([][]point{ring}, rings...)
+				} else {
+					rings = append(rings, ring)
+				}
+			}
+		}
+		return &geoGeometry{Type: "Polygon", Coordinates: rings}
+	case "MultiGeometry":
+		var geoms []*geoGeometry
+		for _, c := range n.children {
+			if g := kmlGeometry(c); g != nil {
+				geoms = append(geoms, g)
+			}
+		}
+		return &geoGeometry{Type: "GeometryCollection", Geometries: geoms}
+	default:
+		return nil
+	}
+}
+
+func kmlCoordinates(n *xmlNode) []point {
+	if n == nil {
+		return nil
+	}
+
+	var result []point
+	for _, tuple := range strings.Fields(n.text.String()) {
+		parts := strings.Split(tuple, ",")
+		if len(parts) < 2 {
+			continue
+		}
+
+		x, err1 := strconv.ParseFloat(parts[0], 64)
+		y, err2 := strconv.ParseFloat(parts[1], 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		result = append(result, point{x, y})
+	}
+	return result
+}
+
+// readZippedShapefile reads the first shapefile found in the given zip
+// file. It returns false if there is no shapefile in it.
+func readZippedShapefile(file string) ([]geoFeature, bool, error) {
+	z, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, false, err
+	}
+	defer z.Close()
+
+	var shp, dbf *zip.File
+	for _, f := range z.File {
+		if strings.ToLower(filepath.Ext(f.Name)) == ".shp" {
+			shp = f
+			break
+		}
+	}
+
+	if shp == nil {
+		return nil, false, nil
+	}
+
+	base := strings.ToLower(strings.TrimSuffix(shp.Name, filepath.Ext(shp.Name)))
+	for _, f := range z.File {
+		if strings.ToLower(f.Name) == base+".dbf" {
+			dbf = f
+		}
+	}
+
+	data, err := readZipFile(shp)
+	if err != nil {
+		return nil, true, err
+	}
+
+	geoms, err := readShapes(data)
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid shapefile: %s", err)
+	}
+
+	var records []map[string]interface{}
+	if dbf != nil {
+		data, err := readZipFile(dbf)
+		if err != nil {
+			return nil, true, err
+		}
+
+		records, err = readDBF(data)
+		if err != nil {
+			return nil, true, fmt.Errorf("invalid dbf file: %s", err)
+		}
+	}
+
+	var features = make([]geoFeature, len(geoms))
+	for i, g := range geoms {
+		var props map[string]interface{}
+		if i < len(records) {
+			props = records[i]
+		}
+		features[i] = geoFeature{"Feature", g, props}
+	}
+
+	return features, true, nil
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+const shpHeaderSize = 100
+
+// readShapes reads the geometries of a .shp file. Only the X and Y
+// coordinates are read, Z and M values are ignored.
+func readShapes(data []byte) ([]*geoGeometry, error) {
+	if len(data) < shpHeaderSize || binary.BigEndian.Uint32(data) != 9994 {
+		return nil, fmt.Errorf("not a shapefile")
+	}
+
+	var result []*geoGeometry
+	for pos := shpHeaderSize; pos+8 <= len(data); {
+		size := int(binary.BigEndian.Uint32(data[pos+4:])) * 2
+		pos += 8
+		if pos+size > len(data) || size < 4 {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		g, err := readShape(data[pos : pos+size])
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, g)
+		pos += size
+	}
+
+	return result, nil
+}
+
+func readShape(b []byte) (*geoGeometry, error) {
+	r := bytes.NewReader(b)
+	var typ int32
+	if err := binary.Read(r, binary.LittleEndian, &typ); err != nil {
+		return nil, err
+	}
+
+	switch typ {
+	case 0:
+		return nil, nil
+	case 1, 11, 21:
+		var p point
+		if err := binary.Read(r, binary.LittleEndian, &p); err != nil {
+			return nil, err
+		}
+		return &geoGeometry{Type: "Point", Coordinates: p}, nil
+	case 8, 18, 28:
+		var n int32
+		if _, err := r.Seek(32, io.SeekCurrent); err != nil {
+			return nil, err
+		}
+
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+			return nil, err
+		}
+
+		if err := checkShapeCounts(r, 0, n); err != nil {
+			return nil, err
+		}
+
+		var points = make([]point, n)
+		if err := binary.Read(r, binary.LittleEndian, points); err != nil {
+			return nil, err
+		}
+		return &geoGeometry{Type: "MultiPoint", Coordinates: points}, nil
+	case 3, 13, 23, 5, 15, 25:
+		var counts [2]int32
+		if _, err := r.Seek(32, io.SeekCurrent); err != nil {
+			return nil, err
+		}
+
+		if err := binary.Read(r, binary.LittleEndian, &counts); err != nil {
+			return nil, err
+		}
+
+		if err := checkShapeCounts(r, counts[0], counts[1]); err != nil {
+			return nil, err
+		}
+
+		var parts = make([]int32, counts[0])
+		if err := binary.Read(r, binary.LittleEndian, parts); err != nil {
+			return nil, err
+		}
+
+		var points = make([]point, counts[1])
+		if err := binary.Read(r, binary.LittleEndian, points); err != nil {
+			return nil, err
+		}
+
+		var rings [][]point
+		for i, start := range parts {
+			end := int32(len(points))
+			if i+1 < len(parts) {
+				end = parts[i+1]
+			}
+
+			if start < 0 || start > end || end > int32(len(points)) {
+				return nil, fmt.Errorf("invalid part offsets")
+			}
+			rings = append(rings, points[start:end])
+		}
+
+		if typ == 3 || typ == 13 || typ == 23 {
+			if len(rings) == 1 {
+				return &geoGeometry{Type: "LineString", Coordinates: rings[0]}, nil
+			}
+			return &geoGeometry{Type: "MultiLineString", Coordinates: rings}, nil
+		}
+
+		return shpPolygon(rings), nil
+	default:
+		return nil, fmt.Errorf("unsupported shape type %d", typ)
+	}
+}
+
+// checkShapeCounts checks that the record has room for the given number of
+// parts and points, so corrupt counts don't make huge allocations.
+func checkShapeCounts(r *bytes.Reader, parts, points int32) error {
+	if parts < 0 || points < 0 {
+		return fmt.Errorf("negative number of parts or points")
+	}
+
+	if int64(parts)*4+int64(points)*16 > int64(r.Len()) {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// shpPolygon groups the rings of a shapefile polygon, in which outer rings
+// are clockwise and holes are counterclockwise, into a GeoJSON polygon or
+// multipolygon, whose outer rings are counterclockwise instead.
+func shpPolygon(rings [][]point) *geoGeometry {
+	var polygons [][][]point
+	for _, r := range rings {
+		if ringArea(r) <= 0 || len(polygons) == 0 {
+			polygons = append(polygons, [][]point{reversed(r)})
+		} else {
+			last := len(polygons) - 1
+			polygons[last] = append(polygons[last], reversed(r))
+		}
+	}
+
+	if len(polygons) == 1 {
+		return &geoGeometry{Type: "Polygon", Coordinates: polygons[0]}
+	}
+	return &geoGeometry{Type: "MultiPolygon", Coordinates: polygons}
+}
+
+func ringArea(r []point) float64 {
+	var area float64
+	for i := range r {
+		j := (i + 1) % len(r)
+		area += r[i][0]*r[j][1] - r[j][0]*r[i][1]
+	}
+	return area / 2
+}
+
+func reversed(r []point) []point {
+	var result = make([]point, len(r))
+	for i, p := range r {
+		result[len(r)-1-i] = p
+	}
+	return result
+}
+
+// readDBF reads the records of a dBase file, which contains the attributes
+// of the shapes.
+func readDBF(data []byte) ([]map[string]interface{}, error) {
+	if len(data) < 32 {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	numRecords := int(binary.LittleEndian.Uint32(data[4:]))
+	headerSize := int(binary.LittleEndian.Uint16(data[8:]))
+	recordSize := int(binary.LittleEndian.Uint16(data[10:]))
+
+	type field struct {
+		name string
+		typ  byte
+		size int
+	}
+
+	var fields []field
+	for pos := 32; pos+32 <= len(data) && data[pos] != 0x0d; pos += 32 {
+		name := data[pos : pos+11]
+		if i := bytes.IndexByte(name, 0); i >= 0 {
+			name = name[:i]
+		}
+		fields = append(fields, field{dbfString(name), data[pos+11], int(data[pos+16])})
+	}
+
+	// Records start with the deletion flag, followed by the fields.
+	size := 1
+	for _, f := range fields {
+		size += f.size
+	}
+
+	if size > recordSize {
+		return nil, fmt.Errorf("fields of %d bytes don't fit in records of %d bytes", size-1, recordSize)
+	}
+
+	if int64(headerSize)+int64(numRecords)*int64(recordSize) > int64(len(data)) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	var result []map[string]interface{}
+	for i := 0; i < numRecords; i++ {
+		pos := headerSize + i*recordSize
+		if pos+recordSize > len(data) {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		// Deleted records are kept so records are still aligned with
+		// their shapes.
+		if data[pos] == '*' {
+			result = append(result, nil)
+			continue
+		}
+
+		var record = make(map[string]interface{})
+		pos++
+		for _, f := range fields {
+			value := strings.TrimSpace(dbfString(data[pos : pos+f.size]))
+			pos += f.size
+
+			if f.typ == 'N' || f.typ == 'F' {
+				if n, err := strconv.ParseFloat(value, 64); err == nil && !math.IsNaN(n) {
+					record[f.name] = n
+					continue
+				}
+			}
+			record[f.name] = value
+		}
+		result = append(result, record)
+	}
+
+	return result, nil
+}
+
+// dbfString decodes a dBase string, which is usually encoded in latin1
+// when the data comes from spanish publishers.
+func dbfString(b []byte) string {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+
+	var runes = make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
@@ -0,0 +1,281 @@
+package app
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testKML = `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2">
+<Document>
+	<Placemark>
+		<name>Museo</name>
+		<ExtendedData><Data name="aforo"><value>120</value></Data></ExtendedData>
+		<Point><coordinates>-3.69,40.41,0</coordinates></Point>
+	</Placemark>
+	<Placemark>
+		<name>Parque</name>
+		<Polygon>
+			<outerBoundaryIs><LinearRing><coordinates>0,0 1,0 1,1 0,0</coordinates></LinearRing></outerBoundaryIs>
+		</Polygon>
+	</Placemark>
+</Document>
+</kml>`
+
+// shpRecord returns a record of a shapefile with the given content.
+func shpRecord(n int, content []byte) []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.BigEndian, int32(n))
+	binary.Write(&b, binary.BigEndian, int32(len(content)/2))
+	b.Write(content)
+	return b.Bytes()
+}
+
+// shpFile returns a shapefile with the given records.
+func shpFile(records ...[]byte) []byte {
+	var b bytes.Buffer
+	header := make([]byte, shpHeaderSize)
+	binary.BigEndian.PutUint32(header, 9994)
+	b.Write(header)
+	for _, r := range records {
+		b.Write(r)
+	}
+	return b.Bytes()
+}
+
+func shpPoint(x, y float64) []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, int32(1))
+	binary.Write(&b, binary.LittleEndian, [2]float64{x, y})
+	return b.Bytes()
+}
+
+// shpPolyline returns a polyline with the given counts of parts and
+// points, which may not match the data written for corrupt files.
+func shpPolyline(parts, points int32, data ...float64) []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, int32(3))
+	b.Write(make([]byte, 32))
+	binary.Write(&b, binary.LittleEndian, [2]int32{parts, points})
+	if parts > 0 {
+		binary.Write(&b, binary.LittleEndian, int32(0))
+	}
+	binary.Write(&b, binary.LittleEndian, data)
+	return b.Bytes()
+}
+
+// dbfFile returns a dBase file with a character field of the given size
+// and the given records.
+func dbfFile(fieldSize, recordSize int, values ...string) []byte {
+	var b bytes.Buffer
+	header := make([]byte, 32)
+	header[0] = 3
+	binary.LittleEndian.PutUint32(header[4:], uint32(len(values)))
+	binary.LittleEndian.PutUint16(header[8:], uint16(32+32+1))
+	binary.LittleEndian.PutUint16(header[10:], uint16(recordSize))
+	b.Write(header)
+
+	field := make([]byte, 32)
+	copy(field, "nombre")
+	field[11] = 'C'
+	field[16] = byte(fieldSize)
+	b.Write(field)
+	b.WriteByte(0x0d)
+
+	for _, v := range values {
+		record := bytes.Repeat([]byte(" "), recordSize)
+		copy(record[1:], v)
+		b.Write(record)
+	}
+	return b.Bytes()
+}
+
+func zipFiles(t *testing.T, path string, files map[string][]byte) {
+	t.Helper()
+	var b bytes.Buffer
+	w := zip.NewWriter(&b)
+	for name, data := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		f.Write(data)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := ioutil.WriteFile(path, b.Bytes(), 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestConvertGeo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos-geo")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		name  string
+		file  string
+		kml   string
+		zip   map[string][]byte
+		types []string
+		props []string
+		err   string
+	}{
+		{
+			name:  "kml",
+			file:  "a.kml",
+			kml:   testKML,
+			types: []string{"Point", "Polygon"},
+			props: []string{"Museo", "Parque"},
+		},
+		{
+			name: "shapefile",
+			file: "b.zip",
+			zip: map[string][]byte{
+				"capa.shp": shpFile(
+					shpRecord(1, shpPoint(-3.69, 40.41)),
+					shpRecord(2, shpPolyline(1, 2, 0, 0, 1, 1)),
+				),
+				"capa.dbf": dbfFile(10, 11, "Museo", "Calle"),
+			},
+			types: []string{"Point", "LineString"},
+			props: []string{"Museo", "Calle"},
+		},
+		{
+			name: "zip without shapefile",
+			file: "c.zip",
+			zip:  map[string][]byte{"datos.csv": []byte("a\n1\n")},
+		},
+		{
+			name: "malformed kml",
+			file: "d.kml",
+			kml:  "<kml><Placemark>",
+			err:  "XML syntax error",
+		},
+		{
+			name: "not a shapefile",
+			file: "e.zip",
+			zip:  map[string][]byte{"capa.shp": []byte("shp")},
+			err:  "not a shapefile",
+		},
+		{
+			name: "truncated record",
+			file: "f.zip",
+			zip:  map[string][]byte{"capa.shp": shpFile(shpRecord(1, shpPoint(1, 2)))[:shpHeaderSize+12]},
+			err:  "unexpected EOF",
+		},
+		{
+			name: "negative count",
+			file: "g.zip",
+			zip:  map[string][]byte{"capa.shp": shpFile(shpRecord(1, shpPolyline(1, -5, 0, 0)))},
+			err:  "negative number",
+		},
+		{
+			name: "huge count",
+			file: "h.zip",
+			zip:  map[string][]byte{"capa.shp": shpFile(shpRecord(1, shpPolyline(1, math.MaxInt32, 0, 0)))},
+			err:  "unexpected EOF",
+		},
+		{
+			name: "fields larger than records",
+			file: "i.zip",
+			zip: map[string][]byte{
+				"capa.shp": shpFile(shpRecord(1, shpPoint(1, 2))),
+				"capa.dbf": dbfFile(200, 11, "Museo"),
+			},
+			err: "don't fit",
+		},
+		{
+			name: "truncated dbf",
+			file: "j.zip",
+			zip: map[string][]byte{
+				"capa.shp": shpFile(shpRecord(1, shpPoint(1, 2))),
+				"capa.dbf": dbfFile(10, 11, "Museo")[:70],
+			},
+			err: "unexpected EOF",
+		},
+	}
+
+	for _, c := range cases {
+		path := filepath.Join(dir, c.file)
+		if c.zip != nil {
+			zipFiles(t, path, c.zip)
+		} else if err := ioutil.WriteFile(path, []byte(c.kml), 0644); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		out, err := convertGeo()(path)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s: expected error containing %q, got: %v", c.name, c.err, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+			continue
+		}
+
+		if c.types == nil {
+			if out != path {
+				t.Errorf("%s: expected file not to be converted, got: %s", c.name, out)
+			}
+			continue
+		}
+
+		data, err := ioutil.ReadFile(out)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+			continue
+		}
+
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s: expected original file to be removed", c.name)
+		}
+
+		var fc struct {
+			Features []struct {
+				Geometry   *struct{ Type string }
+				Properties map[string]interface{}
+			}
+		}
+		if err := json.Unmarshal(data, &fc); err != nil {
+			t.Errorf("%s: invalid GeoJSON: %s", c.name, err)
+			continue
+		}
+
+		if len(fc.Features) != len(c.types) {
+			t.Errorf("%s: wrong number of features, expected: %d, got: %d", c.name, len(c.types), len(fc.Features))
+			continue
+		}
+
+		for i, f := range fc.Features {
+			if f.Geometry == nil || f.Geometry.Type != c.types[i] {
+				t.Errorf("%s: wrong geometry of feature %d: %+v", c.name, i, f.Geometry)
+			}
+
+			name, _ := f.Properties["name"].(string)
+			if name == "" {
+				name, _ = f.Properties["nombre"].(string)
+			}
+			if name != c.props[i] {
+				t.Errorf("%s: wrong name of feature %d, expected: %s, got: %v", c.name, i, c.props[i], f.Properties)
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// manifestFile is the name of the manifest in the output directory.
+const manifestFile = "datos-manifest.json"
+
+// ManifestEntry records the download of a dataset.
+type ManifestEntry struct {
+	// ID of the dataset.
+	ID string `json:"id"`
+	// URL of the downloaded distribution.
+	URL string `json:"url"`
+	// Modified is the time the dataset was modified when it was
+	// downloaded.
+	Modified time.Time `json:"modified"`
+	// SHA256 is the hash of the downloaded file.
+	SHA256 string `json:"sha256"`
+	// File is the name of the downloaded file in the output directory.
+	File string `json:"file"`
+	// Downloaded is the time the dataset was downloaded.
+	Downloaded time.Time `json:"downloaded"`
+}
+
+// Manifest records all the datasets downloaded to a directory.
+type Manifest struct {
+	// Datasets downloaded keyed by ID.
+	Datasets map[string]ManifestEntry `json:"datasets"`
+}
+
+// ReadManifest reads the manifest of the given output directory. If there
+// is none, an empty manifest is returned.
+func ReadManifest(dir string) (*Manifest, error) {
+	m := &Manifest{Datasets: make(map[string]ManifestEntry)}
+	data, err := ioutil.ReadFile(filepath.Join(dir, manifestFile))
+	if os.IsNotExist(err) {
+		return m, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+
+	if m.Datasets == nil {
+		m.Datasets = make(map[string]ManifestEntry)
+	}
+	return m, nil
+}
+
+// isUpdated reports whether the dataset was modified after the version
+// recorded in the manifest, or was never downloaded.
+func (m *Manifest) isUpdated(d Dataset) bool {
+	e, ok := m.Datasets[d.ID]
+	return !ok || d.Modified.IsZero() || d.Modified.After(e.Modified)
+}
+
+// add records the download of the dataset into the file at path.
+func (m *Manifest) add(d Dataset, path string) error {
+	hash, err := hashFile(path)
+	if err != nil {
+		return err
+	}
+
+	m.Datasets[d.ID] = ManifestEntry{
+		ID:         d.ID,
+		URL:        d.URL,
+		Modified:   d.Modified,
+		SHA256:     hash,
+		File:       filepath.Base(path),
+		Downloaded: time.Now(),
+	}
+	return nil
+}
+
+// write replaces the manifest of the given output directory.
+func (m *Manifest) write(dir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(dir, "."+manifestFile+".tmp")
+	if err := ioutil.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, filepath.Join(dir, manifestFile))
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	m, err := ReadManifest(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	modified := time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC)
+	d := Dataset{ID: "foo", URL: "http://example.com/foo.csv", Modified: modified}
+	if !m.isUpdated(d) {
+		t.Errorf("expected dataset not in the manifest to be updated")
+	}
+
+	path := filepath.Join(dir, "foo.csv")
+	if err := ioutil.WriteFile(path, []byte("a,b\n"), 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := m.add(d, path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := m.write(dir); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	m, err = ReadManifest(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	e := m.Datasets["foo"]
+	hash := "5be08c9684a1d25efcee09318204824278b08bbfb4aef973ffefd0b9d7478313"
+	if e.URL != d.URL || e.File != "foo.csv" || !e.Modified.Equal(modified) || e.SHA256 != hash {
+		t.Errorf("wrong manifest entry: %+v", e)
+	}
+
+	if m.isUpdated(d) {
+		t.Errorf("expected dataset not modified to not be updated")
+	}
+
+	d.Modified = modified.Add(time.Hour)
+	if !m.isUpdated(d) {
+		t.Errorf("expected modified dataset to be updated")
+	}
+}
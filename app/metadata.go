@@ -0,0 +1,173 @@
+package app
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/erizocosmico/datos"
+)
+
+// Metadata formats supported by WriteMetadata.
+const (
+	MetadataJSON   = "json"
+	MetadataNDJSON = "ndjson"
+	MetadataCSV    = "csv"
+	MetadataTable  = "table"
+	MetadataXLSX   = "xlsx"
+	MetadataODS    = "ods"
+)
+
+// DatasetMetadata is the metadata of a dataset printed by the search
+// command.
+type DatasetMetadata struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Publisher string    `json:"publisher"`
+	Formats   []string  `json:"formats"`
+	Modified  time.Time `json:"modified"`
+	URLs      []string  `json:"urls"`
+}
+
+// NewDatasetMetadata returns the metadata of the dataset.
+func NewDatasetMetadata(ds datos.Dataset) DatasetMetadata {
+	m := DatasetMetadata{
+		ID:        ds.Identifier,
+		Title:     datasetTitle(ds),
+		Publisher: lastSegment(ds.Publisher),
+		Modified:  ds.Modified.Time,
+		Formats:   []string{},
+		URLs:      []string{},
+	}
+
+	if m.ID == "" {
+		m.ID = lastSegment(ds.About)
+	}
+
+	seen := make(map[string]bool)
+	for _, d := range ds.Distribution {
+		if f := d.Format.Value; f != "" && !seen[f] {
+			seen[f] = true
+			m.Formats = append(m.Formats, f)
+		}
+
+		if d.AccessURL != "" {
+			m.URLs = append(m.URLs, d.AccessURL)
+		}
+	}
+
+	return m
+}
+
+// WriteMetadata writes the metadata of the datasets to w in the given
+// format, which can be json, ndjson, csv, table, xlsx or ods. The JSON
+// formats follow MetadataSchema, ndjson with an object per line. The
+// spreadsheets have a sheet for the datasets and another one for their
+// distributions.
+func WriteMetadata(w io.Writer, format string, datasets []datos.Dataset) error {
+	meta := make([]DatasetMetadata, len(datasets))
+	for i, ds := range datasets {
+		meta[i] = NewDatasetMetadata(ds)
+	}
+
+	switch format {
+	case MetadataJSON:
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		return e.Encode(meta)
+	case MetadataNDJSON:
+		e := json.NewEncoder(w)
+		for _, m := range meta {
+			if err := e.Encode(m); err != nil {
+				return err
+			}
+		}
+		return nil
+	case MetadataCSV:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"id", "title", "publisher", "formats", "modified", "urls"})
+		for _, m := range meta {
+			cw.Write([]string{
+				m.ID,
+				m.Title,
+				m.Publisher,
+				strings.Join(m.Formats, " "),
+				formatModified(m.Modified),
+				strings.Join(m.URLs, " "),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	case MetadataTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tTITLE\tPUBLISHER\tFORMATS\tMODIFIED")
+		for _, m := range meta {
+			fmt.Fprintf(
+				tw, "%s\t%s\t%s\t%s\t%s\n",
+				m.ID,
+				previewCell(m.Title),
+				m.Publisher,
+				strings.Join(m.Formats, ", "),
+				formatModified(m.Modified),
+			)
+		}
+		return tw.Flush()
+	case MetadataXLSX:
+		return WriteXLSX(w, metadataSheets(meta, datasets))
+	case MetadataODS:
+		return WriteODS(w, metadataSheets(meta, datasets))
+	default:
+		return fmt.Errorf("invalid output format: %s", format)
+	}
+}
+
+func metadataSheets(meta []DatasetMetadata, datasets []datos.Dataset) []Sheet {
+	ds := Sheet{
+		Name: "Datasets",
+		Rows: [][]interface{}{{"ID", "Title", "Publisher", "Formats", "Modified", "URLs"}},
+	}
+	for _, m := range meta {
+		ds.Rows = append(ds.Rows, []interface{}{
+			m.ID,
+			m.Title,
+			m.Publisher,
+			strings.Join(m.Formats, ", "),
+			m.Modified,
+			strings.Join(m.URLs, " "),
+		})
+	}
+
+	dists := Sheet{
+		Name: "Distributions",
+		Rows: [][]interface{}{{"Dataset", "Title", "Format", "Size", "URL"}},
+	}
+	for i, d := range datasets {
+		for _, dist := range d.Distribution {
+			var size interface{} = ""
+			if dist.ByteSize > 0 {
+				size = dist.ByteSize
+			}
+
+			dists.Rows = append(dists.Rows, []interface{}{
+				meta[i].ID,
+				strings.Join([]string(dist.Title), ", "),
+				dist.Format.Value,
+				size,
+				dist.AccessURL,
+			})
+		}
+	}
+
+	return []Sheet{ds, dists}
+}
+
+func formatModified(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/erizocosmico/datos"
+)
+
+func TestWriteMetadata(t *testing.T) {
+	ds := datos.Dataset{
+		About:     "http://datos.gob.es/catalogo/l01280796-calidad-del-aire",
+		Title:     datos.LangStrings{{Text: "Calidad del aire"}},
+		Publisher: "http://datos.gob.es/recurso/sector-publico/org/Organismo/L01280796",
+		Distribution: datos.Distributions{
+			{AccessURL: "http://example.com/aire.csv"},
+			{AccessURL: "http://example.com/aire.json"},
+		},
+	}
+
+	var b strings.Builder
+	if err := WriteMetadata(&b, MetadataCSV, []datos.Dataset{ds}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := "id,title,publisher,formats,modified,urls\n" +
+		"l01280796-calidad-del-aire,Calidad del aire,L01280796,,,http://example.com/aire.csv http://example.com/aire.json\n"
+	if b.String() != expected {
+		t.Errorf("wrong CSV, expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+
+	b.Reset()
+	if err := WriteMetadata(&b, MetadataJSON, []datos.Dataset{ds}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.Contains(b.String(), `"publisher": "L01280796"`) {
+		t.Errorf("expected publisher in JSON output, got:\n%s", b.String())
+	}
+
+	if err := WriteMetadata(&b, "xml", nil); err == nil {
+		t.Errorf("expected error with invalid format")
+	}
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	decimalComma    = regexp.MustCompile(`^-?(\d{1,3}(\.\d{3})+|\d+),\d+$`)
+	thousandsDots   = regexp.MustCompile(`^-?\d{1,3}(\.\d{3}){2,}$`)
+	spanishDate     = regexp.MustCompile(`^(\d{1,2})/(\d{1,2})/(\d{4})$`)
+	spanishDateTime = regexp.MustCompile(`^(\d{1,2})/(\d{1,2})/(\d{4})[ T](\d{1,2}):(\d{2})(:(\d{2}))?$`)
+)
+
+// normalizeValue converts a value using spanish conventions (decimal comma,
+// DD/MM/YYYY dates) into its canonical form.
+func normalizeValue(v string) string {
+	v = strings.TrimSpace(v)
+
+	switch {
+	case decimalComma.MatchString(v):
+		v = strings.Replace(v, ".", "", -1)
+		return strings.Replace(v, ",", ".", 1)
+	case thousandsDots.MatchString(v):
+		return strings.Replace(v, ".", "", -1)
+	}
+
+	if m := spanishDate.FindStringSubmatch(v); m != nil {
+		if d, ok := isoDate(m[1], m[2], m[3]); ok {
+			return d
+		}
+		return v
+	}
+
+	if m := spanishDateTime.FindStringSubmatch(v); m != nil {
+		d, ok := isoDate(m[1], m[2], m[3])
+		if !ok {
+			return v
+		}
+
+		sec := m[7]
+		if sec == "" {
+			sec = "00"
+		}
+
+		h, _ := strconv.Atoi(m[4])
+		return fmt.Sprintf("%sT%02d:%s:%s", d, h, m[5], sec)
+	}
+
+	return v
+}
+
+// isoDate returns the date in ISO 8601 format, if it's a valid date.
+func isoDate(day, month, year string) (string, bool) {
+	d, _ := strconv.Atoi(day)
+	m, _ := strconv.Atoi(month)
+	y, _ := strconv.Atoi(year)
+
+	// Out of range days and months are normalized by time.Date, so
+	// 31/02/2019 would become 2019-03-03.
+	t := time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+	if t.Day() != d || int(t.Month()) != m || t.Year() != y {
+		return "", false
+	}
+	return t.Format("2006-01-02"), true
+}
+
+// normalize returns a process function that converts downloaded CSV files
+// into comma separated files with dots as decimal separator and ISO 8601
+// dates.
+func normalize() processFunc {
+	return func(path string) error {
+		if filepath.Ext(path) != ".csv" {
+			return nil
+		}
+
+		return rewriteCSV(path, ',', func(i int, record []string) []string {
+			if i == 0 {
+				if len(record) > 0 {
+					record[0] = strings.TrimPrefix(record[0], "\ufeff")
+				}
+				return record
+			}
+
+			for j, v := range record {
+				record[j] = normalizeValue(v)
+			}
+			return record
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizeValue(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected string
+	}{
+		{"1.234,56", "1234.56"},
+		{"-3,5", "-3.5"},
+		{"1.234.567", "1234567"},
+		{"1.234", "1.234"},
+		{"18/11/2012", "2012-11-18"},
+		{"1/2/2019 9:05", "2019-02-01T09:05:00"},
+		{"32/01/2019", "32/01/2019"},
+		{"31/02/2019", "31/02/2019"},
+		{"29/02/2019", "29/02/2019"},
+		{"29/02/2020", "2020-02-29"},
+		{"31/04/2019 10:00", "31/04/2019 10:00"},
+		{"0/01/2019", "0/01/2019"},
+		{" abc ", "abc"},
+	}
+
+	for _, tt := range testCases {
+		if result := normalizeValue(tt.value); result != tt.expected {
+			t.Errorf("wrong result for %q, expected: %s, got: %s", tt.value, tt.expected, result)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos-normalize")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			"semicolons",
+			"fecha;importe\n18/11/2012;1.234,56\n31/02/2019;-3,5\n",
+			"fecha,importe\n2012-11-18,1234.56\n31/02/2019,-3.5\n",
+		},
+		{
+			"commas",
+			"nombre,importe\nAna,\"2,5\"\n",
+			"nombre,importe\nAna,2.5\n",
+		},
+		{
+			"bom",
+			"\ufeffnombre,importe\nAna,\"1.000,25\"\n",
+			"nombre,importe\nAna,1000.25\n",
+		},
+		{
+			"quoted delimiters",
+			"nombre;direccion\nAna;\"Calle Mayor, 1\"\n",
+			"nombre,direccion\nAna,\"Calle Mayor, 1\"\n",
+		},
+	}
+
+	for _, c := range cases {
+		path := filepath.Join(dir, "data.csv")
+		if err := ioutil.WriteFile(path, []byte(c.input), 0644); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if err := normalize()(path); err != nil {
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+			continue
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if string(data) != c.expected {
+			t.Errorf("%s: wrong result, expected:\n%s\ngot:\n%s", c.name, c.expected, data)
+		}
+	}
+}
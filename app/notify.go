@@ -0,0 +1,426 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/erizocosmico/datos"
+)
+
+// Notifier posts short messages to a social network or chat.
+type Notifier interface {
+	// Name of the social network or chat, used in errors and logs.
+	Name() string
+	// MaxLength is the maximum number of characters of a post, counting
+	// every link as linkLength characters.
+	MaxLength() int
+	// Post publishes the text.
+	Post(ctx context.Context, text string) error
+}
+
+// linkLength is the number of characters a link counts towards the length
+// of a post in both Mastodon and X, regardless of its actual length. Chats
+// have limits long enough for it not to matter.
+const linkLength = 23
+
+// Mastodon posts statuses to an account of a Mastodon server.
+type Mastodon struct {
+	// Server is the URL of the server, e.g. https://mastodon.social.
+	Server string
+	// Token is the access token of the account, with the write:statuses
+	// scope.
+	Token string
+	// Visibility of the statuses: public, unlisted, private or direct. By
+	// default, the one of the account.
+	Visibility string
+	// Client used to make the requests. By default, one with a timeout of
+	// 30 seconds.
+	Client *http.Client
+}
+
+// Name implements the Notifier interface.
+func (m *Mastodon) Name() string { return "mastodon" }
+
+// MaxLength implements the Notifier interface.
+func (m *Mastodon) MaxLength() int { return 500 }
+
+// Post implements the Notifier interface.
+func (m *Mastodon) Post(ctx context.Context, text string) error {
+	form := url.Values{"status": {text}}
+	if m.Visibility != "" {
+		form.Set("visibility", m.Visibility)
+	}
+
+	req, err := http.NewRequest("POST", strings.TrimRight(m.Server, "/")+"/api/v1/statuses", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	// The same status is not posted twice if a request is retried.
+	sum := sha256.Sum256([]byte(text))
+	req.Header.Set("Idempotency-Key", hex.EncodeToString(sum[:]))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return postRequest(ctx, m.Client, m.Name(), m.Token, req)
+}
+
+// X posts tweets to an account of X, formerly Twitter.
+type X struct {
+	// Token is an OAuth 2.0 user access token of the account, with the
+	// tweet.write scope.
+	Token string
+	// URL of the API, by default https://api.twitter.com.
+	URL string
+	// Client used to make the requests. By default, one with a timeout of
+	// 30 seconds.
+	Client *http.Client
+}
+
+// Name implements the Notifier interface.
+func (x *X) Name() string { return "x" }
+
+// MaxLength implements the Notifier interface.
+func (x *X) MaxLength() int { return 280 }
+
+// Post implements the Notifier interface.
+func (x *X) Post(ctx context.Context, text string) error {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+
+	api := x.URL
+	if api == "" {
+		api = "https://api.twitter.com"
+	}
+
+	req, err := http.NewRequest("POST", strings.TrimRight(api, "/")+"/2/tweets", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	return postRequest(ctx, x.Client, x.Name(), x.Token, req)
+}
+
+// Matrix sends messages to a room of a Matrix homeserver.
+type Matrix struct {
+	// Homeserver is the URL of the homeserver, e.g. https://matrix.org.
+	Homeserver string
+	// Room is the ID of the room, e.g. !abcdef:matrix.org. The account must
+	// have joined it.
+	Room string
+	// Token is the access token of the account.
+	Token string
+	// Client used to make the requests. By default, one with a timeout of
+	// 30 seconds.
+	Client *http.Client
+}
+
+// Name implements the Notifier interface.
+func (m *Matrix) Name() string { return "matrix" }
+
+// MaxLength implements the Notifier interface.
+func (m *Matrix) MaxLength() int { return 1000 }
+
+// Post implements the Notifier interface.
+func (m *Matrix) Post(ctx context.Context, text string) error {
+	body, err := json.Marshal(map[string]string{"msgtype": "m.text", "body": text})
+	if err != nil {
+		return err
+	}
+
+	// The transaction ID makes the homeserver ignore the message if a
+	// request is retried.
+	sum := sha256.Sum256([]byte(text))
+	u := fmt.Sprintf(
+		"%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimRight(m.Homeserver, "/"),
+		url.PathEscape(m.Room),
+		hex.EncodeToString(sum[:16]),
+	)
+
+	req, err := http.NewRequest("PUT", u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	return postRequest(ctx, m.Client, m.Name(), m.Token, req)
+}
+
+// Telegram sends messages to a chat with a Telegram bot.
+type Telegram struct {
+	// Token of the bot, given by @BotFather.
+	Token string
+	// Chat is the ID of the chat, or the username of the channel such as
+	// @datosgob. The bot must be a member of it.
+	Chat string
+	// URL of the API, by default https://api.telegram.org.
+	URL string
+	// Client used to make the requests. By default, one with a timeout of
+	// 30 seconds.
+	Client *http.Client
+}
+
+// Name implements the Notifier interface.
+func (t *Telegram) Name() string { return "telegram" }
+
+// MaxLength implements the Notifier interface.
+func (t *Telegram) MaxLength() int { return 1000 }
+
+// Post implements the Notifier interface.
+func (t *Telegram) Post(ctx context.Context, text string) error {
+	body, err := json.Marshal(map[string]string{"chat_id": t.Chat, "text": text})
+	if err != nil {
+		return err
+	}
+
+	api := t.URL
+	if api == "" {
+		api = "https://api.telegram.org"
+	}
+
+	req, err := http.NewRequest("POST", strings.TrimRight(api, "/")+"/bot"+t.Token+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	return postRequest(ctx, t.Client, t.Name(), "", req)
+}
+
+// postRequest makes the request with the token, if any, and checks it
+// succeeded.
+func postRequest(ctx context.Context, client *http.Client, name, token string, req *http.Request) error {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		// The URL is left out of the error, since some APIs have the token
+		// in it.
+		if uerr, ok := err.(*url.Error); ok {
+			err = uerr.Err
+		}
+		return fmt.Errorf("%s: %s", name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: unexpected status %d: %s", name, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
+
+// PostText returns the text of the post announcing the dataset, with its
+// title, formats and a link to its page in the portal. The title is
+// shortened if the post would be longer than max characters.
+func PostText(ds datos.Dataset, max int) string {
+	m := NewDatasetMetadata(ds)
+	prefix := "New dataset: "
+	var suffix string
+	if len(m.Formats) > 0 {
+		suffix = " (" + strings.Join(shortFormats(m.Formats), ", ") + ")"
+	}
+
+	title := m.Title
+	available := max - utf8.RuneCountInString(prefix+suffix) - 1 - linkLength
+	if utf8.RuneCountInString(title) > available && available > 0 {
+		title = strings.TrimSpace(string([]rune(title)[:available-1])) + "…"
+	}
+
+	return prefix + title + suffix + "\n" + ds.PortalURL()
+}
+
+// shortFormats returns the formats without the MIME type prefix, e.g. csv
+// instead of text/csv.
+func shortFormats(formats []string) []string {
+	result := make([]string, len(formats))
+	for i, f := range formats {
+		result[i] = strings.ToUpper(lastSegment(f))
+	}
+	return result
+}
+
+// PostedDatasets records the datasets already posted, so they are not
+// posted again in following runs.
+type PostedDatasets struct {
+	path string
+	keys map[string]time.Time
+}
+
+// LoadPostedDatasets reads the datasets already posted from the JSON file
+// at path. If the file does not exist, no dataset has been posted yet.
+func LoadPostedDatasets(path string) (*PostedDatasets, error) {
+	p := &PostedDatasets{path: path, keys: make(map[string]time.Time)}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return p, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &p.keys); err != nil {
+		return nil, fmt.Errorf("invalid posted datasets file %s: %s", path, err)
+	}
+	return p, nil
+}
+
+// Has reports whether the dataset was already posted with all the
+// notifiers.
+func (p *PostedDatasets) Has(ds datos.Dataset) bool {
+	_, ok := p.keys[ds.Key()]
+	return ok
+}
+
+// Add records the dataset as posted with all the notifiers.
+func (p *PostedDatasets) Add(ds datos.Dataset) {
+	p.keys[ds.Key()] = time.Now().UTC()
+	for k := range p.keys {
+		if strings.HasSuffix(k, " "+ds.Key()) {
+			delete(p.keys, k)
+		}
+	}
+}
+
+// HasPost reports whether the dataset was already posted with the notifier
+// with the given name.
+func (p *PostedDatasets) HasPost(ds datos.Dataset, notifier string) bool {
+	_, ok := p.keys[notifier+" "+ds.Key()]
+	return ok || p.Has(ds)
+}
+
+// AddPost records the dataset as posted with the notifier with the given
+// name, so it's not posted with it again if other notifiers failed.
+func (p *PostedDatasets) AddPost(ds datos.Dataset, notifier string) {
+	p.keys[notifier+" "+ds.Key()] = time.Now().UTC()
+}
+
+// Prune forgets the datasets posted before the given time, so the file
+// does not grow forever.
+func (p *PostedDatasets) Prune(before time.Time) {
+	for k, t := range p.keys {
+		if t.Before(before) {
+			delete(p.keys, k)
+		}
+	}
+}
+
+// Save writes the datasets posted to the file they were loaded from.
+func (p *PostedDatasets) Save() error {
+	data, err := json.MarshalIndent(p.keys, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := p.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, p.path)
+}
+
+// NotifyError is returned when some datasets could not be posted with
+// some notifiers.
+type NotifyError []error
+
+func (e NotifyError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d post(s) failed:", len(e))
+	for _, err := range e {
+		fmt.Fprintf(&b, "\n- %s", err)
+	}
+	return b.String()
+}
+
+// NotifyDatasets posts every dataset not posted yet with all the notifiers,
+// from the oldest to the newest, and records them as posted. If a post
+// fails, the rest are still made and a NotifyError with all the failures
+// is returned. Every successful post is recorded, so datasets are only
+// posted again with the notifiers that failed. It returns the number of
+// datasets posted with all the notifiers.
+func NotifyDatasets(ctx context.Context, notifiers []Notifier, datasets []datos.Dataset, posted *PostedDatasets) (int, error) {
+	pending := make([]datos.Dataset, 0, len(datasets))
+	for _, ds := range datasets {
+		if !posted.Has(ds) {
+			pending = append(pending, ds)
+		}
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].Issued.Before(pending[j].Issued.Time)
+	})
+
+	// Notifiers with the same name, such as two Mastodon accounts, are
+	// told apart by their position among them.
+	names := make([]string, len(notifiers))
+	seen := make(map[string]int)
+	for i, nt := range notifiers {
+		names[i] = nt.Name()
+		if seen[names[i]]++; seen[names[i]] > 1 {
+			names[i] += fmt.Sprintf("#%d", seen[names[i]])
+		}
+	}
+
+	var n int
+	var errs NotifyError
+	for _, ds := range pending {
+		var failed bool
+		for i, nt := range notifiers {
+			if posted.HasPost(ds, names[i]) {
+				continue
+			}
+
+			if err := nt.Post(ctx, PostText(ds, nt.MaxLength())); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %s", ds.Key(), err))
+				failed = true
+				continue
+			}
+
+			posted.AddPost(ds, names[i])
+		}
+
+		if !failed {
+			posted.Add(ds)
+			n++
+		}
+	}
+
+	if len(errs) > 0 {
+		return n, errs
+	}
+	return n, nil
+}
+
+// Published returns the datasets matching the configuration published
+// between from and to.
+func (a *App) Published(ctx context.Context, from, to time.Time) ([]datos.Dataset, error) {
+	query := a.query
+	defer func() { a.query = query }()
+
+	a.query = func(ctx context.Context, p datos.Params) ([]datos.Dataset, error) {
+		return a.client.DatasetsIssuedBetween(ctx, from, to, p)
+	}
+	return a.Search(ctx)
+}
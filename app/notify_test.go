@@ -0,0 +1,367 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/erizocosmico/datos"
+)
+
+type recordingNotifier struct {
+	posts []string
+}
+
+func (n *recordingNotifier) Name() string   { return "test" }
+func (n *recordingNotifier) MaxLength() int { return 100 }
+func (n *recordingNotifier) Post(ctx context.Context, text string) error {
+	n.posts = append(n.posts, text)
+	return nil
+}
+
+type failingNotifier struct {
+	recordingNotifier
+	fail bool
+}
+
+func (n *failingNotifier) Name() string { return "failing" }
+func (n *failingNotifier) Post(ctx context.Context, text string) error {
+	if n.fail {
+		return errors.New("failing: unexpected status 500")
+	}
+	return n.recordingNotifier.Post(ctx, text)
+}
+
+func TestPostText(t *testing.T) {
+	var dist datos.Distribution
+	dist.Format.Value = "text/csv"
+	ds := datos.Dataset{
+		About:        "http://datos.gob.es/apidata/catalog/dataset/l01280796-calidad-del-aire",
+		Title:        datos.LangStrings{{Text: "Calidad del aire"}},
+		Distribution: datos.Distributions{dist},
+	}
+
+	expected := "New dataset: Calidad del aire (CSV)\n" + datos.PortalURL("l01280796-calidad-del-aire")
+	if got := PostText(ds, 500); got != expected {
+		t.Errorf("wrong post, expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	ds.Title = datos.LangStrings{{Text: strings.Repeat("aire ", 100)}}
+	text := PostText(ds, 280)
+	first := strings.Split(text, "\n")[0]
+	if n := utf8.RuneCountInString(first) + 1 + linkLength; n != 280 {
+		t.Errorf("wrong length of post, expected: 280, got: %d", n)
+	}
+
+	if !strings.HasSuffix(first, "… (CSV)") {
+		t.Errorf("expected shortened title, got: %s", first)
+	}
+}
+
+func TestMastodon(t *testing.T) {
+	var auth, status, key string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/statuses" {
+			http.NotFound(w, r)
+			return
+		}
+
+		auth = r.Header.Get("Authorization")
+		key = r.Header.Get("Idempotency-Key")
+		status = r.FormValue("status")
+		w.Write([]byte(`{"id": "1"}`))
+	}))
+	defer srv.Close()
+
+	m := &Mastodon{Server: srv.URL + "/", Token: "secret"}
+	if err := m.Post(context.Background(), "hola"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if auth != "Bearer secret" || status != "hola" || key == "" {
+		t.Errorf("wrong request, auth: %q, status: %q, idempotency key: %q", auth, status, key)
+	}
+
+	m.Server = srv.URL + "/missing"
+	if err := m.Post(context.Background(), "hola"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected error with status 404, got: %v", err)
+	}
+}
+
+func TestX(t *testing.T) {
+	var body map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/tweets" || r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	x := &X{Token: "secret", URL: srv.URL}
+	if err := x.Post(context.Background(), "hola"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if body["text"] != "hola" {
+		t.Errorf("wrong text, expected: hola, got: %q", body["text"])
+	}
+}
+
+func TestNotifyDatasets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos-notify")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "posted.json")
+	posted, err := LoadPostedDatasets(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	day := func(d int) datos.Datetime {
+		return datos.Datetime{Time: time.Date(2020, time.March, d, 0, 0, 0, 0, time.UTC)}
+	}
+	datasets := []datos.Dataset{
+		{About: "http://datos.gob.es/catalogo/b", Title: datos.LangStrings{{Text: "B"}}, Issued: day(2)},
+		{About: "http://datos.gob.es/catalogo/a", Title: datos.LangStrings{{Text: "A"}}, Issued: day(1)},
+	}
+
+	n := &recordingNotifier{}
+	count, err := NotifyDatasets(context.Background(), []Notifier{n}, datasets, posted)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if count != 2 || len(n.posts) != 2 || !strings.HasPrefix(n.posts[0], "New dataset: A\n") {
+		t.Errorf("expected datasets to be posted from the oldest, got: %q", n.posts)
+	}
+
+	if err := posted.Save(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	posted, err = LoadPostedDatasets(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	datasets = append(datasets, datos.Dataset{About: "http://datos.gob.es/catalogo/c", Title: datos.LangStrings{{Text: "C"}}})
+	n.posts = nil
+	if _, err := NotifyDatasets(context.Background(), []Notifier{n}, datasets, posted); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(n.posts) != 1 || !strings.HasPrefix(n.posts[0], "New dataset: C\n") {
+		t.Errorf("expected only the new dataset to be posted, got: %q", n.posts)
+	}
+
+	posted.Prune(time.Now().Add(time.Hour))
+	if posted.Has(datasets[0]) {
+		t.Errorf("expected dataset to be forgotten after pruning")
+	}
+}
+
+func TestNotifyDatasetsPartialFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos-notify")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "posted.json")
+	posted, err := LoadPostedDatasets(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	datasets := []datos.Dataset{
+		{About: "http://datos.gob.es/catalogo/a", Title: datos.LangStrings{{Text: "A"}}},
+		{About: "http://datos.gob.es/catalogo/b", Title: datos.LangStrings{{Text: "B"}}},
+	}
+
+	ok, other := &recordingNotifier{}, &recordingNotifier{}
+	failing := &failingNotifier{fail: true}
+	notifiers := []Notifier{ok, failing, other}
+	count, err := NotifyDatasets(context.Background(), notifiers, datasets, posted)
+	if count != 0 {
+		t.Errorf("expected no dataset to be posted with all notifiers, got: %d", count)
+	}
+
+	errs, isNotifyErr := err.(NotifyError)
+	if !isNotifyErr || len(errs) != 2 {
+		t.Fatalf("expected error for every failed post, got: %v", err)
+	}
+
+	if !strings.HasPrefix(errs[0].Error(), "a: failing:") {
+		t.Errorf("wrong error: %s", errs[0])
+	}
+
+	if len(ok.posts) != 2 || len(other.posts) != 2 {
+		t.Errorf("expected other notifiers to post every dataset, got: %q, %q", ok.posts, other.posts)
+	}
+
+	if posted.Has(datasets[0]) || !posted.HasPost(datasets[0], "test") || posted.HasPost(datasets[0], "failing") {
+		t.Errorf("expected dataset to be recorded as posted only with the notifiers that succeeded")
+	}
+
+	if err := posted.Save(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	posted, err = LoadPostedDatasets(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ok.posts, other.posts = nil, nil
+	failing.fail = false
+	count, err = NotifyDatasets(context.Background(), notifiers, datasets, posted)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if count != 2 || len(failing.posts) != 2 || len(ok.posts) != 0 || len(other.posts) != 0 {
+		t.Errorf("expected datasets to be posted only with the notifier that failed, got: %d, %q, %q, %q", count, failing.posts, ok.posts, other.posts)
+	}
+
+	if !posted.Has(datasets[0]) || !posted.Has(datasets[1]) {
+		t.Errorf("expected datasets to be recorded as posted with all notifiers")
+	}
+
+	if err := posted.Save(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if strings.Contains(string(data), "test ") {
+		t.Errorf("expected posts of each notifier to be forgotten once posted with all, got: %s", data)
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	var method, path, auth string
+	var body map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path, auth = r.Method, r.URL.EscapedPath(), r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"event_id": "$1"}`))
+	}))
+	defer srv.Close()
+
+	m := &Matrix{Homeserver: srv.URL, Room: "!abc:matrix.org", Token: "secret"}
+	if err := m.Post(context.Background(), "hola"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	prefix := "/_matrix/client/v3/rooms/%21abc:matrix.org/send/m.room.message/"
+	if method != "PUT" || !strings.HasPrefix(path, prefix) || auth != "Bearer secret" {
+		t.Errorf("wrong request: %s %s (%s)", method, path, auth)
+	}
+
+	if body["msgtype"] != "m.text" || body["body"] != "hola" {
+		t.Errorf("wrong message: %v", body)
+	}
+}
+
+func TestTelegram(t *testing.T) {
+	var path string
+	var body map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["chat_id"] != "@datos" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ok": false, "description": "chat not found"}`))
+			return
+		}
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer srv.Close()
+
+	tg := &Telegram{Token: "123:secret", Chat: "@datos", URL: srv.URL}
+	if err := tg.Post(context.Background(), "hola"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if path != "/bot123:secret/sendMessage" || body["text"] != "hola" {
+		t.Errorf("wrong request to %s: %v", path, body)
+	}
+
+	tg.Chat = "@otro"
+	err := tg.Post(context.Background(), "hola")
+	if err == nil || !strings.Contains(err.Error(), "chat not found") {
+		t.Errorf("expected chat not found error, got: %v", err)
+	}
+
+	tg.URL = "http://127.0.0.1:0"
+	err = tg.Post(context.Background(), "hola")
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("expected error without the token, got: %v", err)
+	}
+}
+
+func TestNotifyDatasetsChats(t *testing.T) {
+	var messages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/bot") {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"ok": false, "description": "bot was kicked from the channel"}`))
+			return
+		}
+
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		messages = append(messages, body["body"])
+		w.Write([]byte(`{"event_id": "$1"}`))
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "datos-notify")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	posted, err := LoadPostedDatasets(filepath.Join(dir, "posted.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ds := datos.Dataset{About: "http://datos.gob.es/catalogo/a", Title: datos.LangStrings{{Text: "A"}}}
+	notifiers := []Notifier{
+		&Telegram{Token: "123:secret", Chat: "@datos", URL: srv.URL},
+		&Matrix{Homeserver: srv.URL, Room: "!abc:matrix.org", Token: "secret"},
+	}
+
+	count, err := NotifyDatasets(context.Background(), notifiers, []datos.Dataset{ds}, posted)
+	if count != 0 || err == nil || !strings.Contains(err.Error(), "telegram: unexpected status 403") {
+		t.Errorf("expected telegram error, got: %d, %v", count, err)
+	}
+
+	if len(messages) != 1 || !strings.HasPrefix(messages[0], "New dataset: A\n") {
+		t.Errorf("expected message to be sent to the Matrix room, got: %q", messages)
+	}
+
+	if !posted.HasPost(ds, "matrix") || posted.HasPost(ds, "telegram") {
+		t.Errorf("expected dataset to be recorded as posted only with Matrix")
+	}
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const odsMimetype = "application/vnd.oasis.opendocument.spreadsheet"
+
+const odsManifest = `<?xml version="1.0" encoding="UTF-8"?>
+<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">
+<manifest:file-entry manifest:full-path="/" manifest:media-type="` + odsMimetype + `"/>
+<manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>
+</manifest:manifest>`
+
+// WriteODS writes the sheets as an OpenDocument spreadsheet. Like with
+// WriteXLSX, headers are bold and dates are formatted as such.
+func WriteODS(w io.Writer, sheets []Sheet) error {
+	z := zip.NewWriter(w)
+
+	// The mimetype must be the first file of the package and it can't be
+	// compressed, so applications can detect the type of the file.
+	fw, err := z.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(fw, odsMimetype); err != nil {
+		return err
+	}
+
+	files := []struct{ name, content string }{
+		{"META-INF/manifest.xml", odsManifest},
+		{"content.xml", odsContent(sheets)},
+	}
+
+	for _, f := range files {
+		fw, err := z.Create(f.name)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.WriteString(fw, f.content); err != nil {
+			return err
+		}
+	}
+
+	return z.Close()
+}
+
+// odsContent returns the content.xml of a spreadsheet with the sheets.
+func odsContent(sheets []Sheet) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0" xmlns:number="urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0" office:version="1.2">
+<office:automatic-styles>
+<number:date-style style:name="N1"><number:year/><number:text>-</number:text><number:month number:style="long"/><number:text>-</number:text><number:day number:style="long"/><number:text> </number:text><number:hours number:style="long"/><number:text>:</number:text><number:minutes number:style="long"/><number:text>:</number:text><number:seconds number:style="long"/></number:date-style>
+<style:style style:name="header" style:family="table-cell"><style:text-properties fo:font-weight="bold"/></style:style>
+<style:style style:name="date" style:family="table-cell" style:data-style-name="N1"/>
+`)
+
+	for i, s := range sheets {
+		for j, w := range columnWidths(s.Rows) {
+			fmt.Fprintf(
+				&b, `<style:style style:name="co%d-%d" style:family="table-column"><style:table-column-properties style:column-width="%.2fcm"/></style:style>`,
+				i+1, j+1, float64(w)*0.2,
+			)
+		}
+	}
+	b.WriteString("</office:automatic-styles>\n<office:body><office:spreadsheet>")
+
+	for i, s := range sheets {
+		fmt.Fprintf(&b, `<table:table table:name="%s">`, xmlEscape(sheetName(s.Name)))
+		for j := range columnWidths(s.Rows) {
+			fmt.Fprintf(&b, `<table:table-column table:style-name="co%d-%d"/>`, i+1, j+1)
+		}
+
+		for j, row := range s.Rows {
+			b.WriteString("<table:table-row>")
+			for _, v := range row {
+				writeODSCell(&b, j == 0, v)
+			}
+			b.WriteString("</table:table-row>")
+		}
+		b.WriteString("</table:table>")
+	}
+
+	b.WriteString("</office:spreadsheet></office:body></office:document-content>")
+	return b.String()
+}
+
+func writeODSCell(b *strings.Builder, header bool, v interface{}) {
+	var style string
+	if header {
+		style = ` table:style-name="header"`
+	}
+
+	switch v := v.(type) {
+	case int, int64, float64:
+		fmt.Fprintf(b, `<table:table-cell%s office:value-type="float" office:value="%v"><text:p>%v</text:p></table:table-cell>`, style, v, v)
+	case time.Time:
+		if v.IsZero() {
+			b.WriteString("<table:table-cell/>")
+			return
+		}
+		value := v.UTC().Format("2006-01-02T15:04:05")
+		fmt.Fprintf(
+			b, `<table:table-cell table:style-name="date" office:value-type="date" office:date-value="%s"><text:p>%s</text:p></table:table-cell>`,
+			value, v.UTC().Format("2006-01-02 15:04:05"),
+		)
+	default:
+		fmt.Fprintf(b, `<table:table-cell%s office:value-type="string"><text:p>%s</text:p></table:table-cell>`, style, xmlEscape(fmt.Sprint(v)))
+	}
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteODS(t *testing.T) {
+	modified := time.Date(2020, time.March, 1, 12, 0, 0, 0, time.UTC)
+	sheets := []Sheet{
+		{
+			Name: "Datasets",
+			Rows: [][]interface{}{
+				{"ID", "Title", "Size", "Modified"},
+				{"aire", "Calidad <del> aire & agua", 1024, modified},
+			},
+		},
+		{Name: "Distributions"},
+	}
+
+	var b bytes.Buffer
+	if err := WriteODS(&b, sheets); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	z, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if first := z.File[0]; first.Name != "mimetype" || first.Method != zip.Store {
+		t.Errorf("expected uncompressed mimetype as first file, got: %s", first.Name)
+	}
+
+	files := readZip(t, b.Bytes())
+	if files["mimetype"] != odsMimetype {
+		t.Errorf("wrong mimetype: %s", files["mimetype"])
+	}
+
+	for _, name := range []string{"META-INF/manifest.xml", "content.xml"} {
+		if err := xml.Unmarshal([]byte(files[name]), new(interface{})); err != nil {
+			t.Errorf("invalid XML in %s: %s", name, err)
+		}
+	}
+
+	content := files["content.xml"]
+	for _, expected := range []string{
+		`<table:table table:name="Datasets">`,
+		`<table:table table:name="Distributions">`,
+		`<table:table-cell table:style-name="header" office:value-type="string"><text:p>ID</text:p></table:table-cell>`,
+		`<text:p>Calidad &lt;del&gt; aire &amp; agua</text:p>`,
+		`office:value-type="float" office:value="1024"`,
+		`office:value-type="date" office:date-value="2020-03-01T12:00:00"`,
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected %s in content:\n%s", expected, content)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"time"
+)
+
+// Outcome summarizes a run of a command, so other programs, such as CI
+// jobs, can check its results without parsing the logs.
+type Outcome struct {
+	// Command that was run.
+	Command string `json:"command"`
+	// Started is the time the command started.
+	Started time.Time `json:"started"`
+	// Finished is the time the command finished.
+	Finished time.Time `json:"finished"`
+	// Success reports whether the command finished without errors.
+	Success bool `json:"success"`
+	// Error that made the command fail.
+	Error string `json:"error,omitempty"`
+	// Found is the number of datasets found.
+	Found int `json:"found"`
+	// Downloaded is the number of datasets downloaded.
+	Downloaded int `json:"downloaded"`
+	// Skipped is the number of datasets not downloaded because the local
+	// copy was up to date.
+	Skipped int `json:"skipped"`
+	// Failed is the number of datasets that could not be downloaded, of
+	// datasets whose files are missing or changed when verifying them, or
+	// of posts that failed when notifying them.
+	Failed int `json:"failed"`
+	// Warnings is the number of datasets that could not be decoded.
+	Warnings int `json:"warnings"`
+	// Problems is the number of problems found in the catalog, or of files
+	// not recorded in the manifest when verifying downloads.
+	Problems int `json:"problems"`
+	// Posted is the number of datasets posted by notify.
+	Posted int `json:"posted"`
+	// Files downloaded.
+	Files []string `json:"files,omitempty"`
+}
+
+// Finish records the end of the command and the error that made it fail,
+// if any.
+func (o *Outcome) Finish(err error) {
+	o.Finished = time.Now()
+	o.Success = err == nil
+	if err != nil {
+		o.Error = err.Error()
+	}
+}
+
+// WriteFile writes the outcome as JSON to the file at path.
+func (o *Outcome) WriteFile(path string) error {
+	data, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOutcomeWriteFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	o := Outcome{Command: "download", Found: 3, Downloaded: 2, Failed: 1}
+	o.Finish(errors.New("boom"))
+
+	path := filepath.Join(dir, "report.json")
+	if err := o.WriteFile(path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if result["success"] != false || result["error"] != "boom" || result["downloaded"] != 2.0 {
+		t.Errorf("wrong outcome: %s", data)
+	}
+}
@@ -0,0 +1,197 @@
+package app
+
+import (
+	"encoding/csv"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const dniLetters = "TRWAGMYFPDXBNJZSQVHLCKE"
+
+// piiValidators perform additional validations on values matching the
+// built-in patterns to avoid reporting false positives.
+var piiValidators = map[string]func(string) bool{
+	"dni":  validDNI,
+	"nie":  validNIE,
+	"iban": validIBAN,
+}
+
+func validDNI(s string) bool {
+	n, err := strconv.Atoi(s[:8])
+	if err != nil {
+		return false
+	}
+	return strings.ToUpper(s[8:]) == string(dniLetters[n%23])
+}
+
+func validNIE(s string) bool {
+	prefix := strings.Index("XYZ", strings.ToUpper(s[:1]))
+	return validDNI(strconv.Itoa(prefix) + s[1:])
+}
+
+func validIBAN(s string) bool {
+	s = strings.ToUpper(strings.Replace(s, " ", "", -1))
+	s = s[4:] + s[:4]
+
+	var digits strings.Builder
+	for _, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			digits.WriteString(strconv.Itoa(int(r-'A') + 10))
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	if !ok {
+		return false
+	}
+	return new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+// piiKind returns the kind of personal data the value looks like, if any.
+func piiKind(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", false
+	}
+
+	var kinds = make([]string, 0, len(piiPatterns))
+	for k := range piiPatterns {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+
+	for _, k := range kinds {
+		if isPII(k, value) {
+			return k, true
+		}
+	}
+
+	return "", false
+}
+
+// isPII reports whether the value matches the built-in pattern of the given
+// kind of personal data and passes its additional validation, if any.
+func isPII(kind, value string) bool {
+	if !piiPatterns[kind].MatchString(value) {
+		return false
+	}
+
+	v, ok := piiValidators[kind]
+	return !ok || v(value)
+}
+
+type piiFinding struct {
+	file   string
+	column string
+	kind   string
+	count  int
+}
+
+// piiReport collects the personal data found in downloaded files.
+type piiReport struct {
+	mut      sync.Mutex
+	findings []piiFinding
+}
+
+// process returns a process function that scans downloaded CSV files
+// looking for personal data and adds the findings to the report.
+func (r *piiReport) process() processFunc {
+	return func(path string) error {
+		if filepath.Ext(path) != ".csv" {
+			return nil
+		}
+
+		var header []string
+		var counts = make(map[int]map[string]int)
+		err := readCSV(path, func(i int, record []string) error {
+			if i == 0 {
+				header = record
+				return nil
+			}
+
+			for j, v := range record {
+				kind, ok := piiKind(v)
+				if !ok {
+					continue
+				}
+
+				if counts[j] == nil {
+					counts[j] = make(map[string]int)
+				}
+				counts[j][kind]++
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		var findings []piiFinding
+		for col, kinds := range counts {
+			var name = strconv.Itoa(col + 1)
+			if col < len(header) {
+				name = header[col]
+			}
+
+			for kind, n := range kinds {
+				findings = append(findings, piiFinding{filepath.Base(path), name, kind, n})
+				logrus.Warnf("found %d possible %s value(s) in column %q of %s", n, kind, name, path)
+			}
+		}
+
+		r.mut.Lock()
+		r.findings = append(r.findings, findings...)
+		r.mut.Unlock()
+		return nil
+	}
+}
+
+// write writes the report as a CSV file to the given path.
+func (r *piiReport) write(path string) error {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	sort.Slice(r.findings, func(i, j int) bool {
+		a, b := r.findings[i], r.findings[j]
+		if a.file != b.file {
+			return a.file < b.file
+		}
+		if a.column != b.column {
+			return a.column < b.column
+		}
+		return a.kind < b.kind
+	})
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	if err := w.Write([]string{"file", "column", "kind", "matches"}); err != nil {
+		return err
+	}
+
+	for _, f := range r.findings {
+		if err := w.Write([]string{f.file, f.column, f.kind, strconv.Itoa(f.count)}); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPIIKind(t *testing.T) {
+	cases := []struct {
+		value string
+		kind  string
+		ok    bool
+	}{
+		{"12345678Z", "dni", true},
+		{"12345678a", "", false},
+		{"X1234567L", "nie", true},
+		{"X1234567A", "", false},
+		{" ana@example.com ", "email", true},
+		{"+34 612 345 678", "phone", true},
+		{"512345678", "", false},
+		{"ES91 2100 0418 4502 0005 1332", "iban", true},
+		{"ES00 2100 0418 4502 0005 1332", "", false},
+		{"Madrid", "", false},
+		{"", "", false},
+	}
+
+	for _, c := range cases {
+		kind, ok := piiKind(c.value)
+		if kind != c.kind || ok != c.ok {
+			t.Errorf("wrong kind of %q, expected: %q %v, got: %q %v", c.value, c.kind, c.ok, kind, ok)
+		}
+	}
+}
+
+func TestPIIReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos-pii")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"b.csv":  "nombre;dni;contacto\nAna;12345678Z;ana@example.com\nLuis;12345678A;612345678\nEva;X1234567L;\n",
+		"a.csv":  "municipio,poblacion\nMadrid,3000000\n",
+		"c.csv":  "id\n1\n2\n",
+		"d.json": `{"email":"ana@example.com"}`,
+	}
+
+	var r piiReport
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if err := r.process()(path); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	// Values in columns beyond the header are reported by position.
+	path := filepath.Join(dir, "e.csv")
+	if err := ioutil.WriteFile(path, []byte("id\n1,ana@example.com\n"), 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := r.process()(path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	report := filepath.Join(dir, "report.csv")
+	if err := r.write(report); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, err := ioutil.ReadFile(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := "file,column,kind,matches\n" +
+		"b.csv,contacto,email,1\n" +
+		"b.csv,contacto,phone,1\n" +
+		"b.csv,dni,dni,1\n" +
+		"b.csv,dni,nie,1\n" +
+		"e.csv,2,email,1\n"
+	if string(data) != expected {
+		t.Errorf("wrong report, expected:\n%s\ngot:\n%s", expected, data)
+	}
+}
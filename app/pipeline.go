@@ -0,0 +1,39 @@
+package app
+
+import "fmt"
+
+// processFunc processes a downloaded file after it has been written to disk.
+type processFunc func(path string) error
+
+// convertFunc converts a downloaded file into another format and returns
+// the path of the converted file. If the file is not converted, the same
+// path is returned.
+type convertFunc func(path string) (string, error)
+
+// pipeline of steps run on every downloaded file. Conversions are run
+// before any other processing, so processes that only work with CSV files
+// also apply to the files converted to CSV.
+type pipeline struct {
+	convert []convertFunc
+	process []processFunc
+}
+
+// run runs the pipeline on the file at path and returns the path of the
+// resulting file.
+func (p *pipeline) run(path string) (string, error) {
+	for _, c := range p.convert {
+		out, err := c(path)
+		if err != nil {
+			return "", fmt.Errorf("error converting %s: %s", path, err)
+		}
+		path = out
+	}
+
+	for _, fn := range p.process {
+		if err := fn(path); err != nil {
+			return "", fmt.Errorf("error processing %s: %s", path, err)
+		}
+	}
+
+	return path, nil
+}
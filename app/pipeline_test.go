@@ -0,0 +1,39 @@
+package app
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	var processed []string
+	p := pipeline{
+		convert: []convertFunc{
+			func(path string) (string, error) { return path + ".csv", nil },
+		},
+		process: []processFunc{
+			func(path string) error {
+				processed = append(processed, path)
+				return nil
+			},
+		},
+	}
+
+	path, err := p.run("data.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if path != "data.json.csv" || len(processed) != 1 || processed[0] != path {
+		t.Errorf("wrong result, path: %s, processed: %v", path, processed)
+	}
+
+	p.convert = append(p.convert, func(path string) (string, error) {
+		return "", errors.New("invalid file")
+	})
+
+	_, err = p.run("data.json")
+	if err == nil || err.Error() != "error converting data.json.csv: invalid file" {
+		t.Errorf("expected error with the path of the file, got: %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"github.com/erizocosmico/datos"
+)
+
+// PreviewOptions controls how much of a dataset is previewed.
+type PreviewOptions struct {
+	// Rows is the maximum number of rows previewed, without counting the
+	// header. By default, 10.
+	Rows int
+	// MaxBytes is the maximum number of bytes downloaded. By default,
+	// 64KB.
+	MaxBytes int64
+}
+
+// Preview contains the first rows of a tabular distribution of a dataset.
+type Preview struct {
+	// Title of the dataset.
+	Title string
+	// URL of the previewed distribution.
+	URL string
+	// Header of the table.
+	Header []string
+	// Rows of the table.
+	Rows [][]string
+}
+
+// maxPreviewCell is the maximum number of characters of a cell printed in a
+// preview table.
+const maxPreviewCell = 30
+
+// PreviewDataset downloads the first rows of the CSV distribution of the
+// dataset with the given ID, without downloading the whole file.
+func PreviewDataset(ctx context.Context, client *datos.Client, id string, opts PreviewOptions) (*Preview, error) {
+	ds, err := client.Dataset(ctx, id, datos.Params{})
+	if err != nil {
+		return nil, err
+	}
+
+	var url string
+	for _, d := range ds.Distribution {
+		if d.Format.Value == formats["csv"] && d.AccessURL != "" {
+			url = d.AccessURL
+			break
+		}
+	}
+
+	if url == "" {
+		return nil, fmt.Errorf("no tabular distribution found for dataset: %s", id)
+	}
+
+	p, err := previewURL(ctx, url, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Title = datasetTitle(ds)
+	return p, nil
+}
+
+// previewURL downloads the beginning of the CSV file at url and parses its
+// first rows.
+func previewURL(ctx context.Context, url string, opts PreviewOptions) (*Preview, error) {
+	if opts.Rows <= 0 {
+		opts.Rows = 10
+	}
+
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = 64 << 10
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", opts.MaxBytes-1))
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("unable to download %s: status %d", url, resp.StatusCode)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, opts.MaxBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	// Unless the whole file was downloaded, the last line is probably
+	// incomplete.
+	truncated := int64(len(data)) >= opts.MaxBytes
+	if truncated {
+		if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+			data = data[:i+1]
+		}
+	}
+
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	line := string(data)
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+
+	p := &Preview{URL: url}
+	r := newCSVReader(bytes.NewReader(data), guessDelimiter(line))
+	for len(p.Rows) < opts.Rows {
+		record, err := r.Read()
+		if err == io.EOF {
+			// A quoted field with line breaks may have been cut, so the
+			// last row can't be trusted.
+			if truncated && len(p.Rows) > 0 {
+				p.Rows = p.Rows[:len(p.Rows)-1]
+			}
+			break
+		}
+
+		if err != nil && truncated && p.Header != nil {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if p.Header == nil {
+			p.Header = record
+		} else {
+			p.Rows = append(p.Rows, record)
+		}
+	}
+
+	return p, nil
+}
+
+// WriteTable writes the preview as a table aligned with spaces. Long
+// cells are truncated.
+func (p *Preview) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, record := range append([][]string{p.Header}, p.Rows...) {
+		cells := make([]string, len(record))
+		for i, c := range record {
+			cells[i] = previewCell(c)
+		}
+
+		if _, err := fmt.Fprintln(tw, strings.Join(cells, "\t")); err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
+
+func previewCell(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) <= maxPreviewCell {
+		return s
+	}
+
+	return string([]rune(s)[:maxPreviewCell-1]) + "…"
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPreviewURL(t *testing.T) {
+	var rng string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rng = r.Header.Get("Range")
+		w.Write([]byte("municipio;poblacion\nHuesca;53132\nTeruel;35691\nZaragoza;674997\nCalatayud;20"))
+	}))
+	defer srv.Close()
+
+	p, err := previewURL(context.Background(), srv.URL, PreviewOptions{Rows: 2, MaxBytes: 64})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if rng != "bytes=0-63" {
+		t.Errorf("wrong range, expected: bytes=0-63, got: %s", rng)
+	}
+
+	if strings.Join(p.Header, ",") != "municipio,poblacion" {
+		t.Errorf("wrong header: %v", p.Header)
+	}
+
+	if len(p.Rows) != 2 || p.Rows[1][0] != "Teruel" {
+		t.Errorf("wrong rows: %v", p.Rows)
+	}
+
+	var b strings.Builder
+	if err := p.WriteTable(&b); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := "municipio  poblacion\nHuesca     53132\nTeruel     35691\n"
+	if b.String() != expected {
+		t.Errorf("wrong table, expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
+
+func TestPreviewURLTruncated(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("a,b\n1,\"foo\n2,bar\n"))
+	}))
+	defer srv.Close()
+
+	p, err := previewURL(context.Background(), srv.URL, PreviewOptions{MaxBytes: 12})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(p.Rows) != 0 {
+		t.Errorf("expected incomplete rows to be dropped, got: %v", p.Rows)
+	}
+}
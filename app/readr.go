@@ -0,0 +1,244 @@
+package app
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// readrColumn describes a column of a CSV file written for R's readr.
+type readrColumn struct {
+	Name string `json:"name"`
+	// Source is the name of the column in the original file, if it had to
+	// be changed because it was empty or repeated.
+	Source string `json:"source,omitempty"`
+	// Type is the readr collector of the column: integer, double,
+	// logical, date, datetime or character.
+	Type    string `json:"type"`
+	Missing int    `json:"missing"`
+}
+
+// readrMetadata is the sidecar file of a CSV file written for R's readr,
+// with the arguments needed to read it with the right types.
+type readrMetadata struct {
+	File string `json:"file"`
+	// SourceEncoding is the encoding of the original file, UTF-8 or
+	// ISO-8859-1. The file written is always UTF-8.
+	SourceEncoding string        `json:"source_encoding"`
+	Encoding       string        `json:"encoding"`
+	Delim          string        `json:"delim"`
+	DecimalMark    string        `json:"decimal_mark"`
+	NA             []string      `json:"na"`
+	ColTypes       string        `json:"col_types"`
+	Rows           int           `json:"rows"`
+	Columns        []readrColumn `json:"columns"`
+}
+
+// readrTypes are the readr collectors of the inferred types of columns,
+// with their abbreviations in the compact col_types string.
+var readrTypes = map[arrowType][2]string{
+	arrowInt64:     {"integer", "i"},
+	arrowFloat64:   {"double", "d"},
+	arrowBool:      {"logical", "l"},
+	arrowDate:      {"date", "D"},
+	arrowTimestamp: {"datetime", "T"},
+	arrowString:    {"character", "c"},
+}
+
+// exportReadr returns a process function that writes every CSV file as a
+// CSV file for R's readr next to it, along with a JSON file documenting
+// the types of its columns and how to read it.
+//
+// The file written is UTF-8, comma separated, with dots as decimal mark
+// and ISO 8601 dates, which are the defaults of readr::read_csv. Values
+// in latin1 are converted to UTF-8. Empty values are the only missing
+// values, so texts like NA are kept. Integers that don't fit in the
+// 32-bit integers of R are doubles.
+func exportReadr() processFunc {
+	return func(path string) error {
+		if filepath.Ext(path) != ".csv" {
+			return nil
+		}
+
+		encoding := "UTF-8"
+		var header []string
+		var rows [][]string
+		err := readCSV(path, func(i int, record []string) error {
+			row := make([]string, len(record))
+			for j, v := range record {
+				if !utf8.ValidString(v) {
+					encoding = "ISO-8859-1"
+					v = dbfString([]byte(v))
+				}
+
+				if i == 0 {
+					row[j] = v
+				} else {
+					row[j] = normalizeValue(v)
+				}
+			}
+
+			if i == 0 {
+				if len(row) > 0 {
+					row[0] = strings.TrimPrefix(row[0], "\ufeff")
+				}
+				header = row
+				return nil
+			}
+
+			rows = append(rows, row)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		base := strings.TrimSuffix(path, filepath.Ext(path)) + ".readr"
+		meta := readrColumns(header, rows)
+		meta.File = filepath.Base(base + ".csv")
+		meta.SourceEncoding = encoding
+
+		names := make([]string, len(meta.Columns))
+		for i, c := range meta.Columns {
+			names[i] = c.Name
+		}
+
+		if err := writeFileAtomic(base+".csv", func(f *os.File) error {
+			w := csv.NewWriter(f)
+			if err := w.Write(names); err != nil {
+				return err
+			}
+
+			for _, row := range rows {
+				if len(row) < len(names) {
+					row = append(row, make([]string, len(names)-len(row))...)
+				}
+				if err := w.Write(row[:len(names)]); err != nil {
+					return err
+				}
+			}
+
+			w.Flush()
+			return w.Error()
+		}); err != nil {
+			return err
+		}
+
+		return writeFileAtomic(base+".json", func(f *os.File) error {
+			e := json.NewEncoder(f)
+			e.SetIndent("", "  ")
+			return e.Encode(meta)
+		})
+	}
+}
+
+// readrColumns returns the metadata of the columns of a CSV file. Empty
+// and repeated names are replaced, as readr would do, so the names in the
+// metadata are the ones of the data frame. Rows longer than the header add
+// unnamed columns.
+func readrColumns(header []string, rows [][]string) readrMetadata {
+	n := len(header)
+	for _, row := range rows {
+		if len(row) > n {
+			n = len(row)
+		}
+	}
+
+	meta := readrMetadata{
+		Encoding:    "UTF-8",
+		Delim:       ",",
+		DecimalMark: ".",
+		NA:          []string{""},
+		Rows:        len(rows),
+	}
+
+	seen := make(map[string]bool)
+	var colTypes strings.Builder
+	for i := 0; i < n; i++ {
+		var source string
+		if i < len(header) {
+			source = header[i]
+		}
+
+		base := strings.TrimSpace(source)
+		if base == "" {
+			base = fmt.Sprintf("X%d", i+1)
+		}
+		name := base
+		for k := 2; seen[name]; k++ {
+			name = fmt.Sprintf("%s_%d", base, k)
+		}
+		seen[name] = true
+
+		values := make([]string, len(rows))
+		var missing int
+		for j, row := range rows {
+			if i < len(row) {
+				values[j] = row[i]
+			}
+			if values[j] == "" {
+				missing++
+			}
+		}
+
+		typ := inferArrowType(values)
+		if typ == arrowInt64 && !fitsInt32(values) {
+			typ = arrowFloat64
+		}
+
+		col := readrColumn{Name: name, Type: readrTypes[typ][0], Missing: missing}
+		if name != source {
+			col.Source = source
+		}
+		meta.Columns = append(meta.Columns, col)
+		colTypes.WriteString(readrTypes[typ][1])
+	}
+
+	meta.ColTypes = colTypes.String()
+	return meta
+}
+
+// fitsInt32 reports whether all the integers fit in the integers of R,
+// which are 32 bits wide with the smallest value reserved for NA.
+func fitsInt32(values []string) bool {
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n > math.MaxInt32 || n <= math.MinInt32 {
+			return false
+		}
+	}
+	return true
+}
+
+// writeFileAtomic writes a file with fn, replacing it only if fn doesn't
+// fail.
+func writeFileAtomic(path string, fn func(*os.File) error) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(f); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
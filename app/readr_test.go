@@ -0,0 +1,77 @@
+package app
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExportReadr(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos-readr")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := "\ufeffid;precio;fecha;municipio;;municipio;poblaci\xf3n\n" +
+		"1;1,5;01/03/2020;Le\xf3n;x;NA;3000000000\n" +
+		"2;;31/12/2019;Zaragoza;;;12\n"
+	path := filepath.Join(dir, "data.csv")
+	if err := ioutil.WriteFile(path, []byte(input), 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := exportReadr()(path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "data.readr.csv"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := "id,precio,fecha,municipio,X5,municipio_2,población\n" +
+		"1,1.5,2020-03-01,León,x,NA,3000000000\n" +
+		"2,,2019-12-31,Zaragoza,,,12\n"
+	if string(data) != expected {
+		t.Errorf("wrong CSV file, expected:\n%s\ngot:\n%s", expected, data)
+	}
+
+	data, err = ioutil.ReadFile(filepath.Join(dir, "data.readr.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var meta readrMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if meta.File != "data.readr.csv" || meta.SourceEncoding != "ISO-8859-1" || meta.Encoding != "UTF-8" || meta.Rows != 2 {
+		t.Errorf("wrong metadata: %+v", meta)
+	}
+
+	if meta.ColTypes != "idDcccd" {
+		t.Errorf("wrong column types, expected: idDcccd, got: %s", meta.ColTypes)
+	}
+
+	expectedColumns := []readrColumn{
+		{Name: "id", Type: "integer"},
+		{Name: "precio", Type: "double", Missing: 1},
+		{Name: "fecha", Type: "date"},
+		{Name: "municipio", Type: "character"},
+		{Name: "X5", Type: "character", Missing: 1},
+		{Name: "municipio_2", Source: "municipio", Type: "character", Missing: 1},
+		{Name: "población", Type: "double"},
+	}
+	if !reflect.DeepEqual(meta.Columns, expectedColumns) {
+		t.Errorf("wrong columns, expected: %+v, got: %+v", expectedColumns, meta.Columns)
+	}
+
+	if err := exportReadr()(filepath.Join(dir, "data.json")); err != nil {
+		t.Errorf("unexpected error with a file that is not CSV: %s", err)
+	}
+}
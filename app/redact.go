@@ -0,0 +1,141 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// piiPatterns are the built-in patterns that can be used to detect
+// personal data by name.
+var piiPatterns = map[string]*regexp.Regexp{
+	"dni":   regexp.MustCompile(`^\d{8}[A-Za-z]$`),
+	"nie":   regexp.MustCompile(`^[XYZxyz]\d{7}[A-Za-z]$`),
+	"phone": regexp.MustCompile(`^(\+34|0034)?[ -]?[6789]\d{2}[ -]?\d{3}[ -]?\d{3}$`),
+	"email": regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`),
+	"iban":  regexp.MustCompile(`^[A-Za-z]{2}\d{2}( ?[0-9A-Za-z]{4}){4,7}( ?[0-9A-Za-z]{1,4})?$`),
+}
+
+// minRedactShare is the minimum share of the non empty values of a column
+// that need to match a pattern for the column to be dropped, so columns
+// where a few values happen to look like personal data are kept.
+const minRedactShare = 0.5
+
+// redactPattern matches values with one of the built-in patterns, applying
+// its additional validation, or with a regular expression.
+type redactPattern struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+func (p redactPattern) match(s string) bool {
+	if p.kind != "" {
+		return isPII(p.kind, s)
+	}
+	return p.re.MatchString(s)
+}
+
+// parsePatterns parses a comma separated list of built-in pattern names or
+// regular expressions.
+func parsePatterns(list string) ([]redactPattern, error) {
+	var result []redactPattern
+	for _, p := range strings.Split(list, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		if re, ok := piiPatterns[strings.ToLower(p)]; ok {
+			result = append(result, redactPattern{strings.ToLower(p), re})
+			continue
+		}
+
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %s", p, err)
+		}
+		result = append(result, redactPattern{re: re})
+	}
+	return result, nil
+}
+
+func matchesAny(patterns []redactPattern, s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return false
+	}
+
+	for _, p := range patterns {
+		if p.match(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact returns a process function that drops from downloaded CSV files
+// all columns whose header matches one of the patterns, or whose values
+// match them in at least minRedactShare of the rows with a value.
+func redact(patterns []redactPattern) processFunc {
+	return func(path string) error {
+		if filepath.Ext(path) != ".csv" {
+			return nil
+		}
+
+		drop := make(map[int]bool)
+		values := make(map[int]int)
+		matches := make(map[int]int)
+		err := readCSV(path, func(i int, record []string) error {
+			for j, v := range record {
+				if i == 0 {
+					if matchesAny(patterns, v) {
+						drop[j] = true
+					}
+					continue
+				}
+
+				if strings.TrimSpace(v) == "" {
+					continue
+				}
+
+				values[j]++
+				if matchesAny(patterns, v) {
+					matches[j]++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for j, n := range matches {
+			if float64(n) >= minRedactShare*float64(values[j]) {
+				drop[j] = true
+			}
+		}
+
+		if len(drop) == 0 {
+			return nil
+		}
+
+		delim, err := detectDelimiter(path)
+		if err != nil {
+			return err
+		}
+
+		logrus.Infof("redacting %d column(s) from %s", len(drop), path)
+		return rewriteCSV(path, delim, func(_ int, record []string) []string {
+			var result = make([]string, 0, len(record))
+			for i, v := range record {
+				if !drop[i] {
+					result = append(result, v)
+				}
+			}
+			return result
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParsePatterns(t *testing.T) {
+	patterns, err := parsePatterns("dni, EMAIL,^telefono$,")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(patterns) != 3 {
+		t.Fatalf("wrong number of patterns, expected: 3, got: %d", len(patterns))
+	}
+
+	if patterns[0].kind != "dni" || patterns[1].kind != "email" {
+		t.Errorf("expected built-in patterns to be used by name")
+	}
+
+	if !patterns[2].match("telefono") {
+		t.Errorf("expected regular expression to match")
+	}
+
+	if _, err := parsePatterns("dni,[a-"); err == nil {
+		t.Errorf("expected error parsing invalid pattern")
+	}
+}
+
+func TestRedact(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos-redact")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	patterns, err := parsePatterns("email,phone,dni,^telefono$")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			"header and values",
+			"nombre;telefono;contacto;edad\nAna;600000000;ana@example.com;30\nLuis;;;41\n",
+			"nombre;edad\nAna;30\nLuis;41\n",
+		},
+		{
+			"short rows",
+			"nombre,contacto,edad\nAna,,30\nLuis\nEva,eva@example.com,25\n",
+			"nombre,edad\nAna,30\nLuis\nEva,25\n",
+		},
+		{
+			"few matches",
+			"codigo,importe\n600000000,12\n1,700000000\n2,13\n3,14\n",
+			"codigo,importe\n600000000,12\n1,700000000\n2,13\n3,14\n",
+		},
+		{
+			"invalid checksums",
+			"nombre,documento\nAna,12345678A\nLuis,00000000A\n",
+			"nombre,documento\nAna,12345678A\nLuis,00000000A\n",
+		},
+		{
+			"valid checksums",
+			"nombre,documento\nAna,12345678Z\nLuis,00000000T\n",
+			"nombre\nAna\nLuis\n",
+		},
+		{
+			"no matches",
+			"nombre,edad\nAna,30\n",
+			"nombre,edad\nAna,30\n",
+		},
+	}
+
+	for _, c := range cases {
+		path := filepath.Join(dir, "data.csv")
+		if err := ioutil.WriteFile(path, []byte(c.input), 0644); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if err := redact(patterns)(path); err != nil {
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+			continue
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if string(data) != c.expected {
+			t.Errorf("%s: wrong result, expected:\n%s\ngot:\n%s", c.name, c.expected, data)
+		}
+	}
+
+	path := filepath.Join(dir, "data.json")
+	const input = `{"contacto":"ana@example.com"}`
+	if err := ioutil.WriteFile(path, []byte(input), 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := redact(patterns)(path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if data, _ := ioutil.ReadFile(path); string(data) != input {
+		t.Errorf("expected non CSV file not to be changed, got: %s", data)
+	}
+}
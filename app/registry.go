@@ -0,0 +1,86 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// NotifierOpener returns a notifier posting to the given target with the
+// given token. The meaning of the target depends on the backend, e.g. the
+// URL of the server for Mastodon or the chat for Telegram.
+type NotifierOpener func(target, token string) (Notifier, error)
+
+var notifiers = struct {
+	sync.RWMutex
+	m map[string]NotifierOpener
+}{m: make(map[string]NotifierOpener)}
+
+// RegisterNotifier makes a notifier backend available by the given name
+// to OpenNotifier, so other packages can add backends from their init
+// functions. It panics if the name is already registered or open is nil.
+func RegisterNotifier(name string, open NotifierOpener) {
+	notifiers.Lock()
+	defer notifiers.Unlock()
+	if open == nil {
+		panic("app: RegisterNotifier opener is nil")
+	}
+	if _, ok := notifiers.m[name]; ok {
+		panic("app: RegisterNotifier called twice for " + name)
+	}
+	notifiers.m[name] = open
+}
+
+// OpenNotifier returns a notifier of the backend registered with the given
+// name. The mastodon, x, matrix and telegram backends are always
+// registered. The target of Matrix is the URL of the homeserver followed
+// by the room ID, e.g. https://matrix.org/!abcdef:matrix.org, and X has
+// no target.
+func OpenNotifier(name, target, token string) (Notifier, error) {
+	notifiers.RLock()
+	open, ok := notifiers.m[name]
+	notifiers.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown notifier %q", name)
+	}
+	return open(target, token)
+}
+
+// NotifierBackends returns the sorted names of the registered notifier
+// backends.
+func NotifierBackends() []string {
+	notifiers.RLock()
+	defer notifiers.RUnlock()
+	var names []string
+	for name := range notifiers.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterNotifier("mastodon", func(target, token string) (Notifier, error) {
+		if target == "" {
+			return nil, fmt.Errorf("the URL of the Mastodon server is required")
+		}
+		return &Mastodon{Server: target, Token: token}, nil
+	})
+	RegisterNotifier("x", func(target, token string) (Notifier, error) {
+		return &X{Token: token}, nil
+	})
+	RegisterNotifier("matrix", func(target, token string) (Notifier, error) {
+		i := strings.Index(target, "/!")
+		if i < 0 {
+			return nil, fmt.Errorf("expecting homeserver URL followed by the room ID, e.g. https://matrix.org/!abcdef:matrix.org, got %q", target)
+		}
+		return &Matrix{Homeserver: target[:i], Room: target[i+1:], Token: token}, nil
+	})
+	RegisterNotifier("telegram", func(target, token string) (Notifier, error) {
+		if target == "" {
+			return nil, fmt.Errorf("the Telegram chat is required")
+		}
+		return &Telegram{Chat: target, Token: token}, nil
+	})
+}
@@ -0,0 +1,45 @@
+package app
+
+import "testing"
+
+func TestOpenNotifier(t *testing.T) {
+	n, err := OpenNotifier("matrix", "https://matrix.org/!abc:matrix.org", "token")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	m, ok := n.(*Matrix)
+	if !ok || m.Homeserver != "https://matrix.org" || m.Room != "!abc:matrix.org" || m.Token != "token" {
+		t.Errorf("wrong notifier: %+v", n)
+	}
+
+	for _, c := range []struct{ name, target string }{
+		{"matrix", "https://matrix.org"},
+		{"mastodon", ""},
+		{"telegram", ""},
+		{"irc", "#datos"},
+	} {
+		if _, err := OpenNotifier(c.name, c.target, ""); err == nil {
+			t.Errorf("expected error opening %s with target %q", c.name, c.target)
+		}
+	}
+
+	RegisterNotifier("test", func(target, token string) (Notifier, error) {
+		return &Telegram{Chat: target, Token: token}, nil
+	})
+
+	if n, err := OpenNotifier("test", "chat", "t"); err != nil || n.Name() != "telegram" {
+		t.Errorf("wrong registered notifier: %v, %v", n, err)
+	}
+
+	names := NotifierBackends()
+	expected := []string{"mastodon", "matrix", "telegram", "test", "x"}
+	if len(names) != len(expected) {
+		t.Fatalf("wrong notifiers, expected: %v, got: %v", expected, names)
+	}
+	for i := range names {
+		if names[i] != expected[i] {
+			t.Errorf("wrong notifiers, expected: %v, got: %v", expected, names)
+		}
+	}
+}
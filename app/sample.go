@@ -0,0 +1,175 @@
+package app
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/erizocosmico/datos"
+	"github.com/sirupsen/logrus"
+)
+
+// sampleRows returns a process function that replaces downloaded CSV files
+// with a random sample of n of their rows. The header and the original
+// order of the rows are preserved. Every file has its own random source,
+// derived from the seed and the ID of its dataset, so the sample does not
+// depend on the order in which files are processed.
+func sampleRows(n int, seed int64) processFunc {
+	return func(path string) error {
+		if filepath.Ext(path) != ".csv" {
+			return nil
+		}
+
+		// Files are named after the ID of their dataset.
+		id := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		rnd := fileRand(seed, id)
+
+		// Reservoir sampling of the indexes of the rows to keep, so the
+		// file doesn't need to be kept in memory.
+		var keep []int
+		var total int
+		err := readCSV(path, func(i int, _ []string) error {
+			if i == 0 {
+				return nil
+			}
+
+			total++
+			if len(keep) < n {
+				keep = append(keep, i)
+			} else if j := rnd.Intn(total); j < n {
+				keep[j] = i
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if total <= n {
+			return nil
+		}
+
+		sort.Ints(keep)
+		delim, err := detectDelimiter(path)
+		if err != nil {
+			return err
+		}
+
+		logrus.Infof("sampling %d of %d rows from %s", n, total, path)
+		return rewriteCSV(path, delim, func(i int, record []string) []string {
+			if i == 0 {
+				return record
+			}
+
+			if len(keep) > 0 && keep[0] == i {
+				keep = keep[1:]
+				return record
+			}
+			return nil
+		})
+	}
+}
+
+// fileRand returns the random source for the file of the dataset with the
+// given ID.
+func fileRand(seed int64, id string) *rand.Rand {
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	return rand.New(rand.NewSource(seed ^ int64(h.Sum64())))
+}
+
+// sampleDatasets returns a random sample of the datasets found, keeping the
+// proportion of datasets of every stratum. All the datasets matching the
+// filters need to be found first, so this takes as long as finding them.
+func (a *App) sampleDatasets(ctx context.Context) ([]Dataset, error) {
+	strata := make(map[string][]Dataset)
+	format := formats[strings.ToLower(a.config.Format)]
+	err := a.each(ctx, func(ds datos.Dataset) bool {
+		d, ok := a.selectDistribution(ds, format)
+		if ok {
+			key := stratum(ds, a.config.StratifyBy)
+			strata[key] = append(strata[key], d)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Strata are sorted so the sample only depends on the random source.
+	keys := make([]string, 0, len(strata))
+	for k := range strata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	groups := make([][]Dataset, len(keys))
+	for i, k := range keys {
+		groups[i] = strata[k]
+	}
+
+	return stratifiedSample(groups, a.config.SampleDatasets, a.rnd), nil
+}
+
+func stratum(ds datos.Dataset, by string) string {
+	switch by {
+	case "theme":
+		if len(ds.Theme) > 0 {
+			return lastSegment(ds.Theme[0])
+		}
+	case "publisher":
+		return lastSegment(ds.Publisher)
+	}
+	return ""
+}
+
+// stratifiedSample returns a random sample of n of the datasets of all the
+// groups, taking from every group a number of datasets proportional to its
+// size. Rounding is done with the largest remainder method.
+func stratifiedSample(groups [][]Dataset, n int, rnd *rand.Rand) []Dataset {
+	var total int
+	for _, g := range groups {
+		total += len(g)
+	}
+
+	if total <= n {
+		var result []Dataset
+		for _, g := range groups {
+			result = append(result, g...)
+		}
+		return result
+	}
+
+	quotas := make([]int, len(groups))
+	remainders := make([]int, len(groups))
+	order := make([]int, len(groups))
+	left := n
+	for i, g := range groups {
+		quotas[i] = n * len(g) / total
+		remainders[i] = n * len(g) % total
+		order[i] = i
+		left -= quotas[i]
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return remainders[order[i]] > remainders[order[j]]
+	})
+
+	for _, i := range order[:left] {
+		quotas[i]++
+	}
+
+	var result []Dataset
+	for i, g := range groups {
+		g = append([]Dataset(nil), g...)
+		rnd.Shuffle(len(g), func(i, j int) {
+			g[i], g[j] = g[j], g[i]
+		})
+		result = append(result, g[:quotas[i]]...)
+	}
+
+	return result
+}
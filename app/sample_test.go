@@ -0,0 +1,151 @@
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestStratifiedSample(t *testing.T) {
+	groups := make([][]Dataset, 3)
+	for i, n := range []int{60, 30, 10} {
+		for j := 0; j < n; j++ {
+			groups[i] = append(groups[i], Dataset{ID: fmt.Sprintf("%d-%d", i, j)})
+		}
+	}
+
+	sample := stratifiedSample(groups, 15, rand.New(rand.NewSource(1)))
+	if len(sample) != 15 {
+		t.Fatalf("wrong sample size, expected: 15, got: %d", len(sample))
+	}
+
+	counts := make(map[byte]int)
+	seen := make(map[string]bool)
+	for _, d := range sample {
+		if seen[d.ID] {
+			t.Errorf("dataset %s sampled twice", d.ID)
+		}
+		seen[d.ID] = true
+		counts[d.ID[0]]++
+	}
+
+	// 60%, 30% and 10% of 15 are 9, 4.5 and 1.5, so the remaining dataset
+	// goes to the first stratum with the largest remainder.
+	if counts['0'] != 9 || counts['1'] != 5 || counts['2'] != 1 {
+		t.Errorf("wrong counts per stratum: %v", counts)
+	}
+
+	if all := stratifiedSample(groups, 200, rand.New(rand.NewSource(1))); len(all) != 100 {
+		t.Errorf("expected all datasets when sample is larger, got: %d", len(all))
+	}
+}
+
+func TestSampleSeed(t *testing.T) {
+	var groups [][]Dataset
+	for i := 0; i < 5; i++ {
+		var g []Dataset
+		for j := 0; j < 20; j++ {
+			g = append(g, Dataset{ID: fmt.Sprintf("%d-%d", i, j)})
+		}
+		groups = append(groups, g)
+	}
+
+	sample := func() []Dataset {
+		a, err := New(nil, Config{SampleDatasets: 10, Seed: 42})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		return stratifiedSample(groups, 10, a.rnd)
+	}
+
+	first, second := sample(), sample()
+	for i := range first {
+		if first[i].ID != second[i].ID {
+			t.Fatalf("expected the same sample with the same seed, got: %v and %v", first, second)
+		}
+	}
+}
+
+func TestSampleRows(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos-sample")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := "id,valor\n"
+	for i := 1; i <= 100; i++ {
+		input += fmt.Sprintf("%d,%d\n", i, i*10)
+	}
+
+	sample := func(name string, seed int64) []string {
+		path := filepath.Join(dir, name+".csv")
+		if err := ioutil.WriteFile(path, []byte(input), 0644); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if err := sampleRows(10, seed)(path); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+
+	rows := sample("a", 42)
+	if len(rows) != 11 {
+		t.Fatalf("wrong number of rows, expected: 11, got: %d", len(rows))
+	}
+
+	if rows[0] != "id,valor" {
+		t.Errorf("expected header to be kept, got: %s", rows[0])
+	}
+
+	last := 0
+	for _, r := range rows[1:] {
+		fields := strings.Split(r, ",")
+		id, err := strconv.Atoi(fields[0])
+		if err != nil || fields[1] != strconv.Itoa(id*10) {
+			t.Fatalf("wrong row: %s", r)
+		}
+
+		if id <= last {
+			t.Errorf("expected rows in their original order, got: %v", rows)
+			break
+		}
+		last = id
+	}
+
+	// The sample only depends on the seed and the dataset, not on the
+	// files sampled before.
+	sample("b", 42)
+	if again := sample("a", 42); strings.Join(again, "\n") != strings.Join(rows, "\n") {
+		t.Errorf("expected the same sample with the same seed, got: %v and %v", rows, again)
+	}
+
+	if other := sample("a", 7); strings.Join(other, "\n") == strings.Join(rows, "\n") {
+		t.Errorf("expected a different sample with another seed")
+	}
+
+	path := filepath.Join(dir, "short.csv")
+	const short = "id\n1\n2\n"
+	if err := ioutil.WriteFile(path, []byte(short), 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := sampleRows(10, 42)(path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if data, _ := ioutil.ReadFile(path); string(data) != short {
+		t.Errorf("expected file with fewer rows than the sample not to be changed, got: %s", data)
+	}
+}
@@ -0,0 +1,44 @@
+package app
+
+// MetadataSchema is the JSON Schema of the metadata of a dataset written by
+// WriteMetadata in the json and ndjson formats. Fields are only added in
+// minor releases, and renamed or removed in major ones, so programs reading
+// the output can rely on it.
+const MetadataSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "$id": "https://github.com/erizocosmico/datos/schema/dataset-metadata.json",
+  "title": "Dataset metadata",
+  "description": "Metadata of a dataset of datos.gob.es, as written by datos search -output json or ndjson.",
+  "type": "object",
+  "properties": {
+    "id": {
+      "description": "Identifier of the dataset.",
+      "type": "string"
+    },
+    "title": {
+      "description": "Title of the dataset, in Spanish if available.",
+      "type": "string"
+    },
+    "publisher": {
+      "description": "Identifier of the publisher, e.g. L01280796.",
+      "type": "string"
+    },
+    "formats": {
+      "description": "MIME types of the distributions, without duplicates.",
+      "type": "array",
+      "items": {"type": "string"}
+    },
+    "modified": {
+      "description": "Time the dataset was last modified, 0001-01-01T00:00:00Z if unknown.",
+      "type": "string",
+      "format": "date-time"
+    },
+    "urls": {
+      "description": "Links to download the distributions.",
+      "type": "array",
+      "items": {"type": "string"}
+    }
+  },
+  "required": ["id", "title", "publisher", "formats", "modified", "urls"]
+}
+`
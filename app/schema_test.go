@@ -0,0 +1,146 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erizocosmico/datos"
+)
+
+type jsonSchema struct {
+	Type       string                 `json:"type"`
+	Format     string                 `json:"format"`
+	Items      *jsonSchema            `json:"items"`
+	Properties map[string]*jsonSchema `json:"properties"`
+	Required   []string               `json:"required"`
+}
+
+func TestMetadataSchemaMatchesStruct(t *testing.T) {
+	var schema jsonSchema
+	if err := json.Unmarshal([]byte(MetadataSchema), &schema); err != nil {
+		t.Fatalf("invalid schema: %s", err)
+	}
+
+	typ := reflect.TypeOf(DatasetMetadata{})
+	var fields []string
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		fields = append(fields, name)
+
+		prop, ok := schema.Properties[name]
+		if !ok {
+			t.Errorf("field %s is not in the schema", name)
+			continue
+		}
+
+		var expected string
+		switch {
+		case f.Type == reflect.TypeOf(time.Time{}):
+			expected = "string"
+			if prop.Format != "date-time" {
+				t.Errorf("expected date-time format for %s, got: %s", name, prop.Format)
+			}
+		case f.Type.Kind() == reflect.String:
+			expected = "string"
+		case f.Type.Kind() == reflect.Slice:
+			expected = "array"
+		}
+
+		if prop.Type != expected {
+			t.Errorf("wrong type of %s in schema, expected: %s, got: %s", name, expected, prop.Type)
+		}
+	}
+
+	sort.Strings(fields)
+	required := append([]string(nil), schema.Required...)
+	sort.Strings(required)
+	if strings.Join(fields, ",") != strings.Join(required, ",") || len(schema.Properties) != len(fields) {
+		t.Errorf("schema properties don't match the fields, expected: %v, got required: %v", fields, required)
+	}
+}
+
+func TestWriteMetadataNDJSONValidates(t *testing.T) {
+	var schema jsonSchema
+	if err := json.Unmarshal([]byte(MetadataSchema), &schema); err != nil {
+		t.Fatalf("invalid schema: %s", err)
+	}
+
+	datasets := []datos.Dataset{
+		{
+			About:        "http://datos.gob.es/catalogo/l01280796-calidad-del-aire",
+			Title:        datos.LangStrings{{Text: "Calidad del aire"}},
+			Modified:     datos.Datetime{Time: time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC)},
+			Distribution: datos.Distributions{{AccessURL: "http://example.com/aire.csv"}},
+		},
+		{About: "http://datos.gob.es/catalogo/vacio"},
+	}
+
+	var b strings.Builder
+	if err := WriteMetadata(&b, MetadataNDJSON, datasets); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var lines int
+	s := bufio.NewScanner(strings.NewReader(b.String()))
+	for s.Scan() {
+		lines++
+		var record map[string]interface{}
+		if err := json.Unmarshal(s.Bytes(), &record); err != nil {
+			t.Fatalf("invalid JSON line %q: %s", s.Text(), err)
+		}
+
+		for _, name := range schema.Required {
+			if _, ok := record[name]; !ok {
+				t.Errorf("missing required field %s in %s", name, s.Text())
+			}
+		}
+
+		for name, v := range record {
+			prop, ok := schema.Properties[name]
+			if !ok {
+				t.Errorf("field %s not in schema", name)
+				continue
+			}
+
+			if !matchesSchemaType(prop, v) {
+				t.Errorf("field %s does not match the schema: %v", name, v)
+			}
+		}
+	}
+
+	if lines != len(datasets) {
+		t.Errorf("wrong number of lines, expected: %d, got: %d", len(datasets), lines)
+	}
+}
+
+func matchesSchemaType(s *jsonSchema, v interface{}) bool {
+	switch s.Type {
+	case "string":
+		str, ok := v.(string)
+		if ok && s.Format == "date-time" {
+			_, err := time.Parse(time.RFC3339, str)
+			return err == nil
+		}
+		return ok
+	case "array":
+		items, ok := v.([]interface{})
+		if !ok {
+			return false
+		}
+
+		for _, item := range items {
+			if !matchesSchemaType(s.Items, item) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
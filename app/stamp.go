@@ -0,0 +1,30 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// xdgOriginURL is the extended attribute used by browsers and other tools
+// to store the URL a file was downloaded from.
+const xdgOriginURL = "user.xdg.origin.url"
+
+var errXattrUnsupported = errors.New("extended attributes are not supported")
+
+// stamp sets the modification time of the file to the time the dataset was
+// modified and stores the URL it was downloaded from in an extended
+// attribute, if the filesystem supports it.
+func stamp(path string, d Dataset) error {
+	if !d.Modified.IsZero() {
+		if err := os.Chtimes(path, time.Now(), d.Modified); err != nil {
+			return err
+		}
+	}
+
+	if err := setXattr(path, xdgOriginURL, d.URL); err != nil && err != errXattrUnsupported {
+		return err
+	}
+
+	return nil
+}
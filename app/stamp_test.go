@@ -0,0 +1,45 @@
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStamp(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "foo.csv")
+	if err := ioutil.WriteFile(path, []byte("a,b\n"), 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	d := Dataset{
+		ID:       "foo",
+		URL:      "http://example.com/foo.csv",
+		Modified: time.Date(2019, time.March, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	if err := stamp(path, d); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !fi.ModTime().Equal(d.Modified) {
+		t.Errorf("wrong modification time, expected: %s, got: %s", d.Modified, fi.ModTime())
+	}
+
+	if !isUpToDate(d, dir) {
+		t.Errorf("expected stamped file to be up to date")
+	}
+}
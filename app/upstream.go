@@ -0,0 +1,332 @@
+package app
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/erizocosmico/datos"
+)
+
+// Kinds of problems found in the catalog.
+const (
+	ProblemUndecodable       = "undecodable"
+	ProblemMissingIdentifier = "missing-identifier"
+	ProblemMissingTitle      = "missing-title"
+	ProblemMissingModified   = "missing-modified"
+	ProblemNoDistributions   = "no-distributions"
+	ProblemMissingAccessURL  = "missing-access-url"
+	ProblemMissingFormat     = "missing-format"
+	ProblemBrokenLink        = "broken-link"
+)
+
+// Problem found in the metadata of a dataset published in the catalog.
+type Problem struct {
+	// Publisher is the link to the publisher of the dataset.
+	Publisher string
+	// Dataset is the link to the dataset.
+	Dataset string
+	// Title of the dataset.
+	Title string
+	// Kind of the problem.
+	Kind string
+	// Detail about the problem.
+	Detail string
+}
+
+// UpstreamOptions controls which datasets are checked when building an
+// upstream report.
+type UpstreamOptions struct {
+	// Publisher limits the report to the datasets of the given publisher.
+	Publisher string
+	// Max is the maximum number of datasets checked. If it's 0, the whole
+	// catalog is checked.
+	Max int
+	// CheckLinks checks that the distributions of every dataset can be
+	// downloaded. This makes a request for every distribution.
+	CheckLinks bool
+}
+
+// UpstreamReport contains the problems found in the catalog, meant to be
+// forwarded to the portal maintainers.
+type UpstreamReport struct {
+	// Datasets is the number of datasets checked.
+	Datasets int
+	// Problems found, sorted by publisher.
+	Problems []Problem
+
+	publishers map[string]string
+}
+
+// ReportUpstream checks the datasets of the catalog looking for broken
+// links, invalid metadata and datasets that can not be decoded.
+func ReportUpstream(ctx context.Context, client *datos.Client, opts UpstreamOptions) (*UpstreamReport, error) {
+	var warnings datos.Warnings
+	ctx = datos.CollectWarnings(ctx, &warnings)
+
+	report := &UpstreamReport{publishers: publisherLabels(ctx, client)}
+	params := datos.Params{PageSize: 100}
+	links := &http.Client{Timeout: 15 * time.Second}
+
+	for {
+		var datasets []datos.Dataset
+		var err error
+		if opts.Publisher != "" {
+			datasets, err = client.DatasetsByPublisher(ctx, opts.Publisher, params)
+		} else {
+			datasets, err = client.Datasets(ctx, params)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ds := range datasets {
+			report.Datasets++
+			report.Problems = append(report.Problems, datasetProblems(ds)...)
+			if opts.CheckLinks {
+				report.Problems = append(report.Problems, brokenLinks(ctx, links, ds)...)
+			}
+
+			if opts.Max > 0 && report.Datasets >= opts.Max {
+				break
+			}
+		}
+
+		if len(datasets) < int(params.PageSize) || (opts.Max > 0 && report.Datasets >= opts.Max) {
+			break
+		}
+
+		params.Page++
+	}
+
+	for _, w := range warnings.List() {
+		report.Datasets++
+		report.Problems = append(report.Problems, Problem{
+			Publisher: w.Publisher,
+			Dataset:   w.About,
+			Kind:      ProblemUndecodable,
+			Detail:    w.Err.Error(),
+		})
+	}
+
+	sort.SliceStable(report.Problems, func(i, j int) bool {
+		return report.publisher(report.Problems[i].Publisher) < report.publisher(report.Problems[j].Publisher)
+	})
+
+	return report, nil
+}
+
+// publisherLabels returns the labels of all publishers keyed by their link.
+// Labels are only used to make the report readable, so errors are ignored.
+func publisherLabels(ctx context.Context, client *datos.Client) map[string]string {
+	var labels = make(map[string]string)
+	params := datos.Params{PageSize: 100}
+	for {
+		publishers, err := client.Publishers(ctx, params)
+		if err != nil {
+			return labels
+		}
+
+		for _, p := range publishers {
+			labels[p.About] = p.Label
+		}
+
+		if len(publishers) < int(params.PageSize) {
+			return labels
+		}
+		params.Page++
+	}
+}
+
+func datasetProblems(ds datos.Dataset) []Problem {
+	var problems []Problem
+	add := func(kind, detail string) {
+		problems = append(problems, Problem{ds.Publisher, ds.About, datasetTitle(ds), kind, detail})
+	}
+
+	if ds.Identifier == "" {
+		add(ProblemMissingIdentifier, "")
+	}
+
+	if len(ds.Title) == 0 {
+		add(ProblemMissingTitle, "")
+	}
+
+	if ds.Modified.IsZero() {
+		add(ProblemMissingModified, "")
+	}
+
+	if len(ds.Distribution) == 0 {
+		add(ProblemNoDistributions, "")
+	}
+
+	for _, d := range ds.Distribution {
+		if d.AccessURL == "" {
+			add(ProblemMissingAccessURL, d.About)
+		}
+
+		if d.Format.Value == "" {
+			add(ProblemMissingFormat, d.AccessURL)
+		}
+	}
+
+	return problems
+}
+
+func brokenLinks(ctx context.Context, client *http.Client, ds datos.Dataset) []Problem {
+	var problems []Problem
+	for _, d := range ds.Distribution {
+		if d.AccessURL == "" {
+			continue
+		}
+
+		if err := checkLink(ctx, client, d.AccessURL); err != nil {
+			problems = append(problems, Problem{
+				ds.Publisher,
+				ds.About,
+				datasetTitle(ds),
+				ProblemBrokenLink,
+				fmt.Sprintf("%s: %s", d.AccessURL, err),
+			})
+		}
+	}
+	return problems
+}
+
+// checkLink checks the link can be downloaded. Some servers do not
+// support HEAD requests, so a GET for the first byte is made if it fails.
+func checkLink(ctx context.Context, client *http.Client, url string) error {
+	var err error
+	for _, method := range []string{"HEAD", "GET"} {
+		var req *http.Request
+		req, err = http.NewRequest(method, url, nil)
+		if err != nil {
+			return err
+		}
+
+		if method == "GET" {
+			req.Header.Set("Range", "bytes=0-0")
+		}
+
+		var resp *http.Response
+		resp, err = client.Do(req.WithContext(ctx))
+		if err != nil {
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode < 400 {
+			return nil
+		}
+		err = fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return err
+}
+
+func datasetTitle(ds datos.Dataset) string {
+	return ds.Title.Default()
+}
+
+func (r *UpstreamReport) publisher(link string) string {
+	if l, ok := r.publishers[link]; ok && l != "" {
+		return l
+	}
+
+	if link == "" {
+		return "Unknown publisher"
+	}
+	return lastSegment(link)
+}
+
+// WriteCSV writes the report as CSV.
+func (r *UpstreamReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"publisher", "dataset", "title", "problem", "detail"}); err != nil {
+		return err
+	}
+
+	for _, p := range r.Problems {
+		if err := cw.Write([]string{r.publisher(p.Publisher), p.Dataset, p.Title, p.Kind, p.Detail}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteMarkdown writes the report as Markdown, with a section per
+// publisher.
+func (r *UpstreamReport) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Catalog problems\n\n%d problem(s) found in %d dataset(s).\n", len(r.Problems), r.Datasets)
+
+	var last string
+	for i, p := range r.Problems {
+		if pub := r.publisher(p.Publisher); i == 0 || pub != last {
+			fmt.Fprintf(&b, "\n## %s\n\n| Dataset | Title | Problem | Detail |\n|---|---|---|---|\n", pub)
+			last = pub
+		}
+
+		fmt.Fprintf(
+			&b,
+			"| %s | %s | %s | %s |\n",
+			markdownCell(p.Dataset),
+			markdownCell(p.Title),
+			p.Kind,
+			markdownCell(p.Detail),
+		)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteXLSX writes the report as an Excel workbook, with a sheet for the
+// problems and another one with the number of problems per publisher.
+func (r *UpstreamReport) WriteXLSX(w io.Writer) error {
+	return WriteXLSX(w, r.sheets())
+}
+
+// WriteODS writes the report as an OpenDocument spreadsheet, with the same
+// sheets as WriteXLSX.
+func (r *UpstreamReport) WriteODS(w io.Writer) error {
+	return WriteODS(w, r.sheets())
+}
+
+func (r *UpstreamReport) sheets() []Sheet {
+	problems := Sheet{
+		Name: "Problems",
+		Rows: [][]interface{}{{"Publisher", "Dataset", "Title", "Problem", "Detail"}},
+	}
+	publishers := Sheet{
+		Name: "Publishers",
+		Rows: [][]interface{}{{"Publisher", "Problems"}},
+	}
+
+	var last string
+	for i, p := range r.Problems {
+		pub := r.publisher(p.Publisher)
+		problems.Rows = append(problems.Rows, []interface{}{pub, p.Dataset, p.Title, p.Kind, p.Detail})
+
+		if i == 0 || pub != last {
+			publishers.Rows = append(publishers.Rows, []interface{}{pub, 0})
+			last = pub
+		}
+		row := publishers.Rows[len(publishers.Rows)-1]
+		row[1] = row[1].(int) + 1
+	}
+
+	return []Sheet{problems, publishers}
+}
+
+func markdownCell(s string) string {
+	s = strings.Replace(s, "|", `\|`, -1)
+	return strings.Replace(s, "\n", " ", -1)
+}
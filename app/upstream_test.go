@@ -0,0 +1,85 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/erizocosmico/datos"
+)
+
+func TestDatasetProblems(t *testing.T) {
+	ds := datos.Dataset{
+		About:        "http://datos.gob.es/catalogo/foo",
+		Distribution: datos.Distributions{{About: "http://datos.gob.es/catalogo/foo/1"}},
+	}
+
+	var kinds []string
+	for _, p := range datasetProblems(ds) {
+		kinds = append(kinds, p.Kind)
+	}
+
+	expected := []string{
+		ProblemMissingIdentifier,
+		ProblemMissingTitle,
+		ProblemMissingModified,
+		ProblemMissingAccessURL,
+		ProblemMissingFormat,
+	}
+
+	if strings.Join(kinds, ",") != strings.Join(expected, ",") {
+		t.Errorf("wrong problems, expected: %v, got: %v", expected, kinds)
+	}
+}
+
+func TestUpstreamReportWriteMarkdown(t *testing.T) {
+	report := &UpstreamReport{
+		Datasets: 2,
+		Problems: []Problem{
+			{Publisher: "http://example.com/org/A1", Dataset: "a", Kind: ProblemMissingTitle},
+			{Publisher: "http://example.com/org/A1", Dataset: "b", Kind: ProblemBrokenLink, Detail: "x|y"},
+		},
+		publishers: map[string]string{"http://example.com/org/A1": "Ayuntamiento"},
+	}
+
+	var b strings.Builder
+	if err := report.WriteMarkdown(&b); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	out := b.String()
+	if strings.Count(out, "## Ayuntamiento") != 1 {
+		t.Errorf("expected a single section for the publisher, got:\n%s", out)
+	}
+
+	if !strings.Contains(out, `x\|y`) {
+		t.Errorf("expected pipes to be escaped, got:\n%s", out)
+	}
+}
+
+func TestUpstreamReportWriteXLSX(t *testing.T) {
+	report := &UpstreamReport{
+		Datasets: 3,
+		Problems: []Problem{
+			{Publisher: "http://example.com/org/A1", Dataset: "a", Kind: ProblemMissingTitle},
+			{Publisher: "http://example.com/org/A1", Dataset: "b", Kind: ProblemBrokenLink},
+			{Publisher: "http://example.com/org/B2", Dataset: "c", Kind: ProblemBrokenLink},
+		},
+		publishers: map[string]string{"http://example.com/org/A1": "Ayuntamiento"},
+	}
+
+	var b bytes.Buffer
+	if err := report.WriteXLSX(&b); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	publishers := readZip(t, b.Bytes())["xl/worksheets/sheet2.xml"]
+	for _, expected := range []string{
+		`<t xml:space="preserve">Ayuntamiento</t></is></c><c r="B2" s="0"><v>2</v></c>`,
+		`<t xml:space="preserve">B2</t></is></c><c r="B3" s="0"><v>1</v></c>`,
+	} {
+		if !strings.Contains(publishers, expected) {
+			t.Errorf("expected %s in publishers sheet:\n%s", expected, publishers)
+		}
+	}
+}
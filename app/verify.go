@@ -0,0 +1,133 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// VerifyReport is the result of checking the files of an output directory
+// against its manifest.
+type VerifyReport struct {
+	// Checked is the number of datasets in the manifest.
+	Checked int
+	// Missing are the datasets whose file does not exist.
+	Missing []ManifestEntry
+	// Mismatched are the datasets whose file has changed since it was
+	// downloaded.
+	Mismatched []ManifestEntry
+	// Orphans are the files not recorded in the manifest, nor converted
+	// from a file recorded in it.
+	Orphans []string
+	// Repaired is set when the datasets with missing or mismatched files
+	// were removed from the manifest.
+	Repaired bool
+}
+
+// OK reports whether no problems were found.
+func (r *VerifyReport) OK() bool {
+	return len(r.Missing) == 0 && len(r.Mismatched) == 0 && len(r.Orphans) == 0
+}
+
+// Verify checks the files of the output directory against its manifest,
+// looking for missing files, files whose checksum does not match and files
+// that are not in the manifest. With repair, the datasets with missing or
+// mismatched files are removed from the manifest, so the next sync
+// downloads them again. Orphan files are only reported, since they may
+// have been added to the directory by the user.
+func Verify(dir string, repair bool) (*VerifyReport, error) {
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &VerifyReport{Checked: len(manifest.Datasets)}
+	known := make(map[string]bool)
+	for _, id := range sortedIDs(manifest) {
+		e := manifest.Datasets[id]
+		known[strings.TrimSuffix(e.File, filepath.Ext(e.File))] = true
+
+		hash, err := hashFile(filepath.Join(dir, e.File))
+		switch {
+		case os.IsNotExist(err):
+			report.Missing = append(report.Missing, e)
+		case err != nil:
+			return nil, err
+		case hash != e.SHA256:
+			report.Mismatched = append(report.Mismatched, e)
+		}
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || name == manifestFile || strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		// Converted files have the same name as the downloaded ones with
+		// another extension.
+		if !known[strings.TrimSuffix(name, filepath.Ext(name))] {
+			report.Orphans = append(report.Orphans, name)
+		}
+	}
+
+	if repair && (len(report.Missing) > 0 || len(report.Mismatched) > 0) {
+		for _, e := range append(report.Missing, report.Mismatched...) {
+			delete(manifest.Datasets, e.ID)
+		}
+
+		if err := manifest.write(dir); err != nil {
+			return nil, err
+		}
+		report.Repaired = true
+	}
+
+	return report, nil
+}
+
+func sortedIDs(m *Manifest) []string {
+	ids := make([]string, 0, len(m.Datasets))
+	for id := range m.Datasets {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// WriteVerifyReport writes the problems found verifying an output
+// directory in a human readable way.
+func WriteVerifyReport(w io.Writer, r *VerifyReport) error {
+	var lines []string
+	for _, e := range r.Missing {
+		lines = append(lines, fmt.Sprintf("missing: %s (%s)", e.File, e.ID))
+	}
+
+	for _, e := range r.Mismatched {
+		lines = append(lines, fmt.Sprintf("checksum mismatch: %s (%s)", e.File, e.ID))
+	}
+
+	for _, f := range r.Orphans {
+		lines = append(lines, fmt.Sprintf("not in manifest: %s", f))
+	}
+
+	lines = append(lines, fmt.Sprintf(
+		"%d dataset(s) checked: %d missing, %d mismatched, %d file(s) not in manifest",
+		r.Checked, len(r.Missing), len(r.Mismatched), len(r.Orphans),
+	))
+
+	if r.Repaired {
+		lines = append(lines, "removed missing and mismatched datasets from the manifest, run sync to download them again")
+	}
+
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
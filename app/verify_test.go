@@ -0,0 +1,84 @@
+package app
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	m, err := ReadManifest(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, id := range []string{"ok", "missing", "changed"} {
+		path := filepath.Join(dir, id+".json")
+		if err := ioutil.WriteFile(path, []byte(`{}`), 0644); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if err := m.add(Dataset{ID: id}, path); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	if err := m.write(dir); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	files := map[string]string{
+		"changed.json": `{"a":1}`,
+		"ok.csv":       "a\n",
+		"other.txt":    "foo",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	os.Remove(filepath.Join(dir, "missing.json"))
+
+	r, err := Verify(dir, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if r.OK() || r.Checked != 3 || len(r.Missing) != 1 || r.Missing[0].ID != "missing" ||
+		len(r.Mismatched) != 1 || r.Mismatched[0].ID != "changed" ||
+		strings.Join(r.Orphans, ",") != "other.txt" || r.Repaired {
+		t.Errorf("wrong report: %+v", r)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteVerifyReport(&buf, r); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.Contains(buf.String(), "missing: missing.json (missing)") {
+		t.Errorf("wrong report output:\n%s", buf.String())
+	}
+
+	r, err = Verify(dir, true)
+	if err != nil || !r.Repaired {
+		t.Fatalf("expected manifest to be repaired, got: %+v, %v", r, err)
+	}
+
+	m, err = ReadManifest(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(m.Datasets) != 1 || m.Datasets["ok"].ID != "ok" {
+		t.Errorf("expected only the verified dataset in the manifest, got: %v", m.Datasets)
+	}
+}
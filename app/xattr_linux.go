@@ -0,0 +1,19 @@
+package app
+
+import "syscall"
+
+func init() {
+	registerFeature(Feature{
+		Name:        "xattr",
+		Description: "store the source URL of downloaded files in extended attributes",
+		Enabled:     true,
+	})
+}
+
+func setXattr(path, name, value string) error {
+	err := syscall.Setxattr(path, name, []byte(value), 0)
+	if err == syscall.ENOTSUP {
+		return errXattrUnsupported
+	}
+	return err
+}
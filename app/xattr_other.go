@@ -0,0 +1,16 @@
+//go:build !linux
+// +build !linux
+
+package app
+
+func init() {
+	registerFeature(Feature{
+		Name:        "xattr",
+		Description: "store the source URL of downloaded files in extended attributes",
+		Requires:    "linux",
+	})
+}
+
+func setXattr(path, name, value string) error {
+	return errXattrUnsupported
+}
@@ -0,0 +1,218 @@
+package app
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Sheet is a table written to a spreadsheet. Its first row is the header.
+// Cells can be strings, numbers or times.
+type Sheet struct {
+	Name string
+	Rows [][]interface{}
+}
+
+// maxColumnWidth is the maximum width of the columns of a sheet, in
+// characters, so long URLs don't make the sheet hard to read.
+const maxColumnWidth = 60
+
+// Styles of the cells, as defined in xlsxStyles.
+const (
+	xlsxStyleDefault = iota
+	xlsxStyleHeader
+	xlsxStyleDate
+)
+
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/><xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs>
+<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>
+</styleSheet>`
+
+// WriteXLSX writes the sheets as an Excel workbook. Headers are bold, the
+// header row is frozen and has filters, and dates are formatted as such.
+func WriteXLSX(w io.Writer, sheets []Sheet) error {
+	z := zip.NewWriter(w)
+
+	var types, rels, entries strings.Builder
+	for i, s := range sheets {
+		n := i + 1
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+		fmt.Fprintf(&entries, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(sheetName(s.Name)), n, n)
+	}
+	stylesID := len(sheets) + 1
+
+	files := []struct{ name, content string }{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` + types.String() + `</Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>` + entries.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + rels.String() + fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, stylesID) + `</Relationships>`},
+		{"xl/styles.xml", xlsxStyles},
+	}
+
+	for i, s := range sheets {
+		files = append(files, struct{ name, content string }{
+			fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1),
+			xlsxSheet(s),
+		})
+	}
+
+	for _, f := range files {
+		fw, err := z.Create(f.name)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.WriteString(fw, f.content); err != nil {
+			return err
+		}
+	}
+
+	return z.Close()
+}
+
+// xlsxSheet returns the XML of the worksheet with the rows of the sheet.
+func xlsxSheet(s Sheet) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+
+	if widths := columnWidths(s.Rows); len(widths) > 0 {
+		b.WriteString("<cols>")
+		for i, w := range widths {
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, w)
+		}
+		b.WriteString("</cols>")
+	}
+
+	b.WriteString("<sheetData>")
+	var cols int
+	for i, row := range s.Rows {
+		if len(row) > cols {
+			cols = len(row)
+		}
+
+		fmt.Fprintf(&b, `<row r="%d">`, i+1)
+		for j, v := range row {
+			ref := fmt.Sprintf("%s%d", columnName(j), i+1)
+			style := xlsxStyleDefault
+			if i == 0 {
+				style = xlsxStyleHeader
+			}
+			writeXLSXCell(&b, ref, style, v)
+		}
+		b.WriteString("</row>")
+	}
+	b.WriteString("</sheetData>")
+
+	if len(s.Rows) > 0 && cols > 0 {
+		fmt.Fprintf(&b, `<autoFilter ref="A1:%s%d"/>`, columnName(cols-1), len(s.Rows))
+	}
+
+	b.WriteString("</worksheet>")
+	return b.String()
+}
+
+func writeXLSXCell(b *strings.Builder, ref string, style int, v interface{}) {
+	switch v := v.(type) {
+	case int, int64, float64:
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%v</v></c>`, ref, style, v)
+	case time.Time:
+		if v.IsZero() {
+			fmt.Fprintf(b, `<c r="%s" s="%d"/>`, ref, style)
+			return
+		}
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, xlsxStyleDate, excelDate(v))
+	default:
+		fmt.Fprintf(
+			b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`,
+			ref, style, xmlEscape(fmt.Sprint(v)),
+		)
+	}
+}
+
+// excelEpoch is the day 0 of the dates of Excel, which wrongly counts
+// 1900 as a leap year, so it's one day before 31 December 1899.
+var excelEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+// excelDate returns the time as the number of days since the epoch of
+// Excel, which is how dates are stored.
+func excelDate(t time.Time) string {
+	t = t.UTC()
+	days := t.Sub(excelEpoch).Hours() / 24
+	return fmt.Sprintf("%.6f", days)
+}
+
+// columnName returns the name of the column with the given index, e.g.
+// A for 0, Z for 25 and AA for 26.
+func columnName(i int) string {
+	var name []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = append([]byte{byte('A' + (i-1)%26)}, name...)
+	}
+	return string(name)
+}
+
+// columnWidths returns the width of each column, enough to fit its longest
+// value up to maxColumnWidth.
+func columnWidths(rows [][]interface{}) []int {
+	var widths []int
+	for _, row := range rows {
+		for i, v := range row {
+			if i >= len(widths) {
+				widths = append(widths, 8)
+			}
+
+			n := utf8.RuneCountInString(fmt.Sprint(v)) + 2
+			if _, ok := v.(time.Time); ok {
+				n = 20
+			}
+
+			if n > maxColumnWidth {
+				n = maxColumnWidth
+			}
+			if n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	return widths
+}
+
+// sheetName returns a valid sheet name, which can't have some characters
+// and is at most 31 characters long.
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+
+	if utf8.RuneCountInString(name) > 31 {
+		name = string([]rune(name)[:31])
+	}
+	return name
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erizocosmico/datos"
+)
+
+func TestWriteXLSX(t *testing.T) {
+	modified := time.Date(2020, time.March, 1, 12, 0, 0, 0, time.UTC)
+	sheets := []Sheet{
+		{
+			Name: "Datasets",
+			Rows: [][]interface{}{
+				{"ID", "Title", "Size", "Modified"},
+				{"aire", "Calidad <del> aire & agua", 1024, modified},
+			},
+		},
+		{Name: "Empty: [1/2]"},
+	}
+
+	var b bytes.Buffer
+	if err := WriteXLSX(&b, sheets); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	files := readZip(t, b.Bytes())
+	for _, name := range []string{
+		"[Content_Types].xml",
+		"_rels/.rels",
+		"xl/workbook.xml",
+		"xl/_rels/workbook.xml.rels",
+		"xl/styles.xml",
+		"xl/worksheets/sheet1.xml",
+		"xl/worksheets/sheet2.xml",
+	} {
+		content, ok := files[name]
+		if !ok {
+			t.Errorf("missing file %s in workbook", name)
+			continue
+		}
+
+		if err := xml.Unmarshal([]byte(content), new(interface{})); err != nil {
+			t.Errorf("invalid XML in %s: %s", name, err)
+		}
+	}
+
+	workbook := files["xl/workbook.xml"]
+	for _, name := range []string{`name="Datasets"`, `name="Empty_ _1_2_"`} {
+		if !strings.Contains(workbook, name) {
+			t.Errorf("expected sheet %s in workbook:\n%s", name, workbook)
+		}
+	}
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	for _, expected := range []string{
+		`<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">ID</t></is></c>`,
+		`<t xml:space="preserve">Calidad &lt;del&gt; aire &amp; agua</t>`,
+		`<c r="C2" s="0"><v>1024</v></c>`,
+		`<c r="D2" s="2"><v>43891.500000</v></c>`,
+		`<autoFilter ref="A1:D2"/>`,
+		`state="frozen"`,
+	} {
+		if !strings.Contains(sheet, expected) {
+			t.Errorf("expected %s in sheet:\n%s", expected, sheet)
+		}
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	cases := map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"}
+	for i, expected := range cases {
+		if got := columnName(i); got != expected {
+			t.Errorf("wrong column name for %d, expected: %s, got: %s", i, expected, got)
+		}
+	}
+}
+
+func TestWriteMetadataXLSX(t *testing.T) {
+	ds := datos.Dataset{
+		About: "http://datos.gob.es/catalogo/l01280796-calidad-del-aire",
+		Title: datos.LangStrings{{Text: "Calidad del aire"}},
+		Distribution: datos.Distributions{
+			{AccessURL: "http://example.com/aire.csv"},
+			{AccessURL: "http://example.com/aire.json"},
+		},
+	}
+
+	var b bytes.Buffer
+	if err := WriteMetadata(&b, MetadataXLSX, []datos.Dataset{ds}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	files := readZip(t, b.Bytes())
+	if n := strings.Count(files["xl/worksheets/sheet1.xml"], "<row "); n != 2 {
+		t.Errorf("wrong number of dataset rows, expected: 2, got: %d", n)
+	}
+
+	if n := strings.Count(files["xl/worksheets/sheet2.xml"], "<row "); n != 3 {
+		t.Errorf("wrong number of distribution rows, expected: 3, got: %d", n)
+	}
+}
+
+func readZip(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	files := make(map[string]string)
+	for _, f := range z.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		content, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		files[f.Name] = string(content)
+	}
+	return files
+}
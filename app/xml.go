@@ -0,0 +1,245 @@
+package app
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type xmlNode struct {
+	name     string
+	attrs    []xml.Attr
+	children []*xmlNode
+	text     strings.Builder
+}
+
+func parseXML(r io.Reader) (*xmlNode, error) {
+	var doc = new(xmlNode)
+	var stack = []*xmlNode{doc}
+
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	d.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return doc, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		parent := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{name: t.Name.Local, attrs: t.Attr}
+			parent.children = append(parent.children, n)
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			parent.text.Write(t)
+		}
+	}
+}
+
+type xpathStep struct {
+	name       string
+	descendant bool
+}
+
+// parseXPath parses the small subset of XPath supported to select records:
+// element names or * separated by / or // (descendants). Paths are always
+// evaluated from the document root.
+func parseXPath(path string) ([]xpathStep, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		path = "//" + path
+	}
+
+	var steps []xpathStep
+	for len(path) > 0 {
+		var step xpathStep
+		if strings.HasPrefix(path, "//") {
+			step.descendant = true
+			path = path[2:]
+		} else {
+			path = path[1:]
+		}
+
+		end := strings.Index(path, "/")
+		if end < 0 {
+			end = len(path)
+		}
+
+		step.name = path[:end]
+		if step.name == "" || strings.ContainsAny(step.name, "[]()@=") {
+			return nil, fmt.Errorf("unsupported path step %q", step.name)
+		}
+
+		steps = append(steps, step)
+		path = path[end:]
+	}
+
+	return steps, nil
+}
+
+func (n *xmlNode) selectNodes(steps []xpathStep) []*xmlNode {
+	var current = []*xmlNode{n}
+	for _, s := range steps {
+		var next []*xmlNode
+		for _, c := range current {
+			next = append(next, c.matching(s)...)
+		}
+		current = next
+	}
+	return current
+}
+
+func (n *xmlNode) matching(s xpathStep) []*xmlNode {
+	var result []*xmlNode
+	for _, c := range n.children {
+		if s.name == "*" || c.name == s.name {
+			result = append(result, c)
+		}
+
+		if s.descendant {
+			result = append(result, c.matching(s)...)
+		}
+	}
+	return result
+}
+
+// value converts the node into a value that can be flattened: leaf nodes
+// are converted to their text and the rest to objects whose keys are the
+// names of the children, with attributes prefixed by @.
+func (n *xmlNode) value() interface{} {
+	text := strings.TrimSpace(n.text.String())
+	if len(n.children) == 0 && len(n.attrs) == 0 {
+		return text
+	}
+
+	var obj = make(map[string]interface{})
+	for _, a := range n.attrs {
+		if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+			continue
+		}
+		obj["@"+a.Name.Local] = a.Value
+	}
+
+	if text != "" {
+		obj["#text"] = text
+	}
+
+	for _, c := range n.children {
+		v := c.value()
+		switch prev := obj[c.name].(type) {
+		case nil:
+			obj[c.name] = v
+		case []interface{}:
+			obj[c.name] = append(prev, v)
+		default:
+			obj[c.name] = []interface{}{prev, v}
+		}
+	}
+
+	return obj
+}
+
+// convertXML returns a convert function that turns downloaded XML files into
+// CSV or JSON files, using the records selected by the given path.
+func convertXML(format, path string) (convertFunc, error) {
+	if format != "csv" && format != "json" {
+		return nil, fmt.Errorf("unsupported XML conversion format: %s", format)
+	}
+
+	if path == "" {
+		path = "/*/*"
+	}
+
+	steps, err := parseXPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid XML path %q: %s", path, err)
+	}
+
+	return func(file string) (string, error) {
+		if filepath.Ext(file) != ".xml" {
+			return file, nil
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return "", err
+		}
+
+		doc, err := parseXML(f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+
+		var rows []map[string]string
+		var columns = make(map[string]struct{})
+		for _, n := range doc.selectNodes(steps) {
+			row := make(map[string]string)
+			flattenValue("", n.value(), row)
+			for c := range row {
+				columns[c] = struct{}{}
+			}
+			rows = append(rows, row)
+		}
+
+		out := strings.TrimSuffix(file, filepath.Ext(file)) + "." + format
+		if format == "json" {
+			err = writeJSONRows(out, rows)
+		} else {
+			var header = make([]string, 0, len(columns))
+			for c := range columns {
+				header = append(header, c)
+			}
+			sort.Strings(header)
+			err = writeRows(out, header, rows)
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		return out, os.Remove(file)
+	}, nil
+}
+
+func writeJSONRows(path string, rows []map[string]string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if rows == nil {
+		rows = []map[string]string{}
+	}
+
+	e := json.NewEncoder(f)
+	e.SetIndent("", "  ")
+	if err := e.Encode(rows); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,168 @@
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testXML = `<?xml version="1.0" encoding="ISO-8859-1"?>
+<datos xmlns="http://example.com/datos">
+	<fila id="1" xmlns:x="http://example.com/x">
+		<nombre>Madrid</nombre>
+		<telefono>910000000</telefono>
+		<telefono>910000001</telefono>
+	</fila>
+	<fila id="2">
+		<nombre idioma="es">Sevilla</nombre>
+		<poblacion>688592</poblacion>
+	</fila>
+</datos>`
+
+func TestParseXPath(t *testing.T) {
+	cases := []struct {
+		path  string
+		steps []xpathStep
+		err   bool
+	}{
+		{"/datos/fila", []xpathStep{{"datos", false}, {"fila", false}}, false},
+		{"fila", []xpathStep{{"fila", true}}, false},
+		{"/*//fila", []xpathStep{{"*", false}, {"fila", true}}, false},
+		{"", nil, true},
+		{"/datos/", nil, true},
+		{"/datos/fila[1]", nil, true},
+		{"//@id", nil, true},
+	}
+
+	for _, c := range cases {
+		steps, err := parseXPath(c.path)
+		if c.err {
+			if err == nil {
+				t.Errorf("expected error parsing %q", c.path)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("unexpected error parsing %q: %s", c.path, err)
+			continue
+		}
+
+		if len(steps) != len(c.steps) {
+			t.Errorf("wrong steps for %q, expected: %v, got: %v", c.path, c.steps, steps)
+			continue
+		}
+
+		for i := range steps {
+			if steps[i] != c.steps[i] {
+				t.Errorf("wrong steps for %q, expected: %v, got: %v", c.path, c.steps, steps)
+				break
+			}
+		}
+	}
+}
+
+func TestConvertXML(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos-xml")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		name     string
+		format   string
+		path     string
+		input    string
+		expected string
+		err      string
+	}{
+		{
+			name:   "repeated records",
+			format: "csv",
+			input:  testXML,
+			expected: "@id,nombre,nombre.#text,nombre.@idioma,poblacion,telefono\n" +
+				"1,Madrid,,,,910000000|910000001\n" +
+				"2,,Sevilla,es,688592,\n",
+		},
+		{
+			name:     "descendants",
+			format:   "csv",
+			path:     "//nombre",
+			input:    testXML,
+			expected: "#text,@idioma,value\n,,Madrid\nSevilla,es,\n",
+		},
+		{
+			name:     "json",
+			format:   "json",
+			path:     "/datos/fila/poblacion",
+			input:    testXML,
+			expected: "[\n  {\n    \"value\": \"688592\"\n  }\n]\n",
+		},
+		{
+			name:     "no records",
+			format:   "json",
+			path:     "/datos/columna",
+			input:    testXML,
+			expected: "[]\n",
+		},
+		{
+			name:   "malformed",
+			format: "csv",
+			input:  `<datos><fila id="1`,
+			err:    "XML syntax error",
+		},
+	}
+
+	for _, c := range cases {
+		file := filepath.Join(dir, "data.xml")
+		if err := ioutil.WriteFile(file, []byte(c.input), 0644); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		convert, err := convertXML(c.format, c.path)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", c.name, err)
+		}
+
+		out, err := convert(file)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s: expected error containing %q, got: %v", c.name, c.err, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+			continue
+		}
+
+		if out != filepath.Join(dir, "data."+c.format) {
+			t.Errorf("%s: wrong output file: %s", c.name, out)
+		}
+
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("%s: expected XML file to be removed", c.name)
+		}
+
+		data, err := ioutil.ReadFile(out)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if string(data) != c.expected {
+			t.Errorf("%s: wrong result, expected:\n%s\ngot:\n%s", c.name, c.expected, data)
+		}
+	}
+
+	if _, err := convertXML("xlsx", ""); err == nil {
+		t.Errorf("expected error with unsupported format")
+	}
+
+	if _, err := convertXML("csv", "/datos/fila[1]"); err == nil {
+		t.Errorf("expected error with unsupported path")
+	}
+}
@@ -0,0 +1,396 @@
+package datos
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CachedResponse is an API response stored in a Cache.
+type CachedResponse struct {
+	// Body of the response.
+	Body []byte `json:"body"`
+	// ETag header of the response.
+	ETag string `json:"etag,omitempty"`
+	// LastModified header of the response.
+	LastModified string `json:"last_modified,omitempty"`
+	// Stored is the time the response was received.
+	Stored time.Time `json:"stored"`
+}
+
+// CacheStats describes the contents and usage of a cache.
+type CacheStats struct {
+	// Entries is the number of responses stored.
+	Entries int
+	// Bytes is the size of the bodies of the responses stored.
+	Bytes int64
+	// Hits is the number of times a stored response was found since the
+	// cache was created.
+	Hits int64
+	// Misses is the number of times no response was found since the cache
+	// was created.
+	Misses int64
+}
+
+// cacheCounters counts the hits and misses of a cache.
+type cacheCounters struct {
+	hits   int64
+	misses int64
+}
+
+func (c *cacheCounters) record(hit bool) {
+	if hit {
+		atomic.AddInt64(&c.hits, 1)
+	} else {
+		atomic.AddInt64(&c.misses, 1)
+	}
+}
+
+func (c *cacheCounters) stats(stats CacheStats) CacheStats {
+	stats.Hits = atomic.LoadInt64(&c.hits)
+	stats.Misses = atomic.LoadInt64(&c.misses)
+	return stats
+}
+
+// Cache stores API responses keyed by URL. Implementations must be safe
+// for concurrent use.
+type Cache interface {
+	// Get returns the response stored for the URL, if any.
+	Get(url string) (CachedResponse, bool)
+	// Set stores the response for the URL.
+	Set(url string, resp CachedResponse)
+	// Invalidate removes the responses of the URLs starting with the given
+	// prefix and returns how many were removed.
+	Invalidate(prefix string) int
+	// Stats returns statistics of the contents of the cache.
+	Stats() CacheStats
+}
+
+// CacheTTL controls for how long cached responses are used without asking
+// the API whether they changed. Once they expire, the next request is made
+// conditional. Responses with no ETag or Last-Modified header are only
+// stored if they have a TTL.
+type CacheTTL struct {
+	// Taxonomies is the TTL of the lists of publishers, themes and
+	// spatials, which rarely change.
+	Taxonomies time.Duration
+	// Datasets is the TTL of the lists of datasets and distributions.
+	Datasets time.Duration
+}
+
+// taxonomyPaths are the paths of the API whose responses are taxonomies.
+var taxonomyPaths = []string{"/catalog/publisher", "/catalog/theme", "/catalog/spatial"}
+
+// of returns the TTL of the responses of the given API path.
+func (t CacheTTL) of(path string) time.Duration {
+	for _, p := range taxonomyPaths {
+		if strings.HasPrefix(path, p) {
+			return t.Taxonomies
+		}
+	}
+	return t.Datasets
+}
+
+// WithCache makes the client store the API responses in the given cache.
+// Responses with an ETag or Last-Modified header are stored, and the next
+// requests of the same URL are made conditional, so the response is only
+// sent again by the API if it changed.
+func WithCache(cache Cache) Option {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// WithCacheTTL sets for how long the responses stored in the cache of the
+// client are used without making any request. By default, every request is
+// sent to the API, conditionally if the response is cached.
+func WithCacheTTL(ttl CacheTTL) Option {
+	return func(c *Client) {
+		c.cacheTTL = ttl
+	}
+}
+
+// ClientCache manages the cache of a client.
+type ClientCache struct {
+	cache Cache
+	base  string
+}
+
+// Cache returns the manager of the cache of the client. If the client has
+// no cache, all its operations do nothing.
+func (c *Client) Cache() *ClientCache {
+	return &ClientCache{c.cache, c.baseURL()}
+}
+
+// Invalidate removes the cached responses of the API paths starting with
+// the given prefix, e.g. "/catalog/dataset", and returns how many were
+// removed. An empty prefix removes all of them.
+func (c *ClientCache) Invalidate(prefix string) int {
+	if c.cache == nil {
+		return 0
+	}
+	return c.cache.Invalidate(c.base + prefix)
+}
+
+// Stats returns statistics of the contents of the cache.
+func (c *ClientCache) Stats() CacheStats {
+	if c.cache == nil {
+		return CacheStats{}
+	}
+	return c.cache.Stats()
+}
+
+// MemoryCache is a Cache that keeps the responses in memory.
+type MemoryCache struct {
+	counters  cacheCounters
+	mut       sync.RWMutex
+	responses map[string]CachedResponse
+}
+
+// NewMemoryCache creates a new empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{responses: make(map[string]CachedResponse)}
+}
+
+// Get implements the Cache interface.
+func (c *MemoryCache) Get(url string) (CachedResponse, bool) {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	resp, ok := c.responses[url]
+	c.counters.record(ok)
+	return resp, ok
+}
+
+// Set implements the Cache interface.
+func (c *MemoryCache) Set(url string, resp CachedResponse) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.responses[url] = resp
+}
+
+// Invalidate implements the Cache interface.
+func (c *MemoryCache) Invalidate(prefix string) int {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	var n int
+	for url := range c.responses {
+		if strings.HasPrefix(url, prefix) {
+			delete(c.responses, url)
+			n++
+		}
+	}
+	return n
+}
+
+// Stats implements the Cache interface.
+func (c *MemoryCache) Stats() CacheStats {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	stats := CacheStats{Entries: len(c.responses)}
+	for _, resp := range c.responses {
+		stats.Bytes += int64(len(resp.Body))
+	}
+	return c.counters.stats(stats)
+}
+
+// DiskCache is a Cache that keeps every response in a file of a
+// directory, so they can be reused across runs. Errors reading or writing
+// the files are ignored and treated as cache misses.
+type DiskCache struct {
+	counters cacheCounters
+	dir      string
+
+	mut      sync.Mutex
+	maxBytes int64
+	// size of the files in the directory, or -1 if it's unknown.
+	size int64
+}
+
+// diskEntry is the content of a file of a DiskCache.
+type diskEntry struct {
+	URL string `json:"url"`
+	CachedResponse
+}
+
+// NewDiskCache creates a DiskCache in the given directory, creating it if
+// it does not exist.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &DiskCache{dir: dir, size: -1}, nil
+}
+
+// SetMaxBytes limits the size of the files of the cache to the given number
+// of bytes. When the limit is exceeded, the least recently used responses
+// are removed. By default, the size is not limited.
+func (c *DiskCache) SetMaxBytes(n int64) {
+	c.mut.Lock()
+	c.maxBytes = n
+	c.mut.Unlock()
+	c.evict()
+}
+
+// Get implements the Cache interface.
+func (c *DiskCache) Get(url string) (CachedResponse, bool) {
+	path := c.path(url)
+	e, ok := c.read(path)
+	c.counters.record(ok)
+	if ok {
+		// The modification time of the files is the last time they were
+		// used, so the least recently used ones can be found.
+		now := time.Now()
+		_ = os.Chtimes(path, now, now)
+	}
+	return e.CachedResponse, ok
+}
+
+// Set implements the Cache interface.
+func (c *DiskCache) Set(url string, resp CachedResponse) {
+	data, err := json.Marshal(diskEntry{url, resp})
+	if err != nil {
+		return
+	}
+
+	// Write to a temporary file first so concurrent readers never see a
+	// partially written response.
+	f, err := ioutil.TempFile(c.dir, ".tmp")
+	if err != nil {
+		return
+	}
+
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	path := c.path(url)
+	var old int64
+	if fi, err := os.Stat(path); err == nil {
+		old = fi.Size()
+	}
+
+	if err != nil || os.Rename(f.Name(), path) != nil {
+		os.Remove(f.Name())
+		return
+	}
+
+	c.mut.Lock()
+	if c.size >= 0 {
+		c.size += int64(len(data)) - old
+	}
+	c.mut.Unlock()
+	c.evict()
+}
+
+// evict removes the least recently used responses until the size of the
+// cache is below its limit.
+func (c *DiskCache) evict() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.maxBytes <= 0 || (c.size >= 0 && c.size <= c.maxBytes) {
+		return
+	}
+
+	var files []os.FileInfo
+	c.size = 0
+	c.each(func(fi os.FileInfo) {
+		files = append(files, fi)
+		c.size += fi.Size()
+	})
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+
+	for _, fi := range files {
+		if c.size <= c.maxBytes {
+			break
+		}
+
+		if os.Remove(filepath.Join(c.dir, fi.Name())) == nil {
+			c.size -= fi.Size()
+		}
+	}
+}
+
+// Invalidate implements the Cache interface.
+func (c *DiskCache) Invalidate(prefix string) int {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	var n int
+	c.each(func(fi os.FileInfo) {
+		path := filepath.Join(c.dir, fi.Name())
+		if prefix != "" {
+			e, ok := c.read(path)
+			if ok && !strings.HasPrefix(e.URL, prefix) {
+				return
+			}
+		}
+
+		if os.Remove(path) == nil {
+			n++
+		}
+	})
+
+	c.size = -1
+	return n
+}
+
+// Stats implements the Cache interface.
+func (c *DiskCache) Stats() CacheStats {
+	var stats CacheStats
+	c.each(func(fi os.FileInfo) {
+		if e, ok := c.read(filepath.Join(c.dir, fi.Name())); ok {
+			stats.Entries++
+			stats.Bytes += int64(len(e.Body))
+		}
+	})
+	return c.counters.stats(stats)
+}
+
+// each calls fn with every response file in the cache.
+func (c *DiskCache) each(fn func(os.FileInfo)) {
+	files, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+
+	for _, f := range files {
+		if !f.IsDir() && !strings.HasPrefix(f.Name(), ".") {
+			fn(f)
+		}
+	}
+}
+
+func (c *DiskCache) read(path string) (diskEntry, bool) {
+	var e diskEntry
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return e, false
+	}
+
+	if err := json.Unmarshal(data, &e); err != nil {
+		return e, false
+	}
+
+	return e, true
+}
+
+func (c *DiskCache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,230 @@
+package datos
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	disk, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	caches := map[string]Cache{
+		"memory": NewMemoryCache(),
+		"disk":   disk,
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			var requests, notModified int
+			c := &Client{cache: cache, c: &http.Client{
+				Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+					requests++
+					resp := &http.Response{
+						StatusCode: http.StatusOK,
+						Header:     make(http.Header),
+						Body:       ioutil.NopCloser(strings.NewReader(`{"result":{"items":[{"notation":"foo"}]}}`)),
+						Request:    r,
+					}
+					resp.Header.Set("ETag", `"v1"`)
+
+					if r.Header.Get("If-None-Match") == `"v1"` {
+						notModified++
+						resp.StatusCode = http.StatusNotModified
+						resp.Body = ioutil.NopCloser(strings.NewReader(""))
+					}
+					return resp, nil
+				}),
+			}}
+
+			for i := 0; i < 2; i++ {
+				ps, err := c.Publishers(context.Background(), Params{})
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				if len(ps) != 1 || ps[0].Notation != "foo" {
+					t.Errorf("wrong publishers: %v", ps)
+				}
+			}
+
+			if requests != 2 || notModified != 1 {
+				t.Errorf("expected second request to be conditional, requests: %d, not modified: %d", requests, notModified)
+			}
+		})
+	}
+}
+
+func TestCacheDecodingError(t *testing.T) {
+	cache := NewMemoryCache()
+	c := &Client{cache: cache, c: &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     make(http.Header),
+				Body:       ioutil.NopCloser(strings.NewReader("<html></html>")),
+				Request:    r,
+			}
+			resp.Header.Set("Last-Modified", "Mon, 01 Jan 2018 00:00:00 GMT")
+			return resp, nil
+		}),
+	}}
+
+	if _, err := c.Publishers(context.Background(), Params{}); err == nil {
+		t.Fatalf("expected decoding error")
+	}
+
+	if len(cache.responses) != 0 {
+		t.Errorf("expected broken response not to be cached")
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	var requests []string
+	c := &Client{cache: NewMemoryCache(), c: &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			requests = append(requests, r.URL.Path)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     make(http.Header),
+				Body:       ioutil.NopCloser(strings.NewReader(`{"result":{"items":[]}}`)),
+				Request:    r,
+			}, nil
+		}),
+	}}
+	WithCacheTTL(CacheTTL{Taxonomies: time.Hour})(c)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := c.Themes(ctx, Params{}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if _, err := c.Datasets(ctx, Params{}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	expected := []string{"/apidata/catalog/theme", "/apidata/catalog/dataset", "/apidata/catalog/dataset"}
+	if strings.Join(requests, " ") != strings.Join(expected, " ") {
+		t.Errorf("wrong requests, expected: %v, got: %v", expected, requests)
+	}
+
+	if n := c.Cache().Invalidate("/catalog/theme"); n != 1 {
+		t.Errorf("expected 1 response invalidated, got: %d", n)
+	}
+
+	if _, err := c.Themes(ctx, Params{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(requests) != 4 {
+		t.Errorf("expected invalidated response to be requested again")
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	disk, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	caches := map[string]Cache{
+		"memory": NewMemoryCache(),
+		"disk":   disk,
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			cache.Set(defaultBaseURL+"/catalog/theme", CachedResponse{Body: []byte("[1]")})
+			cache.Set(defaultBaseURL+"/catalog/dataset", CachedResponse{Body: []byte("[12]")})
+			cache.Set(defaultBaseURL+"/catalog/dataset?_page=1", CachedResponse{Body: []byte("[123]")})
+
+			if stats := cache.Stats(); stats != (CacheStats{Entries: 3, Bytes: 12}) {
+				t.Errorf("wrong stats: %+v", stats)
+			}
+
+			c := &Client{cache: cache}
+			if n := c.Cache().Invalidate("/catalog/dataset"); n != 2 {
+				t.Errorf("expected 2 responses invalidated, got: %d", n)
+			}
+
+			if _, ok := cache.Get(defaultBaseURL + "/catalog/theme"); !ok {
+				t.Errorf("expected theme response to be kept")
+			}
+
+			if n := c.Cache().Invalidate(""); n != 1 || cache.Stats().Entries != 0 {
+				t.Errorf("expected cache to be cleared, invalidated: %d", n)
+			}
+		})
+	}
+}
+
+func TestDiskCacheMaxBytes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cache, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	body := []byte(strings.Repeat("x", 100))
+	for _, url := range []string{"a", "b", "c"} {
+		cache.Set(url, CachedResponse{Body: body})
+	}
+
+	// Use "a" after "b", so "b" is the least recently used.
+	past := time.Now().Add(-time.Hour)
+	for i, url := range []string{"a", "b", "c"} {
+		mtime := past.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(cache.path(url), mtime, mtime); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	cache.Get("a")
+
+	fi, err := os.Stat(cache.path("a"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cache.SetMaxBytes(2 * fi.Size())
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("expected least recently used response to be removed")
+	}
+
+	cache.Set("d", CachedResponse{Body: body})
+	for _, url := range []string{"a", "d"} {
+		if _, ok := cache.Get(url); !ok {
+			t.Errorf("expected response %q to be kept", url)
+		}
+	}
+
+	stats := cache.Stats()
+	if stats.Entries != 2 || stats.Hits != 3 || stats.Misses != 1 {
+		t.Errorf("wrong stats: %+v", stats)
+	}
+}
@@ -0,0 +1,146 @@
+package datos
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// certMode is the way the client verifies the certificates of servers.
+type certMode byte
+
+const (
+	// bootstrapCerts adds the certificates of the API to the system ones.
+	bootstrapCerts certMode = iota
+	// systemCerts only uses the system certificates.
+	systemCerts
+	// customCerts only uses the certificates given with WithRootCAs.
+	customCerts
+)
+
+// WithRootCAs makes the client verify the certificates of servers with the
+// given pool only, e.g. one with the certificate of a proxy intercepting
+// TLS connections.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(c *Client) {
+		c.certs = customCerts
+		c.rootCAs = pool
+	}
+}
+
+// WithSystemCertsOnly makes the client verify the certificates of servers
+// with the system certificates only, without fetching the ones of the API.
+func WithSystemCertsOnly() Option {
+	return func(c *Client) {
+		c.certs = systemCerts
+		c.rootCAs = nil
+	}
+}
+
+// WithInsecureBootstrap makes the client fetch the certificates of the API
+// without verifying them and add them to the system ones, because the
+// server does not send the whole chain of certificates. They're fetched
+// right before the first request, so the client can be created offline.
+// This is the default unless WithRootCAs or WithSystemCertsOnly are used.
+// If the client sends the requests to another API with WithBaseURL, only
+// the system certificates are used.
+func WithInsecureBootstrap() Option {
+	return func(c *Client) {
+		c.certs = bootstrapCerts
+		c.rootCAs = nil
+	}
+}
+
+// tlsTransport returns the transport of the client with the certificates
+// configured for it.
+func (c *Client) tlsTransport() http.RoundTripper {
+	switch c.certs {
+	case systemCerts:
+		return newTransport(nil)
+	case customCerts:
+		return newTransport(c.rootCAs)
+	default:
+		// Only the certificates of the public API are missing, and the
+		// ones of other servers must not be trusted without verifying.
+		if u, err := url.Parse(c.baseURL()); err != nil || u.Hostname() != apiHost {
+			return newTransport(nil)
+		}
+		return new(bootstrapTransport)
+	}
+}
+
+// newTransport returns a transport like the default one verifying the
+// certificates of servers with the given pool, or the system one if it's
+// nil.
+func newTransport(pool *x509.CertPool) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return t
+}
+
+// apiHost is the host of the public API, whose certificates are fetched.
+const apiHost = "datos.gob.es"
+
+// bootstrapTransport is a transport with the certificates of the API added
+// to the system ones, which are fetched on the first request. If they can't
+// be fetched, they're fetched again on the next request.
+type bootstrapTransport struct {
+	mut sync.Mutex
+	t   http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (b *bootstrapTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	b.mut.Lock()
+	if b.t == nil {
+		t, err := apiTransport()
+		if err != nil {
+			b.mut.Unlock()
+			return nil, err
+		}
+		b.t = t
+	}
+	t := b.t
+	b.mut.Unlock()
+
+	return t.RoundTrip(req)
+}
+
+// apiTransport returns a transport with the certificates required to call
+// the API installed.
+func apiTransport() (*http.Transport, error) {
+	certs, err := getRemoteCertificates()
+	if err != nil {
+		return nil, fmt.Errorf("datos: unable to get certificates: %s", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		return nil, fmt.Errorf("datos: unable to get system cert pool: %s", err)
+	}
+
+	for _, c := range certs {
+		pool.AddCert(c)
+	}
+
+	return newTransport(pool), nil
+}
+
+func getRemoteCertificates() ([]*x509.Certificate, error) {
+	d := &net.Dialer{Timeout: 10 * time.Second}
+	conn, err := tls.DialWithDialer(d, "tcp", net.JoinHostPort(apiHost, "443"), &tls.Config{
+		InsecureSkipVerify: true,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates, nil
+}
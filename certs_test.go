@@ -0,0 +1,71 @@
+package datos
+
+import (
+	"crypto/x509"
+	"net/http"
+	"testing"
+)
+
+func TestCertOptions(t *testing.T) {
+	// No certificates are fetched creating the client, so it can be
+	// created without network access.
+	c, err := NewClient()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, ok := c.c.Transport.(*bootstrapTransport); !ok {
+		t.Errorf("expected bootstrap transport by default, got: %T", c.c.Transport)
+	}
+
+	c, err = NewClient(WithSystemCertsOnly())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tr, ok := c.c.Transport.(*http.Transport)
+	if !ok || tr.TLSClientConfig.RootCAs != nil || tr.Proxy == nil {
+		t.Errorf("expected transport with system certs, got: %#v", c.c.Transport)
+	}
+
+	pool := x509.NewCertPool()
+	c, err = NewClient(WithRootCAs(pool))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tr, ok = c.c.Transport.(*http.Transport)
+	if !ok || tr.TLSClientConfig.RootCAs != pool {
+		t.Errorf("expected transport with the given certs, got: %#v", c.c.Transport)
+	}
+
+	c, err = NewClient(WithRootCAs(pool), WithInsecureBootstrap())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, ok := c.c.Transport.(*bootstrapTransport); !ok {
+		t.Errorf("expected bootstrap transport, got: %T", c.c.Transport)
+	}
+
+	c, err = NewClient(WithBaseURL("https://datos.gob.es/apidata/"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, ok := c.c.Transport.(*bootstrapTransport); !ok {
+		t.Errorf("expected bootstrap transport with the public API, got: %T", c.c.Transport)
+	}
+
+	for _, base := range []string{"https://mirror.example.com/apidata", "http://127.0.0.1:8080", "://"} {
+		c, err = NewClient(WithBaseURL(base))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		tr, ok = c.c.Transport.(*http.Transport)
+		if !ok || tr.TLSClientConfig.RootCAs != nil {
+			t.Errorf("expected transport with system certs with base URL %s, got: %#v", base, c.c.Transport)
+		}
+	}
+}
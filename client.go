@@ -1,68 +1,136 @@
 package datos
 
 import (
-	"crypto/tls"
+	"bytes"
+	"context"
 	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
-	"net"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 )
 
 // Client to query data from the spanish government open data API.
 type Client struct {
-	c *http.Client
+	c          *http.Client
+	base       string
+	timeout    *time.Duration
+	transport  http.RoundTripper
+	certs      certMode
+	rootCAs    *x509.CertPool
+	strict     bool
+	lazy       bool
+	onWarning  func(DecodeWarning)
+	limiter    *rateLimiter
+	cache      Cache
+	cacheTTL   CacheTTL
+	inflight   *flightGroup
+	logger     Logger
+	hooks      []Hooks
+	spatials   spatialCache
+	publishers publisherCache
 }
 
-const baseURL = "https://datos.gob.es/apidata"
+const defaultBaseURL = "https://datos.gob.es/apidata"
 
-func getRemoteCertificates(url string) ([]*x509.Certificate, error) {
-	d := &net.Dialer{Timeout: 10 * time.Second}
-	conn, err := tls.DialWithDialer(d, "tcp", "datos.gob.es:443", &tls.Config{
-		InsecureSkipVerify: true,
-	})
+// Option configures a Client.
+type Option func(*Client)
 
-	if err != nil {
-		return nil, err
+// WithHTTPClient makes the client use the given HTTP client to perform
+// requests. The certificates required to call the API are not installed
+// in it, so it's up to the caller to configure its transport.
+func WithHTTPClient(c *http.Client) Option {
+	return func(client *Client) {
+		client.c = c
 	}
+}
 
-	defer conn.Close()
-	return conn.ConnectionState().PeerCertificates, nil
+// WithTransport makes the client perform requests with the given transport,
+// e.g. a Recorder. If it's used along with WithHTTPClient, a copy of the
+// HTTP client with the transport is used. Certificates are not installed
+// in the transport, so it's up to the caller to configure it.
+func WithTransport(t http.RoundTripper) Option {
+	return func(client *Client) {
+		client.transport = t
+	}
 }
 
-// NewClient creates a new client to query data from the spanish government open data API.
-// It will also install in the client the SSL certificates required to call the API.
-func NewClient() (*Client, error) {
-	certs, err := getRemoteCertificates(baseURL)
-	if err != nil {
-		return nil, fmt.Errorf("datos: unable to get certificates: %s", err)
+// WithTimeout sets the timeout of the requests made by the client. By
+// default it's 10 seconds.
+func WithTimeout(d time.Duration) Option {
+	return func(client *Client) {
+		client.timeout = &d
 	}
+}
 
-	pool, err := x509.SystemCertPool()
-	if err != nil {
-		return nil, fmt.Errorf("datos: unable to get system cert pool: %s", err)
+// WithBaseURL makes the client send the requests to the API at the given
+// URL instead of https://datos.gob.es/apidata, e.g. to a mirror or to a
+// datostest.Server in tests.
+func WithBaseURL(url string) Option {
+	return func(client *Client) {
+		client.base = strings.TrimRight(url, "/")
+	}
+}
+
+// NewClient creates a new client to query data from the spanish government open data API.
+// Unless an HTTP client or a transport is given with WithHTTPClient or WithTransport, the
+// client verifies the certificates of servers as configured with WithRootCAs,
+// WithSystemCertsOnly or WithInsecureBootstrap, which is the default.
+func NewClient(opts ...Option) (*Client, error) {
+	c := &Client{base: defaultBaseURL}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.c == nil {
+		transport := c.transport
+		if transport == nil {
+			transport = c.tlsTransport()
+		}
+
+		c.c = &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: transport,
+		}
+	} else if c.transport != nil {
+		// Copy the given client so it's not modified.
+		hc := *c.c
+		hc.Transport = c.transport
+		c.c = &hc
 	}
 
-	for _, c := range certs {
-		pool.AddCert(c)
+	if len(c.hooks) > 0 {
+		next := c.c.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+
+		hc := *c.c
+		hc.Transport = &hookTransport{next, c.hooks}
+		c.c = &hc
 	}
 
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				RootCAs: pool,
-			},
-		},
+	if c.timeout != nil {
+		// Copy the client, which may be the one given with WithHTTPClient.
+		hc := *c.c
+		hc.Timeout = *c.timeout
+		c.c = &hc
 	}
 
-	return &Client{
-		c: client,
-	}, nil
+	return c, nil
+}
+
+// baseURL returns the URL of the API used by the client.
+func (c *Client) baseURL() string {
+	if c.base == "" {
+		return defaultBaseURL
+	}
+	return c.base
 }
 
 // Params to control the page, page size and order of the results in any API call.
@@ -72,7 +140,7 @@ type Params struct {
 	PageSize uint
 }
 
-func makeURL(path string, params Params) string {
+func makeURL(base, path string, params Params) string {
 	var queryParts []string
 	if params.Sort != "" {
 		queryParts = append(queryParts, fmt.Sprintf("_sort=%s", params.Sort))
@@ -87,36 +155,116 @@ func makeURL(path string, params Params) string {
 	}
 
 	query := strings.Join(queryParts, "&")
-	return fmt.Sprintf("%s%s?%s", baseURL, path, query)
+	return fmt.Sprintf("%s%s?%s", base, path, query)
 }
 
 func (c *Client) get(
+	ctx context.Context,
 	path string,
 	params Params,
 	decodeInto interface{},
 ) error {
-	req, err := http.NewRequest("GET", makeURL(path, params), nil)
+	url := makeURL(c.baseURL(), path, params)
+	fetch := func() ([]byte, error) { return c.fetchURL(ctx, path, url) }
+
+	var bytes []byte
+	var err error
+	if c.inflight != nil {
+		bytes, err = c.inflight.do(ctx, url, fetch)
+	} else {
+		bytes, err = fetch()
+	}
+
 	if err != nil {
-		return fmt.Errorf("datos: unable to create request: %s", err)
+		return err
+	}
+
+	if err := json.Unmarshal(bytes, decodeInto); err != nil {
+		return newError(ErrDecoding, err, "datos: unable to decode JSON response into %T: %s", decodeInto, err)
+	}
+
+	return nil
+}
+
+// fetchURL requests the given API URL and returns the body of the response.
+func (c *Client) fetchURL(ctx context.Context, path, url string) ([]byte, error) {
+	var cached CachedResponse
+	var isCached bool
+	ttl := c.cacheTTL.of(path)
+	if c.cache != nil {
+		cached, isCached = c.cache.Get(url)
+		if isCached && time.Since(cached.Stored) < ttl {
+			c.log(LevelDebug, "using cached response", Fields{"url": url})
+			return cached.Body, nil
+		}
 	}
 
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("datos: unable to create request: %s", err)
+	}
+
+	if isCached && cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+	if isCached && cached.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cached.LastModified)
+	}
+
+	req = req.WithContext(ctx)
 	req.Header.Add("Accept", "application/json")
+	start := time.Now()
 	resp, err := c.c.Do(req)
 	if err != nil {
-		return fmt.Errorf("datos: unable to get data from %q: %s", path, err)
+		c.log(LevelError, "request failed", Fields{"url": url, "error": err})
+		return nil, newError(ErrUpstreamUnavailable, err, "datos: unable to get data from %q: %s", path, err)
 	}
 
+	c.log(LevelDebug, "request", Fields{
+		"url":      url,
+		"status":   resp.StatusCode,
+		"duration": time.Since(start),
+	})
+
 	defer resp.Body.Close()
 	bytes, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("datos: error reading response body: %s", err)
+		return nil, newError(ErrUpstreamUnavailable, err, "datos: error reading response body: %s", err)
 	}
 
-	if err := json.Unmarshal(bytes, decodeInto); err != nil {
-		return fmt.Errorf("datos: unable to decode JSON response into %T: %s", decodeInto, err)
+	if isCached && resp.StatusCode == http.StatusNotModified {
+		if ttl > 0 {
+			cached.Stored = time.Now()
+			c.cache.Set(url, cached)
+		}
+		return cached.Body, nil
 	}
 
-	return nil
+	if resp.StatusCode >= 400 {
+		return nil, newAPIError(resp, bytes)
+	}
+
+	// Only valid JSON responses are stored, so a broken response is not
+	// served again from the cache.
+	if c.cache != nil && json.Valid(bytes) {
+		etag, modified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if etag != "" || modified != "" || ttl > 0 {
+			c.cache.Set(url, CachedResponse{
+				Body:         bytes,
+				ETag:         etag,
+				LastModified: modified,
+				Stored:       time.Now(),
+			})
+		}
+	}
+
+	return bytes, nil
 }
 
 // Publisher is a data publisher.
@@ -128,17 +276,13 @@ type Publisher struct {
 }
 
 // Publishers lists all data publishers.
-func (c *Client) Publishers(params Params) ([]Publisher, error) {
-	var resp struct {
-		Result struct {
-			Items []Publisher `json:"items"`
-		} `json:"result"`
-	}
-	if err := c.get("/catalog/publisher", params, &resp); err != nil {
+func (c *Client) Publishers(ctx context.Context, params Params) ([]Publisher, error) {
+	var result []Publisher
+	if err := c.getItems(ctx, "/catalog/publisher", params, &result); err != nil {
 		return nil, err
 	}
 
-	return resp.Result.Items, nil
+	return result, nil
 }
 
 // Spatial is the data about a country, municipality or province.
@@ -148,22 +292,20 @@ type Spatial struct {
 	Label string `json:"label"`
 	// Country is a link to the country information.
 	Country string `json:"pais"`
+	// Autonomy is a link to the autonomous community of a province.
+	Autonomy string `json:"autonomia"`
 	// Type of the spatial.
 	Type string `json:"type"`
 }
 
 // Spatials returns all spatials.
-func (c *Client) Spatials(params Params) ([]Spatial, error) {
-	var resp struct {
-		Result struct {
-			Items []Spatial `json:"items"`
-		} `json:"result"`
-	}
-	if err := c.get("/catalog/spatial", params, &resp); err != nil {
+func (c *Client) Spatials(ctx context.Context, params Params) ([]Spatial, error) {
+	var result []Spatial
+	if err := c.getItems(ctx, "/catalog/spatial", params, &result); err != nil {
 		return nil, err
 	}
 
-	return resp.Result.Items, nil
+	return result, nil
 }
 
 // Theme of a dataset.
@@ -175,124 +317,133 @@ type Theme struct {
 }
 
 // Themes returns all themes.
-func (c *Client) Themes(params Params) ([]Theme, error) {
-	var resp struct {
-		Result struct {
-			Items []Theme `json:"items"`
-		} `json:"result"`
-	}
-	if err := c.get("/catalog/theme", params, &resp); err != nil {
+func (c *Client) Themes(ctx context.Context, params Params) ([]Theme, error) {
+	var result []Theme
+	if err := c.getItems(ctx, "/catalog/theme", params, &result); err != nil {
 		return nil, err
 	}
 
-	return resp.Result.Items, nil
+	return result, nil
 }
 
 // Dataset data.
 type Dataset struct {
 	// About contains a link to the information about this object.
-	About       string   `json:"_about"`
-	Modified    Datetime `json:"modified"`
-	Description []struct {
-		Text string `json:"text"`
-		Lang string `json:"lang"`
-	} `json:"description"`
-	Distribution       Distributions `json:"distribution"`
+	About        string        `json:"_about"`
+	Modified     Datetime      `json:"modified"`
+	Description  LangStrings   `json:"description"`
+	Distribution Distributions `json:"distribution"`
+	// Identifier is the identifier of the dataset. If it has several,
+	// it's the first one and all of them are in Identifiers.
 	Identifier         string        `json:"identifier"`
+	Identifiers        []string      `json:"-"`
 	Keywords           Strings       `json:"keyword"`
 	Language           string        `json:"language"`
-	License            string        `json:"license"`
+	License            License       `json:"license"`
 	Publisher          string        `json:"publisher"`
 	References         Strings       `json:"references"`
 	Spatial            Strings       `json:"spatial"`
-	Temporal           string        `json:"temporal"`
+	Temporal           PeriodOfTime  `json:"temporal"`
 	Theme              Strings       `json:"theme"`
-	Title              Strings       `json:"title"`
-	AccrualPeriodicity string        `json:"accrualPeriodicity"`
-	ConformsTo         string        `json:"conformsTo"`
+	Title              LangStrings   `json:"title"`
+	AccrualPeriodicity Frequency     `json:"accrualPeriodicity"`
+	ConformsTo         Strings       `json:"conformsTo"`
 	Issued             Datetime      `json:"issued"`
 	Valid              Datetime      `json:"valid"`
+	ContactPoint       ContactPoints `json:"contactPoint"`
+	LandingPage        Strings       `json:"landingPage"`
+	Page               Strings       `json:"page"`
+	AccessRights       string        `json:"accessRights"`
+	Provenance         string        `json:"provenance"`
+	Version            string        `json:"version"`
+	HasVersion         Strings       `json:"hasVersion"`
+	IsVersionOf        Strings       `json:"isVersionOf"`
+	Source             Strings       `json:"source"`
+	// Extra contains the keys of the dataset that are not fields of it,
+	// such as extensions of the portal, with their undecoded values.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
-type datasetResp struct {
-	Result struct {
-		Items []Dataset `json:"items"`
-	} `json:"result"`
+// UnmarshalJSON decodes a dataset, keeping the keys that are not fields of
+// the dataset in Extra.
+func (d *Dataset) UnmarshalJSON(b []byte) error {
+	*d = Dataset{}
+	return decodeDataset(b, d, false)
 }
 
 // Datasets returns all datasets.
-func (c *Client) Datasets(params Params) ([]Dataset, error) {
-	var resp datasetResp
-	if err := c.get("/catalog/dataset", params, &resp); err != nil {
+func (c *Client) Datasets(ctx context.Context, params Params) ([]Dataset, error) {
+	var result []Dataset
+	if err := c.getItems(ctx, "/catalog/dataset", params, &result); err != nil {
 		return nil, err
 	}
 
-	return resp.Result.Items, nil
+	return result, nil
 }
 
 // Dataset returns the dataset with the given ID.
 // FIXME: this endpoint doesn't seem to work.
-func (c *Client) Dataset(id string, params Params) (Dataset, error) {
-	var resp datasetResp
-	if err := c.get("/catalog/dataset/"+url.PathEscape(id), params, &resp); err != nil {
+func (c *Client) Dataset(ctx context.Context, id string, params Params) (Dataset, error) {
+	var result []Dataset
+	if err := c.getItems(ctx, "/catalog/dataset/"+url.PathEscape(id), params, &result); err != nil {
 		return Dataset{}, err
 	}
 
-	if len(resp.Result.Items) > 0 {
-		return resp.Result.Items[0], nil
+	if len(result) > 0 {
+		return result[0], nil
 	}
 
-	return Dataset{}, fmt.Errorf("datos: dataset not found with id %q", id)
+	return Dataset{}, newError(ErrNotFound, nil, "datos: dataset not found with id %q", id)
 }
 
 // DatasetsByTitle returns the datasets matching the given title.
-func (c *Client) DatasetsByTitle(title string, params Params) ([]Dataset, error) {
-	var resp datasetResp
-	if err := c.get("/catalog/dataset/title/"+url.PathEscape(title), params, &resp); err != nil {
+func (c *Client) DatasetsByTitle(ctx context.Context, title string, params Params) ([]Dataset, error) {
+	var result []Dataset
+	if err := c.getItems(ctx, "/catalog/dataset/title/"+url.PathEscape(title), params, &result); err != nil {
 		return nil, err
 	}
 
-	return resp.Result.Items, nil
+	return result, nil
 }
 
 // DatasetsByPublisher returns the datasets with the given publisher ID.
-func (c *Client) DatasetsByPublisher(publisherID string, params Params) ([]Dataset, error) {
-	var resp datasetResp
-	if err := c.get("/catalog/dataset/publisher/"+url.PathEscape(publisherID), params, &resp); err != nil {
+func (c *Client) DatasetsByPublisher(ctx context.Context, publisherID string, params Params) ([]Dataset, error) {
+	var result []Dataset
+	if err := c.getItems(ctx, "/catalog/dataset/publisher/"+url.PathEscape(publisherID), params, &result); err != nil {
 		return nil, err
 	}
 
-	return resp.Result.Items, nil
+	return result, nil
 }
 
 // DatasetsByTheme returns the datasets with the given theme ID.
-func (c *Client) DatasetsByTheme(themeID string, params Params) ([]Dataset, error) {
-	var resp datasetResp
-	if err := c.get("/catalog/dataset/theme/"+url.PathEscape(themeID), params, &resp); err != nil {
+func (c *Client) DatasetsByTheme(ctx context.Context, themeID string, params Params) ([]Dataset, error) {
+	var result []Dataset
+	if err := c.getItems(ctx, "/catalog/dataset/theme/"+url.PathEscape(themeID), params, &result); err != nil {
 		return nil, err
 	}
 
-	return resp.Result.Items, nil
+	return result, nil
 }
 
 // DatasetsByFormat returns the datasets with the given format.
-func (c *Client) DatasetsByFormat(format string, params Params) ([]Dataset, error) {
-	var resp datasetResp
-	if err := c.get("/catalog/dataset/format/"+url.PathEscape(format), params, &resp); err != nil {
+func (c *Client) DatasetsByFormat(ctx context.Context, format string, params Params) ([]Dataset, error) {
+	var result []Dataset
+	if err := c.getItems(ctx, "/catalog/dataset/format/"+url.PathEscape(format), params, &result); err != nil {
 		return nil, err
 	}
 
-	return resp.Result.Items, nil
+	return result, nil
 }
 
 // DatasetsByKeyword returns the datasets with the given keyword.
-func (c *Client) DatasetsByKeyword(keyword string, params Params) ([]Dataset, error) {
-	var resp datasetResp
-	if err := c.get("/catalog/dataset/keyword/"+url.PathEscape(keyword), params, &resp); err != nil {
+func (c *Client) DatasetsByKeyword(ctx context.Context, keyword string, params Params) ([]Dataset, error) {
+	var result []Dataset
+	if err := c.getItems(ctx, "/catalog/dataset/keyword/"+url.PathEscape(keyword), params, &result); err != nil {
 		return nil, err
 	}
 
-	return resp.Result.Items, nil
+	return result, nil
 }
 
 // SpatialType is the type of a spatial.
@@ -320,38 +471,50 @@ func (t SpatialType) String() string {
 	}
 }
 
-// DatasetsBySpatial returns the datasets with the given spatial.
-func (c *Client) DatasetsBySpatial(typ SpatialType, spatial string, params Params) ([]Dataset, error) {
-	var resp datasetResp
-	err := c.get(
+// DatasetsBySpatial returns the datasets with the given spatial. The
+// spatial can be given by its identifier, label or any of the names
+// accepted by ResolveSpatial. ErrNotFound is returned if the spatial
+// does not exist.
+func (c *Client) DatasetsBySpatial(ctx context.Context, typ SpatialType, spatial string, params Params) ([]Dataset, error) {
+	s, err := c.ResolveSpatial(ctx, typ, spatial)
+	if err == nil {
+		spatial = s.ID()
+	} else if errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	var result []Dataset
+	err = c.getItems(
+		ctx,
 		fmt.Sprintf("/catalog/dataset/spatial/%s/%s", typ, url.PathEscape(spatial)),
 		params,
-		&resp,
+		&result,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	return resp.Result.Items, nil
+	return result, nil
 }
 
 // DatasetsModifiedBetween returns the datasets modified between the given date range.
-func (c *Client) DatasetsModifiedBetween(from, to time.Time, params Params) ([]Dataset, error) {
-	var resp datasetResp
-	err := c.get(
-		fmt.Sprintf(
-			"/catalog/dataset/modified/begin/%s/end/%s",
-			from.Format(time.RFC3339),
-			to.Format(time.RFC3339),
-		),
-		params,
-		&resp,
-	)
-	if err != nil {
+func (c *Client) DatasetsModifiedBetween(ctx context.Context, from, to time.Time, params Params) ([]Dataset, error) {
+	var result []Dataset
+	if err := c.getItems(ctx, dateRangePath("modified", from, to), params, &result); err != nil {
 		return nil, err
 	}
 
-	return resp.Result.Items, nil
+	return result, nil
+}
+
+// DatasetsIssuedBetween returns the datasets issued between the given date range.
+func (c *Client) DatasetsIssuedBetween(ctx context.Context, from, to time.Time, params Params) ([]Dataset, error) {
+	var result []Dataset
+	if err := c.getItems(ctx, dateRangePath("issued", from, to), params, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
 
 // Distribution of a dataset.
@@ -370,55 +533,53 @@ type Distribution struct {
 	Identifier string  `json:"identifier"`
 }
 
-type distributionResp struct {
-	Result struct {
-		Items []Distribution `json:"items"`
-	} `json:"result"`
-}
-
 // Distributions returns all distributions.
-func (c *Client) Distributions(params Params) ([]Distribution, error) {
-	var resp distributionResp
-	if err := c.get("/catalog/distribution", params, &resp); err != nil {
+func (c *Client) Distributions(ctx context.Context, params Params) ([]Distribution, error) {
+	var result []Distribution
+	if err := c.getItems(ctx, "/catalog/distribution", params, &result); err != nil {
 		return nil, err
 	}
 
-	return resp.Result.Items, nil
+	return result, nil
 }
 
 // DistributionsByDataset returns all distributions of a dataset.
 // FIXME: this endpoint doesn't seem to work.
-func (c *Client) DistributionsByDataset(datasetID string, params Params) ([]Distribution, error) {
-	var resp distributionResp
-	if err := c.get(
+func (c *Client) DistributionsByDataset(ctx context.Context, datasetID string, params Params) ([]Distribution, error) {
+	var result []Distribution
+	if err := c.getItems(
+		ctx,
 		"/catalog/distribution/dataset/"+url.PathEscape(datasetID),
 		params,
-		&resp,
+		&result,
 	); err != nil {
 		return nil, err
 	}
 
-	return resp.Result.Items, nil
+	return result, nil
 }
 
 // DistributionsByFormat returns all distributions with the given format.
-func (c *Client) DistributionsByFormat(format string, params Params) ([]Distribution, error) {
-	var resp distributionResp
-	if err := c.get(
+func (c *Client) DistributionsByFormat(ctx context.Context, format string, params Params) ([]Distribution, error) {
+	var result []Distribution
+	if err := c.getItems(
+		ctx,
 		"/catalog/distribution/format/"+url.PathEscape(format),
 		params,
-		&resp,
+		&result,
 	); err != nil {
 		return nil, err
 	}
 
-	return resp.Result.Items, nil
+	return result, nil
 }
 
 // Strings is a slice with zero or more strings.
 type Strings []string
 
-// UnmarshalJSON decodes either a single string or a list of them.
+// UnmarshalJSON decodes either a single value or a list of them. Values can
+// be strings or objects with the value in the _about or _value keys, as
+// links and labels are returned by the API. Null decodes to no values.
 func (s *Strings) UnmarshalJSON(b []byte) error {
 	var val interface{}
 	err := json.Unmarshal(b, &val)
@@ -426,37 +587,84 @@ func (s *Strings) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
+	var values []interface{}
 	switch v := val.(type) {
-	case string:
-		*s = append(*s, v)
+	case nil:
+		return nil
 	case []interface{}:
-		for _, elem := range v {
-			str, ok := elem.(string)
-			if !ok {
-				return fmt.Errorf("expecting string, got %T", elem)
-			}
+		values = v
+	default:
+		values = []interface{}{v}
+	}
+
+	for _, elem := range values {
+		str, err := stringValue(elem)
+		if err != nil {
+			return err
+		}
+
+		if str != "" {
 			*s = append(*s, str)
 		}
-	default:
-		return fmt.Errorf("expecting string or string array, got %T", val)
 	}
 
 	return nil
 }
 
+// stringValue returns the string of a value of a Strings field.
+func stringValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case map[string]interface{}:
+		for _, k := range []string{"_about", "_value", "value"} {
+			if str, ok := v[k].(string); ok {
+				return str, nil
+			}
+		}
+		return "", fmt.Errorf("expecting object with _about or _value, got keys %s", strings.Join(objectKeys(v), ", "))
+	default:
+		return "", fmt.Errorf("expecting string, object or array, got %T", v)
+	}
+}
+
+func objectKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // Distributions is a slice of distributions.
 type Distributions []Distribution
 
 // UnmarshalJSON decodes either a single distribution or a list of them.
+// Null decodes to no distributions, and so do null items of the list.
 func (s *Distributions) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return fmt.Errorf("error decoding distributions, expecting array or object")
+	}
+
 	switch c := b[0]; c {
+	case 'n':
+		var v interface{}
+		return json.Unmarshal(b, &v)
 	case '[':
-		var ds []Distribution
+		var ds []*Distribution
 		if err := json.Unmarshal(b, &ds); err != nil {
 			return err
 		}
 
-		*s = append(*s, ds...)
+		for _, d := range ds {
+			if d != nil {
+				*s = append(*s, *d)
+			}
+		}
 	case '{':
 		var d Distribution
 		if err := json.Unmarshal(b, &d); err != nil {
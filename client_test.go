@@ -1,15 +1,20 @@
-package datos
+package datos_test
 
 import (
+	"context"
+	"os"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/erizocosmico/datos"
+	"github.com/erizocosmico/datos/datostest"
 )
 
-var datasetParams = Params{PageSize: 10, Sort: "-issued"}
+var datasetParams = datos.Params{PageSize: 10, Sort: "-issued"}
 
 func TestDatasets(t *testing.T) {
-	ds, err := newClient(t).Datasets(datasetParams)
+	ds, err := newClient(t).Datasets(context.Background(), datasetParams)
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
@@ -20,11 +25,19 @@ func TestDatasets(t *testing.T) {
 }
 
 func TestDataset(t *testing.T) {
-	t.Skip("endpoint does not work")
+	id := "a02002834-centros-de-salud"
+	d, err := newClient(t).Dataset(context.Background(), id, datos.Params{})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if !strings.HasSuffix(d.About, "/"+id) {
+		t.Errorf("wrong dataset, expected: %s, got: %s", id, d.About)
+	}
 }
 
 func TestDatasetsByTitle(t *testing.T) {
-	ds, err := newClient(t).DatasetsByTitle("mirador", datasetParams)
+	ds, err := newClient(t).DatasetsByTitle(context.Background(), "mirador", datasetParams)
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
@@ -36,7 +49,7 @@ func TestDatasetsByTitle(t *testing.T) {
 	for _, d := range ds {
 		var found bool
 		for _, t := range d.Title {
-			if strings.Contains(strings.ToLower(t), "mirador") {
+			if strings.Contains(strings.ToLower(t.Text), "mirador") {
 				found = true
 			}
 		}
@@ -44,7 +57,7 @@ func TestDatasetsByTitle(t *testing.T) {
 		if !found {
 			t.Errorf(
 				"expected title %q to contain %q",
-				strings.Join([]string(d.Title), ", "),
+				strings.Join(d.Title.Texts(), ", "),
 				"mirador",
 			)
 		}
@@ -53,7 +66,7 @@ func TestDatasetsByTitle(t *testing.T) {
 
 func TestDatasetsByPublisher(t *testing.T) {
 	pub := "L01280066"
-	ds, err := newClient(t).DatasetsByPublisher(pub, datasetParams)
+	ds, err := newClient(t).DatasetsByPublisher(context.Background(), pub, datasetParams)
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
@@ -70,7 +83,7 @@ func TestDatasetsByPublisher(t *testing.T) {
 }
 
 func TestDatasetsByTheme(t *testing.T) {
-	ds, err := newClient(t).DatasetsByTheme("sector-publico", datasetParams)
+	ds, err := newClient(t).DatasetsByTheme(context.Background(), "sector-publico", datasetParams)
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
@@ -98,7 +111,7 @@ func TestDatasetsByTheme(t *testing.T) {
 }
 
 func TestDatasetsByFormat(t *testing.T) {
-	ds, err := newClient(t).DatasetsByFormat("csv", datasetParams)
+	ds, err := newClient(t).DatasetsByFormat(context.Background(), "csv", datasetParams)
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
@@ -124,7 +137,7 @@ func TestDatasetsByFormat(t *testing.T) {
 }
 
 func TestDatasetsByKeyword(t *testing.T) {
-	ds, err := newClient(t).DatasetsByKeyword("turismo", datasetParams)
+	ds, err := newClient(t).DatasetsByKeyword(context.Background(), "turismo", datasetParams)
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
@@ -152,7 +165,7 @@ func TestDatasetsByKeyword(t *testing.T) {
 }
 
 func TestDatasetsBySpatial(t *testing.T) {
-	ds, err := newClient(t).DatasetsBySpatial(Autonomy, "Aragon", datasetParams)
+	ds, err := newClient(t).DatasetsBySpatial(context.Background(), datos.Autonomy, "Aragon", datasetParams)
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
@@ -182,7 +195,7 @@ func TestDatasetsBySpatial(t *testing.T) {
 func TestDatasetsModifiedBetween(t *testing.T) {
 	from := time.Date(2016, time.April, 18, 0, 0, 0, 0, time.UTC)
 	to := time.Date(2016, time.June, 30, 0, 0, 0, 0, time.UTC)
-	ds, err := newClient(t).DatasetsModifiedBetween(from, to, datasetParams)
+	ds, err := newClient(t).DatasetsModifiedBetween(context.Background(), from, to, datasetParams)
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
@@ -202,8 +215,31 @@ func TestDatasetsModifiedBetween(t *testing.T) {
 	}
 }
 
+func TestDatasetsIssuedBetween(t *testing.T) {
+	from := time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2016, time.June, 30, 0, 0, 0, 0, time.UTC)
+	ds, err := newClient(t).DatasetsIssuedBetween(context.Background(), from, to, datasetParams)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if len(ds) == 0 {
+		t.Errorf("expecting results, got none")
+	}
+
+	for _, d := range ds {
+		if !d.Issued.After(from) || !d.Issued.Before(to) {
+			t.Errorf(
+				"expected issued to be between %s and %s, got: %s",
+				from, to,
+				d.Issued,
+			)
+		}
+	}
+}
+
 func TestDistributions(t *testing.T) {
-	ds, err := newClient(t).Distributions(Params{PageSize: 10})
+	ds, err := newClient(t).Distributions(context.Background(), datos.Params{PageSize: 10})
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
@@ -214,7 +250,7 @@ func TestDistributions(t *testing.T) {
 }
 
 func TestDistributionsByFormat(t *testing.T) {
-	ds, err := newClient(t).DistributionsByFormat("csv", Params{PageSize: 10})
+	ds, err := newClient(t).DistributionsByFormat(context.Background(), "csv", datos.Params{PageSize: 10})
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
@@ -231,11 +267,25 @@ func TestDistributionsByFormat(t *testing.T) {
 }
 
 func TestDistributionsByDataset(t *testing.T) {
-	t.Skip("endpoint does not work")
+	id := "a02002834-centros-de-salud"
+	ds, err := newClient(t).DistributionsByDataset(context.Background(), id, datos.Params{})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if len(ds) == 0 {
+		t.Errorf("expecting results, got none")
+	}
+
+	for _, d := range ds {
+		if !strings.Contains(d.About, "/"+id+"/") {
+			t.Errorf("wrong distribution of dataset %s: %s", id, d.About)
+		}
+	}
 }
 
 func TestPublishers(t *testing.T) {
-	ps, err := newClient(t).Publishers(Params{PageSize: 10})
+	ps, err := newClient(t).Publishers(context.Background(), datos.Params{PageSize: 10})
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
@@ -246,7 +296,7 @@ func TestPublishers(t *testing.T) {
 }
 
 func TestSpatials(t *testing.T) {
-	ps, err := newClient(t).Spatials(Params{PageSize: 10})
+	ps, err := newClient(t).Spatials(context.Background(), datos.Params{PageSize: 10})
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
@@ -257,7 +307,7 @@ func TestSpatials(t *testing.T) {
 }
 
 func TestThemes(t *testing.T) {
-	ps, err := newClient(t).Themes(Params{PageSize: 10})
+	ps, err := newClient(t).Themes(context.Background(), datos.Params{PageSize: 10})
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
@@ -267,9 +317,18 @@ func TestThemes(t *testing.T) {
 	}
 }
 
-func newClient(t *testing.T) *Client {
+var server *datostest.Server
+
+func TestMain(m *testing.M) {
+	server = datostest.NewServer()
+	code := m.Run()
+	server.Close()
+	os.Exit(code)
+}
+
+func newClient(t *testing.T) *datos.Client {
 	t.Helper()
-	c, err := NewClient()
+	c, err := server.NewClient()
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
 	}
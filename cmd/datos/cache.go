@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/erizocosmico/datos"
+)
+
+// cache clears the cache of API responses or prints its statistics.
+func cache(args []string) {
+	var dir string
+
+	flags := flag.NewFlagSet("cache", flag.ExitOnError)
+	flags.StringVar(&dir, "cache", "", "folder of the cache of API responses")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: datos cache -cache <folder> clear|stats")
+		flags.PrintDefaults()
+	}
+	check(flags.Parse(args))
+
+	if dir == "" || flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	c, err := datos.NewDiskCache(dir)
+	check(err)
+
+	switch flags.Arg(0) {
+	case "clear":
+		fmt.Printf("removed %d cached response(s)\n", c.Invalidate(""))
+	case "stats":
+		stats := c.Stats()
+		fmt.Printf("%d cached response(s), %d bytes\n", stats.Entries, stats.Bytes)
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/erizocosmico/datos/app"
+	"github.com/sirupsen/logrus"
+)
+
+// digest renders the datasets published or updated recently that match
+// some saved queries as an HTML email.
+func digest(args []string) {
+	var queriesFile, output, smtpAddr, from, to, reportFile string
+	var period time.Duration
+	var cc clientConfig
+
+	flags := flag.NewFlagSet("digest", flag.ExitOnError)
+	flags.StringVar(&queriesFile, "queries", "", `JSON file with the saved queries, e.g. [{"name": "Salud", "theme": "salud"}]`)
+	flags.DurationVar(&period, "period", 7*24*time.Hour, "include the datasets published or updated in this period of time")
+	flags.StringVar(&output, "o", "", "file to write the HTML digest to, by default it's written to stdout unless it's sent by email")
+	flags.StringVar(&smtpAddr, "smtp", "", "SMTP server to send the digest with, e.g. smtp.example.com:587; the user and password are read from DATOS_SMTP_USER and DATOS_SMTP_PASSWORD")
+	flags.StringVar(&from, "from", "", "sender of the digest email")
+	flags.StringVar(&to, "to", "", "comma separated recipients of the digest email")
+	reportFileFlag(flags, &reportFile)
+	clientFlags(flags, &cc)
+	check(flags.Parse(args))
+
+	if queriesFile == "" {
+		logrus.Fatal("a file with the queries must be provided with -queries")
+	}
+
+	if smtpAddr != "" && (from == "" || to == "") {
+		logrus.Fatal("-from and -to are required to send the digest by email")
+	}
+
+	queries, err := app.ReadDigestQueries(queriesFile)
+	check(err)
+
+	now := time.Now()
+	outcome := app.Outcome{Command: "digest", Started: now}
+	d, err := app.BuildDigest(context.Background(), newClient(cc), queries, now.Add(-period), now)
+	if err != nil {
+		writeOutcome(reportFile, outcome, err)
+		check(err)
+	}
+	outcome.Found = d.Datasets()
+
+	if output != "" || smtpAddr == "" {
+		w := os.Stdout
+		if output != "" {
+			f, err := os.Create(output)
+			check(err)
+			defer f.Close()
+			w = f
+		}
+		err = d.WriteHTML(w)
+	}
+
+	if err == nil && smtpAddr != "" {
+		var auth smtp.Auth
+		if user := os.Getenv("DATOS_SMTP_USER"); user != "" {
+			host, _, err := net.SplitHostPort(smtpAddr)
+			check(err)
+			auth = smtp.PlainAuth("", user, os.Getenv("DATOS_SMTP_PASSWORD"), host)
+		}
+
+		if err = d.Send(smtpAddr, auth, from, splitList(to)); err == nil {
+			logrus.Infof("sent digest with %d dataset(s) to %s", d.Datasets(), to)
+		}
+	}
+
+	writeOutcome(reportFile, outcome, err)
+	check(err)
+}
+
+// splitList splits a comma separated list, ignoring empty items.
+func splitList(s string) []string {
+	var result []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/erizocosmico/datos/app"
+	"github.com/sirupsen/logrus"
+)
+
+// download finds the datasets matching the filters and downloads them.
+func download(args []string) {
+	runDownload("download", args)
+}
+
+// sync downloads the datasets matching the filters that were modified
+// since they were recorded in the manifest of the output directory.
+func sync(args []string) {
+	runDownload("sync", args)
+}
+
+// sample downloads a random sample of the datasets matching the filters.
+func sample(args []string) {
+	runDownload("sample", args)
+}
+
+func runDownload(cmd string, args []string) {
+	var config app.Config
+	var num, sample, concurrency, retries uint
+	var cc clientConfig
+	var bandwidth float64
+	var reportFile string
+	var dryRun bool
+	var headSamples uint
+
+	flags := flag.NewFlagSet(cmd, flag.ExitOnError)
+	filterFlags(flags, &config, &num)
+	if cmd == "sample" {
+		flags.StringVar(&config.StratifyBy, "stratify", "", "keep the proportion of datasets of every theme or publisher in the sample")
+	}
+	flags.StringVar(&config.Output, "o", "", "folder to store the datasets")
+	flags.BoolVar(&config.OnlyNewer, "newer", false, "skip datasets not modified since the local copy was downloaded")
+	flags.BoolVar(&config.FlattenJSON, "flatten-json", false, "convert downloaded JSON files with a list of records to CSV")
+	flags.StringVar(&config.JSONPath, "json-path", "", "dot separated path of the list of records in JSON files, by default it's guessed")
+	flags.StringVar(&config.ConvertXML, "convert-xml", "", "convert downloaded XML files to the given format (csv or json)")
+	flags.StringVar(&config.XMLPath, "xml-path", "", "path of the records in XML files, using a subset of XPath (e.g. /root/item or //item), by default the children of the root element")
+	flags.BoolVar(&config.GeoJSON, "geojson", false, "convert downloaded KML files and zipped shapefiles to GeoJSON, without reprojecting coordinates")
+	flags.BoolVar(&config.Normalize, "normalize", false, "convert downloaded CSV files to comma separated values with dot decimals and ISO 8601 dates")
+	flags.StringVar(&config.ColumnRules, "columns", "", "file with rules to rename and coerce the columns of downloaded CSV files")
+	flags.StringVar(&config.Redact, "redact", "", "comma separated list of patterns (dni, nie, phone, email, iban or a regexp) whose matching columns will be removed from downloaded CSV files")
+	flags.BoolVar(&config.Feather, "feather", false, "write a Feather file with inferred column types next to every downloaded CSV file, to be read with pandas.read_feather")
+	flags.BoolVar(&config.Readr, "readr", false, "write a UTF-8 CSV file and a JSON file with its column types next to every downloaded CSV file, to be read with R's readr")
+	flags.StringVar(&config.PIIReport, "pii-report", "", "scan downloaded CSV files for personal data and write the findings to the given file")
+	flags.Int64Var(&config.Seed, "seed", 0, "seed of the random sampling of datasets and rows, for reproducible runs")
+	flags.UintVar(&sample, "sample", 0, "keep only a random sample of the given number of rows of downloaded CSV files")
+	flags.UintVar(&concurrency, "j", 4, "number of datasets to download at the same time")
+	flags.UintVar(&retries, "retries", 2, "number of times a failed download is retried")
+	flags.DurationVar(&config.ConnectTimeout, "connect-timeout", 15*time.Second, "maximum time until a server starts sending a file, 0 means no limit")
+	flags.DurationVar(&config.IdleTimeout, "idle-timeout", 30*time.Second, "maximum time without receiving data of a file, 0 means no limit")
+	flags.DurationVar(&config.Timeout, "timeout", 0, "maximum time to download a file, 0 means no limit")
+	flags.DurationVar(&config.TimeoutPerMB, "timeout-per-mb", 0, "time added to -timeout for every megabyte of a file")
+	flags.BoolVar(&config.Stamp, "stamp", false, "set the modification time of downloaded files to the dataset's and store their source URL in extended attributes")
+	flags.BoolVar(&config.Sync, "fsync", false, "flush downloaded files to disk before closing them, useful on network filesystems")
+	flags.BoolVar(&dryRun, "dry-run", false, "print the datasets that would be downloaded and their estimated size and download time without downloading them")
+	flags.UintVar(&headSamples, "head-samples", 20, "with -dry-run, maximum number of datasets of unknown size whose size is requested to their servers")
+	flags.Float64Var(&bandwidth, "bandwidth", 1, "with -dry-run, expected download speed of every connection in MB/s")
+	reportFileFlag(flags, &reportFile)
+
+	clientFlags(flags, &cc)
+	check(flags.Parse(args))
+
+	if cmd == "sample" {
+		if num == 0 {
+			logrus.Fatal("the number of datasets to sample must be given with -n")
+		}
+		config.SampleDatasets = int(num)
+	} else {
+		config.Max = int(num)
+	}
+	config.Sample = int(sample)
+	config.OnlyUpdated = cmd == "sync"
+	config.Concurrency = int(concurrency)
+	config.Retries = int(retries)
+
+	var err error
+	if config.Output == "" {
+		config.Output, err = os.Getwd()
+		check(err)
+	} else {
+		config.Output, err = filepath.Abs(config.Output)
+		check(err)
+	}
+
+	client := newClient(cc)
+	a, err := app.New(client, config)
+	check(err)
+
+	if dryRun {
+		datasets, err := a.Plan(context.Background())
+		check(err)
+		check(app.WritePlan(os.Stdout, datasets))
+		estimate, err := a.Estimate(context.Background(), datasets, app.EstimateOptions{
+			Samples:   int(headSamples),
+			Bandwidth: bandwidth * 1024 * 1024,
+		})
+		check(err)
+		check(app.WriteEstimate(os.Stdout, estimate))
+		return
+	}
+
+	err = a.Run(context.Background())
+	if config.Verbose && cc.cacheDir != "" {
+		stats := client.Cache().Stats()
+		logrus.Infof("cache: %d hits, %d misses", stats.Hits, stats.Misses)
+	}
+
+	writeOutcome(reportFile, a.Outcome(), err)
+	check(err)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/erizocosmico/datos"
+	"github.com/erizocosmico/datos/app"
+)
+
+func features(args []string) {
+	flags := flag.NewFlagSet("features", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: datos features")
+		flags.PrintDefaults()
+	}
+	check(flags.Parse(args))
+
+	fmt.Printf("%s %s/%s\n\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, f := range app.Features() {
+		status := "yes"
+		if !f.Enabled {
+			status = "no"
+			if f.Requires != "" {
+				status = "no, requires " + f.Requires
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", f.Name, status, f.Description)
+	}
+	check(tw.Flush())
+
+	fmt.Println()
+	fmt.Printf("cache backends: %s\n", strings.Join(datos.CacheBackends(), ", "))
+	fmt.Printf("catalog backends: %s\n", strings.Join(datos.CatalogBackends(), ", "))
+	fmt.Printf("notifiers: %s\n", strings.Join(app.NotifierBackends(), ", "))
+}
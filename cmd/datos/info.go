@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/erizocosmico/datos"
+)
+
+// info prints the metadata of a dataset.
+func info(args []string) {
+	var cc clientConfig
+
+	flags := flag.NewFlagSet("info", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: datos info [flags] <dataset id>")
+		flags.PrintDefaults()
+	}
+	clientFlags(flags, &cc)
+	check(flags.Parse(args))
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	ds, err := newClient(cc).Dataset(context.Background(), flags.Arg(0), datos.Params{})
+	check(err)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fields := []struct{ name, value string }{
+		{"Title", strings.Join(ds.Title.Texts(), " / ")},
+		{"Identifier", ds.Identifier},
+		{"Page", ds.PortalURL()},
+		{"Publisher", ds.Publisher},
+		{"Themes", strings.Join(ds.Theme, ", ")},
+		{"Keywords", strings.Join(ds.Keywords, ", ")},
+		{"Spatial", strings.Join(ds.Spatial, ", ")},
+		{"License", licenseName(ds.License)},
+		{"Issued", formatTime(ds.Issued)},
+		{"Modified", formatTime(ds.Modified)},
+		{"Frequency", frequencyName(ds.AccrualPeriodicity)},
+		{"Temporal", periodName(ds.Temporal)},
+	}
+
+	for _, f := range fields {
+		if f.value != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", f.name, f.value)
+		}
+	}
+
+	for _, d := range ds.Distribution {
+		fmt.Fprintf(tw, "Distribution:\t%s\t%s\n", d.Format.Value, d.AccessURL)
+	}
+	check(tw.Flush())
+}
+
+// licenseName returns the name of a known license along with its link, or
+// just the link otherwise.
+func licenseName(l datos.License) string {
+	if l.Known() {
+		return fmt.Sprintf("%s (%s)", l.Name(), l)
+	}
+	return string(l)
+}
+
+// frequencyName returns the known periodicity of the frequency, or its
+// duration or link otherwise.
+func frequencyName(f datos.Frequency) string {
+	if p := f.Periodicity(); p != "" {
+		return string(p)
+	}
+	return f.String()
+}
+
+// periodName returns the bounds of the period, or its description if it
+// has none.
+func periodName(p datos.PeriodOfTime) string {
+	if p.Start.IsZero() && p.End.IsZero() {
+		return p.About
+	}
+
+	var start, end string
+	if !p.Start.IsZero() {
+		start = p.Start.Format("2006-01-02")
+	}
+	if !p.End.IsZero() {
+		end = p.End.Format("2006-01-02")
+	}
+	return start + " - " + end
+}
+
+func formatTime(d datos.Datetime) string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format("2006-01-02 15:04:05")
+}
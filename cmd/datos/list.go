@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/erizocosmico/datos"
+)
+
+// list prints all the publishers, themes or spatials.
+func list(args []string) {
+	var cc clientConfig
+	var lang string
+
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+	flags.StringVar(&lang, "lang", datos.Spanish, "language of the labels of themes and spatial types (es or en)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: datos list [flags] publishers|themes|spatials")
+		flags.PrintDefaults()
+	}
+	clientFlags(flags, &cc)
+	check(flags.Parse(args))
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	client := newClient(cc)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	params := datos.Params{PageSize: 100}
+
+	switch flags.Arg(0) {
+	case "publishers":
+		fmt.Fprintln(tw, "ID\tLABEL")
+		for {
+			ps, err := client.Publishers(ctx, params)
+			check(err)
+			for _, p := range ps {
+				fmt.Fprintf(tw, "%s\t%s\n", p.Notation, p.Label)
+			}
+
+			if len(ps) < int(params.PageSize) {
+				break
+			}
+			params.Page++
+		}
+	case "themes":
+		fmt.Fprintln(tw, "ID\tLABEL")
+		ts, err := client.Themes(ctx, params)
+		check(err)
+		for _, t := range ts {
+			fmt.Fprintf(tw, "%s\t%s\n", t.ID(), t.Label(lang))
+		}
+	case "spatials":
+		fmt.Fprintln(tw, "TYPE\tID\tLABEL")
+		for {
+			ss, err := client.Spatials(ctx, params)
+			check(err)
+			for _, s := range ss {
+				typ := s.Type
+				if t, ok := s.SpatialType(); ok {
+					typ = t.Label(lang)
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", typ, s.ID(), s.Label)
+			}
+
+			if len(ss) < int(params.PageSize) {
+				break
+			}
+			params.Page++
+		}
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	check(tw.Flush())
+}
@@ -1,261 +1,200 @@
 package main
 
 import (
+	"crypto/x509"
 	"flag"
 	"fmt"
-	"io"
-	"net/http"
+	"io/ioutil"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
-	"unicode"
 
 	"github.com/erizocosmico/datos"
+	"github.com/erizocosmico/datos/app"
 	"github.com/sirupsen/logrus"
 )
 
-var verbose bool
-
-func main() {
-	var title, keyword, theme, publisher, format, output string
-	var num uint
-
-	flag.StringVar(&title, "title", "", "filter by title")
-	flag.StringVar(&keyword, "keyword", "", "filter by keyword")
-	flag.StringVar(&theme, "theme", "", "filter by theme")
-	flag.StringVar(&publisher, "publisher", "", "filter by publisher")
-	flag.StringVar(&format, "format", "", "filter by format")
-	flag.StringVar(&output, "o", "", "folder to store the datasets")
-	flag.UintVar(&num, "n", 0, "maximum number of datasets to download")
-	flag.BoolVar(&verbose, "v", false, "verbose mode")
-
-	flag.Parse()
-
-	var err error
-	if output == "" {
-		output, err = os.Getwd()
-		check(err)
-	} else {
-		output, err = filepath.Abs(output)
-		check(err)
-	}
-
-	if fi, err := os.Stat(output); os.IsNotExist(err) {
-		check(os.MkdirAll(output, 0755))
-	} else if err != nil {
-		check(err)
-	} else if !fi.IsDir() {
-		logrus.Errorf("output directory %s exists and is not a directory", output)
-		os.Exit(1)
-	}
-
-	if title == "" && keyword == "" && theme == "" && publisher == "" && format == "" {
-		logrus.Error("at least one of -title, -keyword, -theme, -publisher or -format must be provided")
-		os.Exit(1)
-	}
+type command struct {
+	name string
+	help string
+	run  func(args []string)
+}
 
-	client, err := datos.NewClient()
-	check(err)
+var commands = []command{
+	{"search", "list the datasets matching the given filters", search},
+	{"schema", "print the JSON Schema of the output of search", schema},
+	{"download", "download the datasets matching the given filters", download},
+	{"sync", "download the datasets modified since the last download", sync},
+	{"verify", "check the downloaded files against the manifest", verify},
+	{"sample", "download a random sample of the datasets matching the given filters", sample},
+	{"list", "list publishers, themes or spatials", list},
+	{"info", "show the metadata of a dataset", info},
+	{"preview", "show the first rows of a dataset", preview},
+	{"open", "open the page of a dataset in the browser", open},
+	{"cache", "clear the cache of API responses or show its statistics", cache},
+	{"snapshot", "download the whole catalog to a file to query it offline", snapshot},
+	{"report-upstream", "report problems found in the catalog by publisher", reportUpstream},
+	{"digest", "render the datasets published or updated recently as an HTML email", digest},
+	{"notify", "post the datasets published recently to Mastodon, X, Matrix or Telegram", notify},
+	{"features", "list the optional features available in this build", features},
+}
 
-	var f getFunc
-	if title != "" {
-		f = func(p datos.Params) ([]datos.Dataset, error) {
-			return client.DatasetsByTitle(title, p)
-		}
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
 	}
 
-	if keyword != "" && f == nil {
-		f = func(p datos.Params) ([]datos.Dataset, error) {
-			return client.DatasetsByKeyword(keyword, p)
-		}
-	} else if keyword != "" && f != nil {
-		logrus.Warn("ignoring -keyword, because another filter parameter has already been provided")
+	// Before subcommands existed, downloading was done passing the flags
+	// directly, so that's still supported.
+	if strings.HasPrefix(os.Args[1], "-") && os.Args[1] != "-h" && os.Args[1] != "-help" {
+		download(os.Args[1:])
+		return
 	}
 
-	if theme != "" && f == nil {
-		f = func(p datos.Params) ([]datos.Dataset, error) {
-			return client.DatasetsByTheme(theme, p)
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			c.run(os.Args[2:])
+			return
 		}
-	} else if theme != "" && f != nil {
-		logrus.Warn("ignoring -theme, because another filter parameter has already been provided")
 	}
 
-	if publisher != "" && f == nil {
-		f = func(p datos.Params) ([]datos.Dataset, error) {
-			return client.DatasetsByPublisher(publisher, p)
-		}
-	} else if publisher != "" && f != nil {
-		logrus.Warn("ignoring -publisher, because another filter parameter has already been provided")
+	usage()
+	if os.Args[1] != "help" && os.Args[1] != "-h" && os.Args[1] != "-help" {
+		os.Exit(2)
 	}
+}
 
-	if format != "" && f == nil {
-		f = func(p datos.Params) ([]datos.Dataset, error) {
-			return client.DatasetsByFormat(format, p)
-		}
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: datos <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", c.name, c.help)
 	}
-
-	datasets, err := findAllDatasets(f, int(num), formats[strings.ToLower(format)])
-	check(err)
-
-	check(downloadAll(datasets, output))
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, `run "datos <command> -h" to see the flags of a command`)
 }
 
-type getFunc func(datos.Params) ([]datos.Dataset, error)
+// filterFlags adds the flags to filter datasets to the flag set.
+func filterFlags(flags *flag.FlagSet, config *app.Config, num *uint) {
+	flags.StringVar(&config.Title, "title", "", "filter by title, may contain * and ? wildcards")
+	flags.StringVar(&config.TitleRegexp, "title-regex", "", "filter by titles matching the given regular expression")
+	flags.StringVar(&config.Filter, "filter", "", `filter datasets with an expression, e.g. 'theme == "salud" && modified > "2024-01-01"'`)
+	flags.StringVar(&config.Keyword, "keyword", "", "filter by keyword, may contain * and ? wildcards")
+	flags.StringVar(&config.Theme, "theme", "", "filter by theme")
+	flags.StringVar(&config.Publisher, "publisher", "", "filter by publisher")
+	flags.StringVar(&config.Format, "format", "", "filter by format")
+	flags.UintVar(num, "n", 0, "maximum number of datasets")
+	flags.BoolVar(&config.Verbose, "v", false, "verbose mode")
+}
 
-type dataset struct {
-	url   string
-	title string
-	id    string
+// reportFileFlag adds the flag to write a summary of the run to the flag
+// set. All the commands running batches support it.
+func reportFileFlag(flags *flag.FlagSet, path *string) {
+	flags.StringVar(path, "report-file", "", "write a JSON summary of the run to the given file")
 }
 
-func findAllDatasets(f getFunc, max int, format string) ([]dataset, error) {
-	var result []dataset
-	params := datos.Params{
-		Page:     0,
-		PageSize: 100,
+// writeOutcome finishes the outcome with the error of the run and writes it
+// to path, unless it's empty.
+func writeOutcome(path string, outcome app.Outcome, err error) {
+	if path == "" {
+		return
 	}
 
-	for {
-		datasets, err := f(params)
-		if err != nil {
-			return nil, err
-		}
-
-		for _, ds := range datasets {
-			var url string
-			for _, d := range ds.Distribution {
-				if format == "" && allowedFormats[d.Format.Value] {
-					url = d.AccessURL
-					break
-				}
-
-				if d.Format.Value == format {
-					url = d.AccessURL
-					break
-				}
-			}
-
-			var id = ds.Identifier
-			var title string
-			if len(ds.Title) > 0 {
-				title = ds.Title[0]
-			}
-
-			if id == "" && len(ds.Title) > 0 {
-				id = ds.Title[0]
-			}
-
-			if ds.Identifier == "" && verbose {
-				logrus.Warn("found dataset with no identifier")
-				continue
-			}
-
-			if url == "" && verbose {
-				var txt = id
-				if id == "" {
-					txt = title
-				}
-
-				logrus.Warnf("no suitable distribution found for dataset: %s", txt)
-				continue
-			}
-
-			result = append(result, dataset{url, title, slugify(id, ds.Issued.Time)})
-
-			if len(result) >= max {
-				return result, nil
-			}
-		}
-
-		if len(datasets) < int(params.PageSize) {
-			return result, nil
-		}
+	outcome.Finish(err)
+	check(outcome.WriteFile(path))
+}
 
-		params.Page++
-	}
+// clientConfig is the configuration of the API client.
+type clientConfig struct {
+	rate      float64
+	cacheDir  string
+	cacheType string
+	cacheSize uint
+	cacheTTL  datos.CacheTTL
+	caFile    string
+	sysCerts  bool
+	debug     bool
 }
 
-func downloadAll(datasets []dataset, output string) error {
-	for _, d := range datasets {
-		if err := download(d, output); err != nil {
-			return err
-		}
-	}
-	return nil
+// clientFlags adds the flags to configure the client to the flag set.
+func clientFlags(flags *flag.FlagSet, config *clientConfig) {
+	flags.Float64Var(&config.rate, "rate", 0, "maximum number of API requests per second, 0 means no limit")
+	flags.StringVar(&config.cacheDir, "cache", "", "folder to cache API responses in, so they are only downloaded again if they changed")
+	flags.StringVar(&config.cacheType, "cache-backend", "disk", "backend of the cache of API responses, see datos features; -cache is its location")
+	flags.UintVar(&config.cacheSize, "cache-size", 256, "maximum size of the cache in MB, the least recently used responses are removed when it's exceeded, 0 means no limit")
+	flags.DurationVar(&config.cacheTTL.Datasets, "cache-ttl", 0, "time cached lists of datasets are used without checking whether they changed")
+	flags.DurationVar(&config.cacheTTL.Taxonomies, "cache-taxonomy-ttl", 24*time.Hour, "time cached lists of publishers, themes and spatials are used without checking whether they changed")
+	flags.StringVar(&config.caFile, "ca-file", "", "PEM file with certificates to trust along with the system ones, e.g. the one of a proxy intercepting TLS connections")
+	flags.BoolVar(&config.sysCerts, "system-certs", false, "only trust the system certificates instead of also fetching the ones of the API")
+	flags.BoolVar(&config.debug, "debug", false, "log every request made by the client")
 }
 
-func download(d dataset, output string) error {
-	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Get(d.url)
-	if err != nil {
-		return err
+func newClient(config clientConfig) *datos.Client {
+	var opts []datos.Option
+	if config.rate > 0 {
+		opts = append(opts, datos.WithRateLimit(config.rate, 1))
 	}
-	defer resp.Body.Close()
 
-	var ext string
-	typ := resp.Header.Get("Content-Type")
-	if strings.Contains(typ, "xml") {
-		ext = ".xml"
-	} else if strings.Contains(typ, "json") {
-		ext = ".json"
-	} else if strings.Contains(typ, "csv") {
-		ext = ".csv"
+	if config.cacheDir != "" {
+		cache, err := datos.OpenCache(config.cacheType, config.cacheDir)
+		check(err)
+		if c, ok := cache.(interface{ SetMaxBytes(int64) }); ok {
+			c.SetMaxBytes(int64(config.cacheSize) << 20)
+		}
+		opts = append(opts, datos.WithCache(cache), datos.WithCacheTTL(config.cacheTTL))
 	}
 
-	path := filepath.Join(output, d.id+ext)
-	f, err := os.Create(path)
-	if err != nil {
-		return err
+	switch {
+	case config.caFile != "":
+		opts = append(opts, datos.WithRootCAs(certPool(config.caFile)))
+	case config.sysCerts:
+		opts = append(opts, datos.WithSystemCertsOnly())
 	}
-	defer f.Close()
 
-	_, err = io.Copy(f, resp.Body)
-	if err != nil {
-		logrus.Errorf("error downoading dataset: %s", d.id)
-		return err
+	if config.debug {
+		logrus.SetLevel(logrus.DebugLevel)
+		opts = append(opts, datos.WithLogger(datos.LoggerFunc(logClient)))
 	}
 
-	logrus.Infof("downloaded dataset %q to %s", d.title, path)
-
-	return nil
+	client, err := datos.NewClient(opts...)
+	check(err)
+	return client
 }
 
-func check(err error) {
-	if err != nil {
-		logrus.Fatal(err)
+// logClient logs the diagnostics of the client with logrus.
+func logClient(level datos.LogLevel, msg string, fields datos.Fields) {
+	entry := logrus.WithFields(logrus.Fields(fields))
+	switch level {
+	case datos.LevelDebug:
+		entry.Debug(msg)
+	case datos.LevelInfo:
+		entry.Info(msg)
+	case datos.LevelWarn:
+		entry.Warn(msg)
+	default:
+		entry.Error(msg)
 	}
 }
 
-func slugify(name string, issued time.Time) string {
-	if i := strings.LastIndex(name, "/"); i >= 0 {
-		name = name[i:]
-	}
+// certPool returns the system certificates along with the ones in the
+// given PEM file.
+func certPool(file string) *x509.CertPool {
+	pem, err := ioutil.ReadFile(file)
+	check(err)
 
-	var result []rune
-	var last rune
-	for _, r := range name {
-		if unicode.IsLetter(r) ||
-			unicode.IsDigit(r) ||
-			r == '_' || r == '-' {
-			result = append(result, r)
-		} else if last != '-' {
-			result = append(result, '-')
-		}
+	pool, err := x509.SystemCertPool()
+	check(err)
 
-		last = result[len(result)-1]
+	if !pool.AppendCertsFromPEM(pem) {
+		logrus.Fatalf("no certificates found in %s", file)
 	}
-	return fmt.Sprintf("%s-%d", string(result), issued.Unix())
-}
-
-var formats = map[string]string{
-	"csv":  "text/csv",
-	"json": "application/json",
-	"xml":  "application/xml",
+	return pool
 }
 
-var allowedFormats = map[string]bool{
-	"text/csv":         true,
-	"application/json": true,
-	"application/xml":  true,
+func check(err error) {
+	if err != nil {
+		logrus.Fatal(err)
+	}
 }
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/erizocosmico/datos/app"
+	"github.com/sirupsen/logrus"
+)
+
+// notify posts the datasets published recently that match the filters to
+// Mastodon, X, Matrix or Telegram.
+func notify(args []string) {
+	var config app.Config
+	var num uint
+	var cc clientConfig
+	var since time.Duration
+	var mastodon, visibility, matrix, matrixRoom, telegramChat, others, state, reportFile string
+	var x, dryRun bool
+
+	flags := flag.NewFlagSet("notify", flag.ExitOnError)
+	filterFlags(flags, &config, &num)
+	flags.DurationVar(&since, "since", 24*time.Hour, "post the datasets published in this period of time")
+	flags.StringVar(&mastodon, "mastodon", "", "URL of the Mastodon server to post to, e.g. https://mastodon.social; the access token is read from DATOS_MASTODON_TOKEN")
+	flags.StringVar(&visibility, "visibility", "", "visibility of the Mastodon statuses (public, unlisted, private or direct)")
+	flags.BoolVar(&x, "x", false, "post to X; the OAuth 2.0 user access token is read from DATOS_X_TOKEN")
+	flags.StringVar(&matrix, "matrix", "", "URL of the Matrix homeserver to send messages to, e.g. https://matrix.org; the access token is read from DATOS_MATRIX_TOKEN")
+	flags.StringVar(&matrixRoom, "matrix-room", "", "ID of the Matrix room to send messages to, e.g. !abcdef:matrix.org")
+	flags.StringVar(&telegramChat, "telegram-chat", "", "ID of the Telegram chat or username of the channel to send messages to; the bot token is read from DATOS_TELEGRAM_TOKEN")
+	flags.StringVar(&others, "notifier", "", "comma separated list of notifiers as name=target, including the ones added by other packages (see datos features); the token is read from DATOS_<NAME>_TOKEN")
+	flags.StringVar(&state, "state", "datos-posted.json", "file recording the datasets already posted, so they are not posted twice")
+	flags.BoolVar(&dryRun, "dry-run", false, "print the posts instead of publishing them")
+	reportFileFlag(flags, &reportFile)
+	clientFlags(flags, &cc)
+	check(flags.Parse(args))
+
+	var notifiers []app.Notifier
+	if mastodon != "" {
+		notifiers = append(notifiers, &app.Mastodon{
+			Server:     mastodon,
+			Token:      requireEnv("DATOS_MASTODON_TOKEN", dryRun),
+			Visibility: visibility,
+		})
+	}
+
+	if x {
+		notifiers = append(notifiers, &app.X{Token: requireEnv("DATOS_X_TOKEN", dryRun)})
+	}
+
+	if matrix != "" {
+		if matrixRoom == "" {
+			logrus.Fatal("-matrix-room is required to send messages to Matrix")
+		}
+
+		notifiers = append(notifiers, &app.Matrix{
+			Homeserver: matrix,
+			Room:       matrixRoom,
+			Token:      requireEnv("DATOS_MATRIX_TOKEN", dryRun),
+		})
+	}
+
+	if telegramChat != "" {
+		notifiers = append(notifiers, &app.Telegram{
+			Chat:  telegramChat,
+			Token: requireEnv("DATOS_TELEGRAM_TOKEN", dryRun),
+		})
+	}
+
+	for _, spec := range splitList(others) {
+		name, target := spec, ""
+		if i := strings.Index(spec, "="); i >= 0 {
+			name, target = spec[:i], spec[i+1:]
+		}
+
+		env := "DATOS_" + strings.ToUpper(strings.Replace(name, "-", "_", -1)) + "_TOKEN"
+		n, err := app.OpenNotifier(name, target, requireEnv(env, dryRun))
+		check(err)
+		notifiers = append(notifiers, n)
+	}
+
+	if len(notifiers) == 0 {
+		logrus.Fatal("at least one of -mastodon, -x, -matrix, -telegram-chat or -notifier must be provided")
+	}
+
+	config.Max = int(num)
+	a, err := app.New(newClient(cc), config)
+	check(err)
+
+	ctx := context.Background()
+	now := time.Now()
+	outcome := app.Outcome{Command: "notify", Started: now}
+	datasets, err := a.Published(ctx, now.Add(-since), now)
+	if err != nil {
+		writeOutcome(reportFile, outcome, err)
+		check(err)
+	}
+	outcome.Found = len(datasets)
+
+	posted, err := app.LoadPostedDatasets(state)
+	check(err)
+
+	if dryRun {
+		for _, ds := range datasets {
+			for _, n := range notifiers {
+				if posted.HasPost(ds, n.Name()) {
+					continue
+				}
+
+				fmt.Printf("[%s]\n%s\n\n", n.Name(), app.PostText(ds, n.MaxLength()))
+			}
+		}
+		return
+	}
+
+	n, err := app.NotifyDatasets(ctx, notifiers, datasets, posted)
+	// Datasets published before the period can't be found again, so there
+	// is no need to remember them.
+	posted.Prune(now.Add(-2 * since))
+	check(posted.Save())
+
+	outcome.Posted = n
+	if errs, ok := err.(app.NotifyError); ok {
+		outcome.Failed = len(errs)
+	}
+	writeOutcome(reportFile, outcome, err)
+	check(err)
+
+	logrus.Infof("posted %d new dataset(s)", n)
+}
+
+// requireEnv returns the value of the environment variable, exiting if it's
+// not set unless optional is true.
+func requireEnv(name string, optional bool) string {
+	v := os.Getenv(name)
+	if v == "" && !optional {
+		logrus.Fatalf("the %s environment variable must be set", name)
+	}
+	return v
+}
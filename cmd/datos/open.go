@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/erizocosmico/datos"
+)
+
+func open(args []string) {
+	var urlOnly bool
+
+	flags := flag.NewFlagSet("open", flag.ExitOnError)
+	flags.BoolVar(&urlOnly, "url-only", false, "print the link to the dataset page instead of opening it")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: datos open [flags] <dataset id>")
+		flags.PrintDefaults()
+	}
+	check(flags.Parse(args))
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	url := datos.PortalURL(flags.Arg(0))
+	if urlOnly {
+		fmt.Println(url)
+		return
+	}
+
+	check(openBrowser(url))
+}
+
+// openBrowser opens the url in the default browser of the system.
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/erizocosmico/datos"
+	"github.com/erizocosmico/datos/app"
+	"github.com/sirupsen/logrus"
+)
+
+func preview(args []string) {
+	var opts app.PreviewOptions
+	var rows, kb uint
+
+	flags := flag.NewFlagSet("preview", flag.ExitOnError)
+	flags.UintVar(&rows, "n", 10, "maximum number of rows to show")
+	flags.UintVar(&kb, "kb", 64, "maximum number of kilobytes to download")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: datos preview [flags] <dataset id>")
+		flags.PrintDefaults()
+	}
+	check(flags.Parse(args))
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	opts.Rows = int(rows)
+	opts.MaxBytes = int64(kb) << 10
+
+	client, err := datos.NewClient()
+	check(err)
+
+	p, err := app.PreviewDataset(context.Background(), client, flags.Arg(0), opts)
+	check(err)
+
+	if p.Title != "" {
+		fmt.Println(p.Title)
+	}
+	logrus.Infof("previewing %s", p.URL)
+	check(p.WriteTable(os.Stdout))
+}
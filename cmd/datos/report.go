@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"os"
+	"time"
+
+	"github.com/erizocosmico/datos"
+	"github.com/erizocosmico/datos/app"
+	"github.com/sirupsen/logrus"
+)
+
+func reportUpstream(args []string) {
+	var opts app.UpstreamOptions
+	var format, output, reportFile string
+	var num uint
+
+	flags := flag.NewFlagSet("report-upstream", flag.ExitOnError)
+	flags.StringVar(&opts.Publisher, "publisher", "", "only check the datasets of the given publisher")
+	flags.UintVar(&num, "n", 0, "maximum number of datasets to check")
+	flags.BoolVar(&opts.CheckLinks, "check-links", false, "check that all distributions can be downloaded")
+	flags.StringVar(&format, "format", "markdown", "format of the report (markdown, csv, xlsx or ods)")
+	flags.StringVar(&output, "o", "", "file to write the report to, by default it's written to stdout")
+	reportFileFlag(flags, &reportFile)
+	check(flags.Parse(args))
+
+	opts.Max = int(num)
+	if format != "markdown" && format != "csv" && format != "xlsx" && format != "ods" {
+		logrus.Fatalf("invalid report format: %s", format)
+	}
+
+	client, err := datos.NewClient()
+	check(err)
+
+	outcome := app.Outcome{Command: "report-upstream", Started: time.Now()}
+	report, err := app.ReportUpstream(context.Background(), client, opts)
+	if err == nil {
+		outcome.Found = report.Datasets
+		outcome.Problems = len(report.Problems)
+		for _, p := range report.Problems {
+			if p.Kind == app.ProblemUndecodable {
+				outcome.Warnings++
+			}
+		}
+	}
+	writeOutcome(reportFile, outcome, err)
+	check(err)
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		check(err)
+		defer f.Close()
+		w = f
+	}
+
+	switch format {
+	case "csv":
+		check(report.WriteCSV(w))
+	case "xlsx":
+		check(report.WriteXLSX(w))
+	case "ods":
+		check(report.WriteODS(w))
+	default:
+		check(report.WriteMarkdown(w))
+	}
+
+	logrus.Infof("found %d problem(s) in %d dataset(s)", len(report.Problems), report.Datasets)
+}
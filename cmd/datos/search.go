@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/erizocosmico/datos/app"
+	"github.com/sirupsen/logrus"
+)
+
+// search prints the metadata of the datasets matching the filters without
+// downloading them.
+func search(args []string) {
+	var config app.Config
+	var num uint
+	var cc clientConfig
+	var output string
+
+	flags := flag.NewFlagSet("search", flag.ExitOnError)
+	filterFlags(flags, &config, &num)
+	flags.StringVar(&output, "output", app.MetadataTable, "output format (json, ndjson, csv, table, xlsx or ods)")
+	clientFlags(flags, &cc)
+	check(flags.Parse(args))
+
+	switch output {
+	case app.MetadataJSON, app.MetadataNDJSON, app.MetadataCSV, app.MetadataTable, app.MetadataXLSX, app.MetadataODS:
+	default:
+		logrus.Fatalf("invalid output format: %s", output)
+	}
+
+	config.Max = int(num)
+	a, err := app.New(newClient(cc), config)
+	check(err)
+
+	datasets, err := a.Search(context.Background())
+	check(err)
+
+	check(app.WriteMetadata(os.Stdout, output, datasets))
+}
+
+// schema prints the JSON Schema of the output of search in the json and
+// ndjson formats.
+func schema(args []string) {
+	flags := flag.NewFlagSet("schema", flag.ExitOnError)
+	check(flags.Parse(args))
+	fmt.Print(app.MetadataSchema)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/erizocosmico/datos/app"
+	"github.com/sirupsen/logrus"
+)
+
+// snapshot downloads the whole catalog to a file.
+func snapshot(args []string) {
+	var cc clientConfig
+	var reportFile string
+
+	flags := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: datos snapshot [flags] <file>")
+		flags.PrintDefaults()
+	}
+	reportFileFlag(flags, &reportFile)
+	clientFlags(flags, &cc)
+	check(flags.Parse(args))
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	outcome := app.Outcome{Command: "snapshot", Started: time.Now()}
+	err := newClient(cc).SaveSnapshot(context.Background(), flags.Arg(0))
+	writeOutcome(reportFile, outcome, err)
+	check(err)
+	logrus.Infof("saved snapshot of the catalog to %s", flags.Arg(0))
+}
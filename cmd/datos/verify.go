@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/erizocosmico/datos/app"
+)
+
+// verify checks the downloaded files of a folder against its manifest.
+func verify(args []string) {
+	var dir, reportFile string
+	var repair bool
+
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	flags.StringVar(&dir, "o", ".", "output folder of the downloads to verify")
+	flags.BoolVar(&repair, "repair", false, "remove the datasets with missing or changed files from the manifest, so sync downloads them again")
+	reportFileFlag(flags, &reportFile)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: datos verify [flags]")
+		flags.PrintDefaults()
+	}
+	check(flags.Parse(args))
+
+	outcome := app.Outcome{Command: "verify", Started: time.Now()}
+	report, err := app.Verify(dir, repair)
+	if err != nil {
+		writeOutcome(reportFile, outcome, err)
+		check(err)
+	}
+	check(app.WriteVerifyReport(os.Stdout, report))
+
+	outcome.Found = report.Checked
+	outcome.Failed = len(report.Missing) + len(report.Mismatched)
+	outcome.Problems = len(report.Orphans)
+	if !report.OK() && !report.Repaired {
+		err = fmt.Errorf("%d dataset(s) with missing or changed files, %d file(s) not in the manifest", outcome.Failed, outcome.Problems)
+	}
+
+	writeOutcome(reportFile, outcome, err)
+	if err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,78 @@
+package datos
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// WithCoalescing makes concurrent identical requests made with the client
+// result in a single request to the API, whose response is shared by all
+// of them. It's useful when the same client serves many consumers that
+// tend to make the same queries at the same time.
+func WithCoalescing() Option {
+	return func(c *Client) {
+		c.inflight = &flightGroup{calls: make(map[string]*flight)}
+	}
+}
+
+// flight is an in-flight request.
+type flight struct {
+	done chan struct{}
+	body []byte
+	err  error
+}
+
+// flightGroup deduplicates concurrent calls with the same key.
+type flightGroup struct {
+	mut   sync.Mutex
+	calls map[string]*flight
+	// shared is the number of calls that waited for another one instead of
+	// making the request.
+	shared int
+}
+
+// do calls fn and returns its result, unless there is already a call in
+// flight with the same key, in which case it waits for it and returns its
+// result instead. Callers stop waiting when their context is done, but the
+// call is still made with the context of the caller that started it. If fn
+// panics, the callers waiting for it get an error and the panic is passed
+// on to the caller that made the call.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() ([]byte, error)) ([]byte, error) {
+	g.mut.Lock()
+	if f, ok := g.calls[key]; ok {
+		g.shared++
+		g.mut.Unlock()
+		select {
+		case <-f.done:
+			return f.body, f.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	f := &flight{done: make(chan struct{})}
+	g.calls[key] = f
+	g.mut.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			f.body, f.err = nil, fmt.Errorf("datos: coalesced request panicked: %v", r)
+			g.finish(key, f)
+			panic(r)
+		}
+	}()
+
+	f.body, f.err = fn()
+	g.finish(key, f)
+	return f.body, f.err
+}
+
+// finish releases the callers waiting for the call with the given key.
+func (g *flightGroup) finish(key string, f *flight) {
+	close(f.done)
+
+	g.mut.Lock()
+	delete(g.calls, key)
+	g.mut.Unlock()
+}
@@ -0,0 +1,147 @@
+package datos
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCoalescing(t *testing.T) {
+	const callers = 5
+	var requests int
+	release := make(chan struct{})
+	c := &Client{c: &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			requests++
+			<-release
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     make(http.Header),
+				Body:       ioutil.NopCloser(strings.NewReader(`{"result":{"items":[{"notation":"foo"}]}}`)),
+				Request:    r,
+			}, nil
+		}),
+	}}
+	WithCoalescing()(c)
+
+	var wg sync.WaitGroup
+	results := make([][]Publisher, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ps, err := c.Publishers(context.Background(), Params{})
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			results[i] = ps
+		}(i)
+	}
+
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		c.inflight.mut.Lock()
+		shared := c.inflight.shared
+		c.inflight.mut.Unlock()
+		if shared == callers-1 {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d calls to wait for the first one, got: %d", callers-1, shared)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if requests != 1 {
+		t.Errorf("expected a single request, got: %d", requests)
+	}
+
+	for _, ps := range results {
+		if len(ps) != 1 || ps[0].Notation != "foo" {
+			t.Errorf("wrong publishers: %v", ps)
+		}
+	}
+}
+
+func TestCoalescingCanceled(t *testing.T) {
+	g := &flightGroup{calls: make(map[string]*flight)}
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go g.do(context.Background(), "foo", func() ([]byte, error) {
+		close(started)
+		<-release
+		return nil, nil
+	})
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := g.do(ctx, "foo", nil); err != context.Canceled {
+		t.Errorf("expected canceled error, got: %v", err)
+	}
+	close(release)
+}
+
+func TestCoalescingPanic(t *testing.T) {
+	g := &flightGroup{calls: make(map[string]*flight)}
+	release := make(chan struct{})
+	started := make(chan struct{})
+	panicked := make(chan interface{})
+	go func() {
+		defer func() { panicked <- recover() }()
+		g.do(context.Background(), "foo", func() ([]byte, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+	<-started
+
+	waited := make(chan error)
+	go func() {
+		_, err := g.do(context.Background(), "foo", nil)
+		waited <- err
+	}()
+
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		g.mut.Lock()
+		shared := g.shared
+		g.mut.Unlock()
+		if shared == 1 {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("expected a call to wait for the first one")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	close(release)
+	if r := <-panicked; r != "boom" {
+		t.Errorf("expected panic to be passed on to the caller, got: %v", r)
+	}
+
+	select {
+	case err := <-waited:
+		if err == nil || !strings.Contains(err.Error(), "boom") {
+			t.Errorf("expected error with the panic, got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected waiting call to be released")
+	}
+
+	body, err := g.do(context.Background(), "foo", func() ([]byte, error) {
+		return []byte("ok"), nil
+	})
+	if err != nil || string(body) != "ok" {
+		t.Errorf("expected a new call after the panic, got: %q, %v", body, err)
+	}
+}
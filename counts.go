@@ -0,0 +1,72 @@
+package datos
+
+import (
+	"context"
+	"net/url"
+)
+
+// countPageSize is the page size used to count the items of a path.
+const countPageSize = 50
+
+// ThemeCount is a theme with its number of datasets.
+type ThemeCount struct {
+	Theme
+	// Datasets is the number of datasets of the theme.
+	Datasets int
+}
+
+// ThemesWithCounts returns all themes along with the number of datasets
+// of each of them. If the API does not return the total of items, every
+// dataset of every theme needs to be paged through, which takes a while.
+func (c *Client) ThemesWithCounts(ctx context.Context) ([]ThemeCount, error) {
+	var themes []Theme
+	params := Params{PageSize: countPageSize}
+	for {
+		page, err := c.Themes(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		themes = append(themes, page...)
+		if len(page) < countPageSize {
+			break
+		}
+		params.Page++
+	}
+
+	result := make([]ThemeCount, len(themes))
+	for i, t := range themes {
+		n, err := c.count(ctx, "/catalog/dataset/theme/"+url.PathEscape(t.ID()))
+		if err != nil {
+			return nil, err
+		}
+
+		result[i] = ThemeCount{t, n}
+	}
+
+	return result, nil
+}
+
+// count returns the number of items of the given path, using the total of
+// items of the response if there is one. Items are not decoded, so
+// malformed items are counted as well.
+func (c *Client) count(ctx context.Context, path string) (int, error) {
+	var n int
+	params := Params{PageSize: countPageSize}
+	for {
+		var resp itemsResp
+		if err := c.get(ctx, path, params, &resp); err != nil {
+			return 0, err
+		}
+
+		if resp.Result.TotalItems != nil {
+			return *resp.Result.TotalItems, nil
+		}
+
+		n += len(resp.Result.Items)
+		if len(resp.Result.Items) < countPageSize {
+			return n, nil
+		}
+		params.Page++
+	}
+}
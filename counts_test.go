@@ -0,0 +1,53 @@
+package datos
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestThemesWithCounts(t *testing.T) {
+	c := newTestClientFunc(func(r *http.Request) (int, string) {
+		var body string
+		switch r.URL.Path {
+		case "/apidata/catalog/theme":
+			body = `{"result":{"items":[
+				{"_about":"http://datos.gob.es/kos/sector-publico/sector/salud"},
+				{"_about":"http://datos.gob.es/kos/sector-publico/sector/turismo"}
+			]}}`
+		case "/apidata/catalog/dataset/theme/salud":
+			n := countPageSize
+			if r.URL.Query().Get("_page") == "1" {
+				n = 3
+			}
+			items := make([]string, n)
+			for i := range items {
+				items[i] = fmt.Sprintf(`{"_about":"%d"}`, i)
+			}
+			body = `{"result":{"items":[` + strings.Join(items, ",") + `]}}`
+		default:
+			body = `{"result":{"items":[]}}`
+		}
+
+		return http.StatusOK, body
+	})
+
+	themes, err := c.ThemesWithCounts(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(themes) != 2 {
+		t.Fatalf("wrong number of themes, expected: 2, got: %d", len(themes))
+	}
+
+	if themes[0].ID() != "salud" || themes[0].Datasets != countPageSize+3 {
+		t.Errorf("wrong count for %s: %d", themes[0].ID(), themes[0].Datasets)
+	}
+
+	if themes[1].ID() != "turismo" || themes[1].Datasets != 0 {
+		t.Errorf("wrong count for %s: %d", themes[1].ID(), themes[1].Datasets)
+	}
+}
@@ -2,10 +2,11 @@ package datos
 
 import (
 	"bufio"
-	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"time"
 	"unicode"
 	"unicode/utf8"
@@ -14,6 +15,23 @@ import (
 // Datetime format returned by the API.
 type Datetime struct {
 	time.Time
+	raw string
+}
+
+// Raw returns the date as it was received from the API, before parsing it.
+// It is empty if the date was not decoded from JSON.
+func (d Datetime) Raw() string {
+	return d.raw
+}
+
+// MarshalJSON implements the json.Marshaler interface. The date is encoded
+// in RFC 3339, which UnmarshalJSON can decode as well, and zero dates are
+// encoded as null.
+func (d Datetime) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.Time.Format(time.RFC3339))
 }
 
 var months = map[string]time.Month{
@@ -31,14 +49,71 @@ var months = map[string]time.Month{
 	"dic": time.December,
 }
 
-// UnmarshalJSON implements the json.Unmarshaler interface.
+// datetimeLayouts are the layouts, besides the Spanish one, of the dates
+// returned by the API. They are tried in order.
+var datetimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 GMT-0700",
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Dates can be in
+// the Spanish format used by most of the API, like "dom, 18 nov 2012
+// 23:00:00 GMT+0000", or in ISO-8601. Null and empty dates are zero.
 func (d *Datetime) UnmarshalJSON(b []byte) error {
-	r := bufio.NewReader(bytes.NewReader(b))
+	if string(b) == "null" {
+		*d = Datetime{}
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		return fmt.Errorf("error parsing date %s: %s", string(b), err)
+	}
+
+	t, err := parseDatetime(str)
+	if err != nil {
+		return err
+	}
+
+	*d = Datetime{Time: t, raw: str}
+	return nil
+}
+
+// parseDatetime parses a date in any of the formats of the API. If the date
+// is empty, the zero time is returned.
+func parseDatetime(str string) (time.Time, error) {
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return time.Time{}, nil
+	}
+
+	t, spanishErr := parseSpanishDatetime(str)
+	if spanishErr == nil {
+		return t, nil
+	}
+
+	for _, layout := range datetimeLayouts {
+		if t, err := time.Parse(layout, str); err == nil {
+			return t.UTC(), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("error parsing date %q: %s", str, spanishErr)
+}
+
+// parseSpanishDatetime parses dates like "dom, 18 nov 2012 23:00:00
+// GMT+0000", with the names of the days and months in Spanish.
+func parseSpanishDatetime(str string) (time.Time, error) {
+	r := bufio.NewReader(strings.NewReader(str))
 
 	var mo string
 	var day, y, h, m, s int
 	steps := []parseFunc{
-		expectChars(`"`),
 		skipChars(3),
 		expectChars(","),
 		skipSpaces,
@@ -55,22 +130,20 @@ func (d *Datetime) UnmarshalJSON(b []byte) error {
 		readInt(2, &s),
 		skipSpaces,
 		expectChars("GMT+0000"),
-		expectChars(`"`),
 	}
 
 	for _, s := range steps {
 		if err := s(r); err != nil {
-			return fmt.Errorf("error parsing date %q: %s", string(b), err)
+			return time.Time{}, err
 		}
 	}
 
-	month, ok := months[mo]
+	month, ok := months[strings.ToLower(mo)]
 	if !ok {
-		return fmt.Errorf("invalid month: %s", mo)
+		return time.Time{}, fmt.Errorf("invalid month: %s", mo)
 	}
 
-	d.Time = time.Date(y, month, day, h, m, s, 0, time.UTC)
-	return nil
+	return time.Date(y, month, day, h, m, s, 0, time.UTC), nil
 }
 
 type parseFunc func(*bufio.Reader) error
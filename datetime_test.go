@@ -1,6 +1,7 @@
 package datos
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 )
@@ -17,3 +18,111 @@ func TestDatetime(t *testing.T) {
 		t.Errorf("invalid date, expected: %s, got: %s", expected, d)
 	}
 }
+
+func TestDatetimeLayouts(t *testing.T) {
+	expected := time.Date(2012, time.November, 18, 23, 0, 0, 0, time.UTC)
+	cases := []string{
+		`"dom, 18 nov 2012 23:00:00 GMT+0000"`,
+		`"Dom, 18 Nov 2012 23:00:00 GMT+0000"`,
+		`"2012-11-18T23:00:00Z"`,
+		`"2012-11-19T00:00:00+01:00"`,
+		`"2012-11-18T23:00:00.000Z"`,
+		`"2012-11-18T23:00:00"`,
+		`"2012-11-18 23:00:00"`,
+		`"Sun, 18 Nov 2012 23:00:00 GMT"`,
+		`"Sun, 18 Nov 2012 23:00:00 +0000"`,
+	}
+
+	for _, c := range cases {
+		var d Datetime
+		if err := d.UnmarshalJSON([]byte(c)); err != nil {
+			t.Errorf("unexpected error parsing %s: %s", c, err)
+			continue
+		}
+
+		if !d.Equal(expected) {
+			t.Errorf("invalid date for %s, expected: %s, got: %s", c, expected, d)
+		}
+	}
+
+	var d Datetime
+	if err := d.UnmarshalJSON([]byte(`"2012-11-18"`)); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	} else if day := time.Date(2012, time.November, 18, 0, 0, 0, 0, time.UTC); !d.Equal(day) {
+		t.Errorf("invalid date, expected: %s, got: %s", day, d)
+	}
+
+	for _, c := range []string{`null`, `""`, `"  "`} {
+		d := Datetime{Time: expected}
+		if err := d.UnmarshalJSON([]byte(c)); err != nil {
+			t.Errorf("unexpected error parsing %s: %s", c, err)
+		}
+
+		if !d.IsZero() {
+			t.Errorf("expected zero date for %s, got: %s", c, d)
+		}
+	}
+
+	for _, c := range []string{`"ayer"`, `"dom, 18 xxx 2012 23:00:00 GMT+0000"`, `12`} {
+		if err := d.UnmarshalJSON([]byte(c)); err == nil {
+			t.Errorf("expected error parsing %s", c)
+		}
+	}
+}
+
+func TestDatetimeMarshalJSON(t *testing.T) {
+	raw := "dom, 18 nov 2012 23:00:00 GMT+0000"
+	var d Datetime
+	if err := json.Unmarshal([]byte(`"`+raw+`"`), &d); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if d.Raw() != raw {
+		t.Errorf("wrong raw date, expected: %s, got: %s", raw, d.Raw())
+	}
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if expected := `"2012-11-18T23:00:00Z"`; string(data) != expected {
+		t.Errorf("wrong JSON, expected: %s, got: %s", expected, data)
+	}
+
+	var decoded Datetime
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !decoded.Equal(d.Time) {
+		t.Errorf("wrong date after round trip, expected: %s, got: %s", d, decoded)
+	}
+}
+
+func TestDatetimeMarshalJSONZero(t *testing.T) {
+	var value struct {
+		Modified Datetime `json:"modified"`
+	}
+	if err := json.Unmarshal([]byte(`{"modified":""}`), &value); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if expected := `{"modified":null}`; string(data) != expected {
+		t.Errorf("wrong JSON, expected: %s, got: %s", expected, data)
+	}
+
+	value.Modified = Datetime{Time: time.Now()}
+	if err := json.Unmarshal(data, &value); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !value.Modified.IsZero() {
+		t.Errorf("expected zero date after round trip, got: %s", value.Modified)
+	}
+}
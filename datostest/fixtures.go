@@ -0,0 +1,217 @@
+package datostest
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	publisherURL = "http://datos.gob.es/recurso/sector-publico/org/Organismo/"
+	themeURL     = "http://datos.gob.es/kos/sector-publico/sector/"
+	spatialURL   = "http://datos.gob.es/recurso/sector-publico/territorio/"
+	datasetURL   = "https://datos.gob.es/catalogo/"
+)
+
+var fixturePublishers = []struct{ notation, label string }{
+	{"L01280796", "Ayuntamiento de Madrid"},
+	{"L01080193", "Ayuntamiento de Barcelona"},
+	{"L01280066", "Ayuntamiento de Alcobendas"},
+	{"L01502973", "Ayuntamiento de Zaragoza"},
+	{"L01410917", "Ayuntamiento de Sevilla"},
+	{"L01462508", "Ayuntamiento de Valencia"},
+	{"L01330241", "Ayuntamiento de Gijón"},
+	{"A02002834", "Gobierno de Aragón"},
+	{"A09002970", "Generalitat de Catalunya"},
+	{"A16003011", "Gobierno Vasco"},
+	{"E00003901", "Instituto Nacional de Estadística"},
+	{"E05024401", "Dirección General de Tráfico"},
+}
+
+var fixtureThemes = []struct{ id, label string }{
+	{"sector-publico", "Sector público"},
+	{"turismo", "Turismo"},
+	{"salud", "Salud"},
+	{"medio-ambiente", "Medio ambiente"},
+	{"transporte", "Transporte"},
+	{"economia", "Economía"},
+	{"educacion", "Educación"},
+	{"cultura-ocio", "Cultura y ocio"},
+	{"demografia", "Demografía"},
+	{"empleo", "Empleo"},
+	{"hacienda", "Hacienda"},
+	{"urbanismo-infraestructuras", "Urbanismo e infraestructuras"},
+}
+
+var fixtureSpatials = []struct{ path, label, autonomy string }{
+	{"Pais/España", "España", ""},
+	{"Autonomia/Andalucia", "Andalucía", ""},
+	{"Autonomia/Aragon", "Aragón", ""},
+	{"Autonomia/Cataluna", "Cataluña", ""},
+	{"Autonomia/Comunidad-Madrid", "Comunidad de Madrid", ""},
+	{"Autonomia/Comunitat-Valenciana", "Comunitat Valenciana", ""},
+	{"Autonomia/Pais-Vasco", "País Vasco", ""},
+	{"Autonomia/Principado-Asturias", "Principado de Asturias", ""},
+	{"Provincia/Madrid", "Madrid", "Comunidad-Madrid"},
+	{"Provincia/Barcelona", "Barcelona", "Cataluna"},
+	{"Provincia/Zaragoza", "Zaragoza", "Aragon"},
+	{"Provincia/Huesca", "Huesca", "Aragon"},
+	{"Provincia/Sevilla", "Sevilla", "Andalucia"},
+	{"Provincia/Valencia", "Valencia", "Comunitat-Valenciana"},
+}
+
+type fixtureDataset struct {
+	id        string
+	title     string
+	publisher string
+	theme     string
+	spatial   string
+	keywords  []string
+	formats   []string
+	modified  time.Time
+}
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 10, 30, 0, 0, time.UTC)
+}
+
+var fixtureDatasets = []fixtureDataset{
+	{"l01280066-mirador-de-la-ciudad", "Mirador de la ciudad", "L01280066", "sector-publico", "Provincia/Madrid", []string{"turismo", "miradores"}, []string{"text/csv", "application/json"}, date(2016, time.May, 3)},
+	{"l01280066-presupuestos-municipales", "Presupuestos municipales", "L01280066", "sector-publico", "Provincia/Madrid", []string{"presupuestos"}, []string{"text/csv"}, date(2018, time.January, 15)},
+	{"l01280066-contratos-menores", "Contratos menores", "L01280066", "sector-publico", "Provincia/Madrid", []string{"contratos"}, []string{"application/xml"}, date(2016, time.June, 20)},
+	{"l01280796-calidad-del-aire", "Calidad del aire. Datos horarios", "L01280796", "medio-ambiente", "Provincia/Madrid", []string{"aire", "contaminación"}, []string{"text/csv", "application/xml"}, date(2019, time.March, 1)},
+	{"l01280796-alojamientos-turisticos", "Alojamientos turísticos", "L01280796", "turismo", "Provincia/Madrid", []string{"turismo", "hoteles"}, []string{"text/csv"}, date(2017, time.September, 8)},
+	{"l01080193-miradores-y-vistas", "Miradores y vistas de Barcelona", "L01080193", "turismo", "Provincia/Barcelona", []string{"turismo", "miradores"}, []string{"application/json"}, date(2015, time.November, 2)},
+	{"l01080193-padron-municipal", "Padrón municipal de habitantes", "L01080193", "demografia", "Provincia/Barcelona", []string{"población"}, []string{"text/csv"}, date(2016, time.April, 25)},
+	{"l01502973-autobuses-urbanos", "Paradas de autobuses urbanos", "L01502973", "transporte", "Provincia/Zaragoza", []string{"autobús", "transporte"}, []string{"application/json", "application/vnd.google-earth.kml+xml"}, date(2018, time.July, 30)},
+	{"a02002834-oficinas-de-turismo", "Oficinas de turismo de Aragón", "A02002834", "turismo", "Autonomia/Aragon", []string{"turismo"}, []string{"text/csv"}, date(2017, time.February, 14)},
+	{"a02002834-centros-de-salud", "Centros de salud de Aragón", "A02002834", "salud", "Autonomia/Aragon", []string{"salud", "centros"}, []string{"text/csv", "application/json"}, date(2016, time.May, 19)},
+	{"a02002834-espacios-naturales", "Espacios naturales protegidos", "A02002834", "medio-ambiente", "Autonomia/Aragon", []string{"naturaleza"}, []string{"application/x-zipped-shp"}, date(2014, time.October, 6)},
+	{"a09002970-equipaments-culturals", "Equipamientos culturales", "A09002970", "cultura-ocio", "Autonomia/Cataluna", []string{"cultura"}, []string{"text/csv"}, date(2019, time.January, 21)},
+	{"a16003011-ofertas-de-empleo", "Ofertas de empleo público", "A16003011", "empleo", "Autonomia/Pais-Vasco", []string{"empleo"}, []string{"application/xml"}, date(2018, time.December, 3)},
+	{"e00003901-poblacion-por-provincias", "Población por provincias", "E00003901", "demografia", "Pais/España", []string{"población", "censo"}, []string{"text/csv", "application/json"}, date(2019, time.February, 11)},
+	{"e00003901-pernoctaciones-hoteleras", "Pernoctaciones hoteleras", "E00003901", "turismo", "Pais/España", []string{"turismo", "hoteles"}, []string{"text/csv"}, date(2016, time.June, 29)},
+	{"e05024401-accidentes-de-trafico", "Accidentes de tráfico con víctimas", "E05024401", "transporte", "Pais/España", []string{"tráfico"}, []string{"text/csv", "application/xml"}, date(2017, time.May, 16)},
+}
+
+var (
+	weekdays = []string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"}
+	months   = []string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sep", "oct", "nov", "dic"}
+)
+
+// formatDate formats a date the way the API does.
+func formatDate(t time.Time) string {
+	return fmt.Sprintf(
+		"%s, %02d %s %d %s GMT+0000",
+		weekdays[t.Weekday()], t.Day(), months[t.Month()-1], t.Year(), t.Format("15:04:05"),
+	)
+}
+
+// item is an item of a response of the API.
+type item = map[string]interface{}
+
+func publisherItems() []item {
+	var result []item
+	for _, p := range fixturePublishers {
+		result = append(result, item{
+			"_about":    publisherURL + p.notation,
+			"notation":  p.notation,
+			"prefLabel": p.label,
+		})
+	}
+	return result
+}
+
+func themeItems() []item {
+	var result []item
+	for _, t := range fixtureThemes {
+		result = append(result, item{
+			"_about":    themeURL + t.id,
+			"notation":  t.id,
+			"prefLabel": []string{t.label},
+		})
+	}
+	return result
+}
+
+func spatialItems() []item {
+	var result []item
+	for _, s := range fixtureSpatials {
+		i := item{"_about": spatialURL + s.path, "label": s.label}
+		if s.autonomy != "" {
+			i["autonomia"] = spatialURL + "Autonomia/" + s.autonomy
+		}
+		result = append(result, i)
+	}
+	return result
+}
+
+func datasetItem(d fixtureDataset) item {
+	var dists []item
+	for i, f := range d.formats {
+		dists = append(dists, item{
+			"_about":    fmt.Sprintf("%s%s/resource/%d", datasetURL, d.id, i+1),
+			"accessURL": fmt.Sprintf("https://example.com/%s/%d", d.id, i+1),
+			"byteSize":  float64(1024 * (i + 1) * len(d.id)),
+			"title":     d.title,
+			"format": item{
+				"_about": "http://datos.gob.es/def/sector-publico/dcat#" + f,
+				"value":  f,
+			},
+		})
+	}
+
+	issued := d.modified.AddDate(-1, 0, 0)
+	return item{
+		"_about":       datasetURL + d.id,
+		"identifier":   datasetURL + d.id,
+		"title":        []string{d.title},
+		"description":  []item{{"text": "Conjunto de datos: " + d.title, "lang": "es"}},
+		"publisher":    publisherURL + d.publisher,
+		"theme":        []string{themeURL + d.theme},
+		"spatial":      spatialURL + d.spatial,
+		"keyword":      d.keywords,
+		"language":     "es",
+		"license":      "http://www.opendefinition.org/licenses/cc-by",
+		"issued":       formatDate(issued),
+		"modified":     formatDate(d.modified),
+		"distribution": dists,
+	}
+}
+
+// snapshot returns the fixtures as a snapshot of the catalog, so they can
+// be queried with a datos.OfflineClient.
+func snapshot() ([]byte, error) {
+	var lines []byte
+	add := func(typ string, items []item) error {
+		for _, i := range items {
+			line, err := json.Marshal(item{"type": typ, "item": i})
+			if err != nil {
+				return err
+			}
+			lines = append(append(lines, line...), '\n')
+		}
+		return nil
+	}
+
+	var datasets []item
+	for _, d := range fixtureDatasets {
+		datasets = append(datasets, datasetItem(d))
+	}
+
+	for _, s := range []struct {
+		typ   string
+		items []item
+	}{
+		{"publisher", publisherItems()},
+		{"theme", themeItems()},
+		{"spatial", spatialItems()},
+		{"dataset", datasets},
+	} {
+		if err := add(s.typ, s.items); err != nil {
+			return nil, err
+		}
+	}
+
+	return lines, nil
+}
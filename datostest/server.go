@@ -0,0 +1,277 @@
+// Package datostest provides a server that imitates the API of
+// datos.gob.es with a fixed catalog of fixtures, so programs using the
+// datos package can be tested without network access.
+package datostest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erizocosmico/datos"
+)
+
+// Server is an HTTP server that imitates the API. It serves a catalog of
+// fixtures with 12 publishers, 12 themes, 14 spatials and 16 datasets with
+// their distributions, filtered and paged the same way the API does. The
+// response of any path can be replaced with Respond.
+type Server struct {
+	*httptest.Server
+	catalog *datos.OfflineClient
+	// items of the fixtures by link, as sent by the API.
+	items map[string]item
+
+	mut       sync.Mutex
+	responses map[string]response
+}
+
+type response struct {
+	status int
+	body   string
+}
+
+// NewServer starts a new Server. It must be closed when it's no longer
+// needed.
+func NewServer() *Server {
+	data, err := snapshot()
+	if err != nil {
+		panic(err)
+	}
+
+	catalog, err := datos.ReadSnapshot(bytes.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+
+	s := &Server{
+		catalog:   catalog,
+		items:     make(map[string]item),
+		responses: make(map[string]response),
+	}
+
+	for _, items := range [][]item{publisherItems(), themeItems(), spatialItems()} {
+		for _, i := range items {
+			s.items[i["_about"].(string)] = i
+		}
+	}
+
+	for _, d := range fixtureDatasets {
+		i := datasetItem(d)
+		s.items[i["_about"].(string)] = i
+		for _, dist := range i["distribution"].([]item) {
+			s.items[dist["_about"].(string)] = dist
+		}
+	}
+
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// NewClient returns a client that sends its requests to the server.
+func (s *Server) NewClient(opts ...datos.Option) (*datos.Client, error) {
+	opts = append(
+		[]datos.Option{datos.WithHTTPClient(s.Server.Client()), datos.WithBaseURL(s.URL)},
+		opts...,
+	)
+	return datos.NewClient(opts...)
+}
+
+// Respond makes the server respond to the requests of the given API path,
+// e.g. "/catalog/dataset", with the given status and body instead of the
+// fixtures.
+func (s *Server) Respond(path string, status int, body string) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.responses[path] = response{status, body}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mut.Lock()
+	resp, ok := s.responses[r.URL.Path]
+	s.mut.Unlock()
+	if ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(resp.status)
+		_, _ = w.Write([]byte(resp.body))
+		return
+	}
+
+	var page datos.Page
+	ctx := datos.CapturePage(r.Context(), &page)
+	abouts, ok := s.query(ctx, r.URL.Path, params(r))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	items := make([]item, 0, len(abouts))
+	for _, about := range abouts {
+		items = append(items, s.items[about])
+	}
+
+	result := item{
+		"_about": s.URL + r.URL.String(),
+		"items":  items,
+	}
+
+	// Responses of a single item have no paging information.
+	if page.Size > 0 {
+		result["first"] = s.pageURL(r, 0)
+		result["page"] = page.Number
+		result["itemsPerPage"] = page.Size
+		result["startIndex"] = page.Number*page.Size + 1
+		result["totalItems"] = page.Total
+		if page.Number > 0 {
+			result["prev"] = s.pageURL(r, page.Number-1)
+		}
+		if page.HasNext() {
+			result["next"] = s.pageURL(r, page.Number+1)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(item{
+		"format":  "linked-data-api",
+		"version": "0.2",
+		"result":  result,
+	})
+}
+
+// pageURL returns the link to the given page of the request.
+func (s *Server) pageURL(r *http.Request, page uint) string {
+	q := r.URL.Query()
+	q.Set("_page", strconv.FormatUint(uint64(page), 10))
+	u := *r.URL
+	u.RawQuery = q.Encode()
+	return s.URL + u.String()
+}
+
+func params(r *http.Request) datos.Params {
+	q := r.URL.Query()
+	page, _ := strconv.ParseUint(q.Get("_page"), 10, 32)
+	size, _ := strconv.ParseUint(q.Get("_pageSize"), 10, 32)
+	return datos.Params{Sort: q.Get("_sort"), Page: uint(page), PageSize: uint(size)}
+}
+
+// query returns the links of the items of the given path. The second
+// result is false if the path does not exist in the API.
+func (s *Server) query(ctx context.Context, path string, p datos.Params) ([]string, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "catalog" {
+		return nil, false
+	}
+
+	c := s.catalog
+	switch parts[1] {
+	case "publisher":
+		ps, _ := c.Publishers(ctx, p)
+		var result []string
+		for _, x := range ps {
+			result = append(result, x.About)
+		}
+		return result, len(parts) == 2
+	case "theme":
+		ts, _ := c.Themes(ctx, p)
+		var result []string
+		for _, x := range ts {
+			result = append(result, x.About)
+		}
+		return result, len(parts) == 2
+	case "spatial":
+		ss, _ := c.Spatials(ctx, p)
+		var result []string
+		for _, x := range ss {
+			result = append(result, x.About)
+		}
+		return result, len(parts) == 2
+	case "dataset":
+		ds, ok := s.datasets(ctx, parts[2:], p)
+		var result []string
+		for _, x := range ds {
+			result = append(result, x.About)
+		}
+		return result, ok
+	case "distribution":
+		var ds []datos.Distribution
+		switch {
+		case len(parts) == 2:
+			ds, _ = c.Distributions(ctx, p)
+		case len(parts) == 4 && parts[2] == "format":
+			ds, _ = c.DistributionsByFormat(ctx, parts[3], p)
+		case len(parts) == 4 && parts[2] == "dataset":
+			ds, _ = c.DistributionsByDataset(ctx, parts[3], p)
+		default:
+			return nil, false
+		}
+
+		var result []string
+		for _, x := range ds {
+			result = append(result, x.About)
+		}
+		return result, true
+	default:
+		return nil, false
+	}
+}
+
+// datasets returns the datasets of the path after /catalog/dataset.
+func (s *Server) datasets(ctx context.Context, parts []string, p datos.Params) ([]datos.Dataset, bool) {
+	c := s.catalog
+	switch {
+	case len(parts) == 0:
+		ds, _ := c.Datasets(ctx, p)
+		return ds, true
+	case len(parts) == 1:
+		d, err := c.Dataset(ctx, parts[0], p)
+		if err != nil {
+			return nil, true
+		}
+		return []datos.Dataset{d}, true
+	case len(parts) == 2 && parts[0] == "title":
+		ds, _ := c.DatasetsByTitle(ctx, parts[1], p)
+		return ds, true
+	case len(parts) == 2 && parts[0] == "publisher":
+		ds, _ := c.DatasetsByPublisher(ctx, parts[1], p)
+		return ds, true
+	case len(parts) == 2 && parts[0] == "theme":
+		ds, _ := c.DatasetsByTheme(ctx, parts[1], p)
+		return ds, true
+	case len(parts) == 2 && parts[0] == "format":
+		ds, _ := c.DatasetsByFormat(ctx, parts[1], p)
+		return ds, true
+	case len(parts) == 2 && parts[0] == "keyword":
+		ds, _ := c.DatasetsByKeyword(ctx, parts[1], p)
+		return ds, true
+	case len(parts) == 3 && parts[0] == "spatial":
+		for _, typ := range []datos.SpatialType{datos.Autonomy, datos.Country, datos.Province} {
+			if typ.String() == parts[1] {
+				ds, _ := c.DatasetsBySpatial(ctx, typ, parts[2], p)
+				return ds, true
+			}
+		}
+		return nil, true
+	case len(parts) == 5 && (parts[0] == "modified" || parts[0] == "issued") && parts[1] == "begin" && parts[3] == "end":
+		from, err1 := time.Parse(time.RFC3339, parts[2])
+		to, err2 := time.Parse(time.RFC3339, parts[4])
+		if err1 != nil || err2 != nil {
+			return nil, false
+		}
+
+		if parts[0] == "issued" {
+			ds, _ := c.DatasetsIssuedBetween(ctx, from, to, p)
+			return ds, true
+		}
+
+		ds, _ := c.DatasetsModifiedBetween(ctx, from, to, p)
+		return ds, true
+	default:
+		return nil, false
+	}
+}
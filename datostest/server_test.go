@@ -0,0 +1,65 @@
+package datostest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/erizocosmico/datos"
+)
+
+func TestServer(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var page datos.Page
+	ctx := datos.CapturePage(context.Background(), &page)
+	var all []datos.Dataset
+	params := datos.Params{PageSize: 5}
+	for {
+		ds, err := c.Datasets(ctx, params)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if page.Total != len(fixtureDatasets) || page.Number != params.Page || page.First == "" {
+			t.Errorf("wrong page: %+v", page)
+		}
+
+		all = append(all, ds...)
+		if !page.HasNext() {
+			break
+		}
+		params.Page++
+	}
+
+	if len(all) != len(fixtureDatasets) {
+		t.Errorf("wrong number of datasets, expected: %d, got: %d", len(fixtureDatasets), len(all))
+	}
+
+	for i, d := range all {
+		if !d.Modified.Equal(fixtureDatasets[i].modified) {
+			t.Errorf("wrong modified date of %s: %s", d.About, d.Modified)
+		}
+	}
+
+	ds, err := c.Query().Theme("turismo").Format("csv").Params(datos.Params{PageSize: 50}).Do(ctx)
+	if err != nil || len(ds) != 3 {
+		t.Errorf("wrong number of tourism datasets in csv: %d, err: %v", len(ds), err)
+	}
+
+	if _, err := c.Dataset(ctx, "foo", datos.Params{}); !errors.Is(err, datos.ErrNotFound) {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+
+	s.Respond("/catalog/theme", http.StatusServiceUnavailable, "")
+	if _, err := c.Themes(ctx, datos.Params{}); !errors.Is(err, datos.ErrUpstreamUnavailable) {
+		t.Errorf("expected upstream unavailable error, got: %v", err)
+	}
+}
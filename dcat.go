@@ -0,0 +1,365 @@
+package datos
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ReadDCAT reads the datasets of a DCAT-AP catalog serialized as JSON-LD,
+// such as the ones published by most open data portals, so they can be
+// queried along with the datasets of a snapshot with OfflineClient.Import.
+// Both the compacted and expanded forms of JSON-LD can be read.
+//
+// Properties are matched by their local name, so they can be given with
+// full IRIs, prefixed names or terms defined in the @context of the
+// document, but remote contexts are not fetched. Distributions, themes and
+// publishers can either be nested in the datasets or referenced by their
+// @id.
+func ReadDCAT(r io.Reader) ([]Dataset, error) {
+	doc, err := parseJSONLD(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := doc.datasets()
+	if len(result) == 0 {
+		return nil, newError(ErrNotFound, nil, "datos: no datasets found in JSON-LD document")
+	}
+
+	return result, nil
+}
+
+// parseJSONLD decodes a JSON-LD document and collects all its nodes.
+func parseJSONLD(r io.Reader) (*jsonldDoc, error) {
+	var data interface{}
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return nil, newError(ErrDecoding, err, "datos: invalid JSON-LD document: %s", err)
+	}
+
+	doc := &jsonldDoc{
+		nodes:   make(map[string]map[string]interface{}),
+		aliases: make(map[string]string),
+	}
+
+	var collect func(v interface{})
+	collect = func(v interface{}) {
+		switch v := v.(type) {
+		case []interface{}:
+			for _, e := range v {
+				collect(e)
+			}
+		case map[string]interface{}:
+			if ctx, ok := v["@context"]; ok {
+				doc.addContext(ctx)
+			}
+
+			doc.all = append(doc.all, v)
+			if id, ok := v["@id"].(string); ok && len(v) > 1 {
+				doc.nodes[id] = v
+			}
+
+			for k, e := range v {
+				if k != "@context" {
+					collect(e)
+				}
+			}
+		}
+	}
+	collect(data)
+
+	return doc, nil
+}
+
+// datasets returns the nodes of the document with the Dataset type.
+func (d *jsonldDoc) datasets() []Dataset {
+	var result []Dataset
+	seen := make(map[string]bool)
+	for _, n := range d.all {
+		node := dcatNode{n, d}
+		if !node.hasType("Dataset") {
+			continue
+		}
+
+		ds := dcatDataset(node)
+		if key := ds.Key(); !seen[key] || key == "" {
+			seen[key] = true
+			result = append(result, ds)
+		}
+	}
+	return result
+}
+
+// jsonldDoc has all the nodes of a JSON-LD document in the order they
+// appear, the ones with an @id indexed so references can be resolved, and
+// the local names of the terms defined in its contexts.
+type jsonldDoc struct {
+	all     []map[string]interface{}
+	nodes   map[string]map[string]interface{}
+	aliases map[string]string
+}
+
+// addContext adds the terms defined in the context, which can be an
+// object or a list of them. Remote contexts are ignored.
+func (d *jsonldDoc) addContext(ctx interface{}) {
+	switch ctx := ctx.(type) {
+	case []interface{}:
+		for _, c := range ctx {
+			d.addContext(c)
+		}
+	case map[string]interface{}:
+		for term, def := range ctx {
+			if m, ok := def.(map[string]interface{}); ok {
+				def = m["@id"]
+			}
+
+			// Prefixes are also defined as terms, but they're IRIs ending
+			// with / or #, so they have no local name.
+			if iri, ok := def.(string); ok && localName(iri) != "" {
+				d.aliases[term] = localName(iri)
+			}
+		}
+	}
+}
+
+// name returns the local name of a key of a node.
+func (d *jsonldDoc) name(key string) string {
+	if alias, ok := d.aliases[key]; ok {
+		return alias
+	}
+	return localName(key)
+}
+
+// dcatNode is a JSON-LD node of a document.
+type dcatNode struct {
+	props map[string]interface{}
+	doc   *jsonldDoc
+}
+
+// values returns the values of the property with the given local name.
+func (n dcatNode) values(name string) []interface{} {
+	for k, v := range n.props {
+		if !strings.HasPrefix(k, "@") && n.doc.name(k) == name {
+			return flatten(v)
+		}
+	}
+	return nil
+}
+
+// flatten returns the values of a property, which can be a single value, an
+// array or a @list or @set object.
+func flatten(v interface{}) []interface{} {
+	if m, ok := v.(map[string]interface{}); ok {
+		if list, ok := m["@list"]; ok {
+			return flatten(list)
+		}
+		if set, ok := m["@set"]; ok {
+			return flatten(set)
+		}
+	}
+
+	list, ok := v.([]interface{})
+	if !ok {
+		return []interface{}{v}
+	}
+
+	var result []interface{}
+	for _, e := range list {
+		result = append(result, flatten(e)...)
+	}
+	return result
+}
+
+// strings returns the values of the property as strings, using the @value
+// of literals and the @id of references.
+func (n dcatNode) strings(name string) []string {
+	var result []string
+	for _, v := range n.values(name) {
+		if s := literal(v); s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// langStrings returns the literals of the property with their language.
+func (n dcatNode) langStrings(name string) LangStrings {
+	var result LangStrings
+	for _, v := range n.values(name) {
+		text := literal(v)
+		if text == "" {
+			continue
+		}
+
+		var lang string
+		if m, ok := v.(map[string]interface{}); ok {
+			lang, _ = m["@language"].(string)
+		}
+		result = append(result, LangString{text, lang})
+	}
+	return result
+}
+
+func (n dcatNode) string(name string) string {
+	if s := n.strings(name); len(s) > 0 {
+		return s[0]
+	}
+	return ""
+}
+
+// children returns the nodes of the property, resolving references.
+func (n dcatNode) children(name string) []dcatNode {
+	var result []dcatNode
+	for _, v := range n.values(name) {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if id, ok := m["@id"].(string); ok {
+			if node, ok := n.doc.nodes[id]; ok {
+				m = node
+			}
+		}
+		result = append(result, dcatNode{m, n.doc})
+	}
+	return result
+}
+
+// hasType reports whether the node has the given type.
+func (n dcatNode) hasType(typ string) bool {
+	for _, t := range flatten(n.props["@type"]) {
+		if s, ok := t.(string); ok && n.doc.name(s) == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func dcatDataset(n dcatNode) Dataset {
+	d := Dataset{
+		About:      literal(n.props),
+		Identifier: n.string("identifier"),
+		Title:      n.langStrings("title"),
+		Keywords:   n.strings("keyword"),
+		Theme:      n.strings("theme"),
+		Spatial:    n.strings("spatial"),
+		License:    License(n.string("license")),
+		Language:   n.string("language"),
+		Modified:   dcatTime(n.string("modified")),
+		Issued:     dcatTime(n.string("issued")),
+	}
+
+	if ids := n.strings("identifier"); len(ids) > 1 {
+		d.Identifiers = ids
+	}
+
+	if d.Identifier == "" {
+		d.Identifier = d.About
+	}
+
+	d.ConformsTo = n.strings("conformsTo")
+	d.References = n.strings("references")
+	d.LandingPage = n.strings("landingPage")
+	d.Page = n.strings("page")
+	d.AccessRights = n.string("accessRights")
+	d.Provenance = n.string("provenance")
+	d.Version = n.string("version")
+	if d.Version == "" {
+		d.Version = n.string("versionInfo")
+	}
+	d.HasVersion = n.strings("hasVersion")
+	d.IsVersionOf = n.strings("isVersionOf")
+	d.Source = n.strings("source")
+	d.AccrualPeriodicity.About = n.string("accrualPeriodicity")
+
+	for _, cn := range n.children("contactPoint") {
+		about := literal(cn.props)
+		if strings.HasPrefix(about, "_:") {
+			about = ""
+		}
+
+		d.ContactPoint = append(d.ContactPoint, ContactPoint{
+			About: about,
+			Name:  cn.string("fn"),
+			Email: strings.TrimPrefix(cn.string("hasEmail"), "mailto:"),
+			URL:   cn.string("hasURL"),
+		})
+	}
+
+	if periods := n.children("temporal"); len(periods) > 0 {
+		p := periods[0]
+		d.Temporal = PeriodOfTime{
+			About: literal(p.props),
+			Start: dcatTime(p.string("startDate")),
+			End:   dcatTime(p.string("endDate")),
+		}
+		d.Temporal.End.Time = endOfDay(d.Temporal.End.raw, d.Temporal.End.Time)
+	} else {
+		d.Temporal = parsePeriodOfTime(n.string("temporal"))
+	}
+
+	if pubs := n.children("publisher"); len(pubs) > 0 && literal(pubs[0].props) != "" {
+		d.Publisher = literal(pubs[0].props)
+	} else {
+		d.Publisher = n.string("publisher")
+	}
+
+	d.Description = n.langStrings("description")
+
+	for _, dn := range n.children("distribution") {
+		var dist Distribution
+		dist.About, _ = dn.props["@id"].(string)
+		dist.AccessURL = dn.string("downloadURL")
+		if dist.AccessURL == "" {
+			dist.AccessURL = dn.string("accessURL")
+		}
+
+		dist.Format.Value = dn.string("mediaType")
+		if dist.Format.Value == "" {
+			dist.Format.Value = dn.string("format")
+		}
+
+		fmt.Sscan(dn.string("byteSize"), &dist.ByteSize)
+		dist.Title = dn.strings("title")
+		dist.Identifier = dn.string("identifier")
+		d.Distribution = append(d.Distribution, dist)
+	}
+
+	return d
+}
+
+// literal returns the value of a literal or the @id of a node.
+func literal(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return fmt.Sprint(v)
+	case map[string]interface{}:
+		if s, ok := v["@value"]; ok {
+			return literal(s)
+		}
+		if s, ok := v["@id"].(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// localName returns the name of a property without its namespace, e.g.
+// "title" for "dct:title" or "http://purl.org/dc/terms/title".
+func localName(iri string) string {
+	if i := strings.LastIndexAny(iri, "/#:"); i >= 0 {
+		return iri[i+1:]
+	}
+	return iri
+}
+
+// dcatTime parses a date of a DCAT catalog, which can have just the date
+// or the time as well.
+func dcatTime(s string) Datetime {
+	t, _ := parseDatetime(s)
+	return Datetime{Time: t, raw: s}
+}
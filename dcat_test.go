@@ -0,0 +1,121 @@
+package datos
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testDCAT = `{
+	"@context": {"dcat": "http://www.w3.org/ns/dcat#", "dct": "http://purl.org/dc/terms/"},
+	"@graph": [
+		{
+			"@id": "https://example.com/catalog",
+			"@type": "dcat:Catalog",
+			"dcat:dataset": [{"@id": "https://example.com/dataset/air"}]
+		},
+		{
+			"@id": "https://example.com/dataset/air",
+			"@type": "dcat:Dataset",
+			"dct:identifier": "air",
+			"dct:title": [{"@value": "Calidad del aire", "@language": "es"}, {"@value": "Air quality", "@language": "en"}],
+			"dct:description": {"@value": "Mediciones", "@language": "es"},
+			"dcat:keyword": ["aire", "contaminación"],
+			"dcat:theme": {"@id": "http://datos.gob.es/kos/sector-publico/sector/medio-ambiente"},
+			"dct:publisher": {"@id": "https://example.com/org"},
+			"dct:modified": {"@value": "2019-03-01", "@type": "xsd:date"},
+			"dcat:distribution": [{"@id": "https://example.com/dist/air-csv"}]
+		},
+		{
+			"@id": "https://example.com/dist/air-csv",
+			"@type": "dcat:Distribution",
+			"dcat:downloadURL": {"@id": "https://example.com/air.csv"},
+			"dcat:mediaType": "text/csv",
+			"dcat:byteSize": 1024
+		}
+	]
+}`
+
+const testDCATFull = `[{
+	"@id": "https://example.com/dataset/water",
+	"@type": ["http://www.w3.org/ns/dcat#Dataset"],
+	"http://purl.org/dc/terms/title": "Water",
+	"http://purl.org/dc/terms/issued": "2018-01-02T10:00:00Z",
+	"http://www.w3.org/ns/dcat#distribution": {
+		"http://www.w3.org/ns/dcat#accessURL": "https://example.com/water.json",
+		"http://purl.org/dc/terms/format": "application/json"
+	}
+}]`
+
+func TestReadDCAT(t *testing.T) {
+	ds, err := ReadDCAT(strings.NewReader(testDCAT))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(ds) != 1 {
+		t.Fatalf("wrong number of datasets, expected: 1, got: %d", len(ds))
+	}
+
+	d := ds[0]
+	if d.About != "https://example.com/dataset/air" || d.Identifier != "air" ||
+		strings.Join(d.Title.Texts(), "|") != "Calidad del aire|Air quality" ||
+		len(d.Keywords) != 2 || d.Publisher != "https://example.com/org" ||
+		!d.Modified.Equal(time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("wrong dataset: %+v", d)
+	}
+
+	if len(d.Description) != 1 || d.Description[0].Text != "Mediciones" || d.Description[0].Lang != "es" {
+		t.Errorf("wrong description: %+v", d.Description)
+	}
+
+	if len(d.Distribution) != 1 || d.Distribution[0].AccessURL != "https://example.com/air.csv" ||
+		d.Distribution[0].Format.Value != "text/csv" || d.Distribution[0].ByteSize != 1024 {
+		t.Errorf("wrong distributions: %+v", d.Distribution)
+	}
+
+	ds, err = ReadDCAT(strings.NewReader(testDCATFull))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(ds) != 1 || ds[0].Title.Default() != "Water" || ds[0].Issued.Year() != 2018 ||
+		len(ds[0].Distribution) != 1 || ds[0].Distribution[0].AccessURL != "https://example.com/water.json" {
+		t.Errorf("wrong datasets: %+v", ds)
+	}
+
+	_, err = ReadDCAT(strings.NewReader(`{"@graph": []}`))
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+}
+
+func TestOfflineClientImport(t *testing.T) {
+	c, err := ReadSnapshot(strings.NewReader(
+		`{"type":"dataset","item":{"_about":"http://datos.gob.es/catalogo/foo","title":"Foo","distribution":{"accessURL":"http://example.com/foo.csv","format":{"value":"text/csv"}}}}`,
+	))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ds, err := ReadDCAT(strings.NewReader(testDCAT))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c.Import(ds...)
+	c.Import(ds...)
+
+	ctx := context.Background()
+	result, _ := c.DatasetsByFormat(ctx, "csv", Params{})
+	if len(result) != 2 {
+		t.Errorf("expected imported dataset along with the snapshot ones, got: %d", len(result))
+	}
+
+	result, _ = c.DatasetsByTheme(ctx, "medio-ambiente", Params{})
+	if len(result) != 1 || result[0].Identifier != "air" {
+		t.Errorf("wrong datasets by theme: %v", result)
+	}
+}
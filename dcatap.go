@@ -0,0 +1,424 @@
+package datos
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContactPoint is the contact information of a dataset, a vCard with the
+// name, email and web page of the contact.
+type ContactPoint struct {
+	About string `json:"_about,omitempty"`
+	Name  string `json:"fn,omitempty"`
+	Email string `json:"hasEmail,omitempty"`
+	URL   string `json:"hasURL,omitempty"`
+}
+
+// ContactPoints is a slice of contact points.
+type ContactPoints []ContactPoint
+
+// UnmarshalJSON decodes either a single contact point or a list of them.
+// Contact points can be links to the contact or objects with the fn,
+// hasEmail and hasURL keys, with or without namespace. The mailto: scheme
+// of emails is removed. Null decodes to no contact points.
+func (s *ContactPoints) UnmarshalJSON(b []byte) error {
+	var val interface{}
+	if err := json.Unmarshal(b, &val); err != nil {
+		return err
+	}
+
+	values, ok := val.([]interface{})
+	if !ok {
+		values = []interface{}{val}
+	}
+
+	for _, v := range values {
+		var cp ContactPoint
+		switch v := v.(type) {
+		case nil:
+			continue
+		case string:
+			cp.About = v
+		case map[string]interface{}:
+			cp.About, _ = v["_about"].(string)
+			for _, k := range objectKeys(v) {
+				kv := v[k]
+				var dst *string
+				switch localName(k) {
+				case "fn", "name", "organization-name":
+					dst = &cp.Name
+				case "hasEmail", "email":
+					dst = &cp.Email
+				case "hasURL", "url":
+					dst = &cp.URL
+				default:
+					continue
+				}
+
+				if list, ok := kv.([]interface{}); ok && len(list) > 0 {
+					kv = list[0]
+				}
+
+				str, err := stringValue(kv)
+				if err != nil {
+					return fmt.Errorf("error decoding contact point %s: %s", k, err)
+				}
+
+				if *dst == "" {
+					*dst = str
+				}
+			}
+			cp.Email = strings.TrimPrefix(cp.Email, "mailto:")
+		default:
+			return fmt.Errorf("expecting contact point string, object or array, got %T", v)
+		}
+
+		*s = append(*s, cp)
+	}
+
+	return nil
+}
+
+// Frequency is the frequency with which a dataset is updated. About is
+// the link to the frequency, such as http://purl.org/cld/freq/daily, and
+// the rest of the fields are the duration between updates, if given.
+type Frequency struct {
+	About   string  `json:"_about,omitempty"`
+	Years   float64 `json:"years,omitempty"`
+	Months  float64 `json:"months,omitempty"`
+	Weeks   float64 `json:"weeks,omitempty"`
+	Days    float64 `json:"days,omitempty"`
+	Hours   float64 `json:"hours,omitempty"`
+	Minutes float64 `json:"minutes,omitempty"`
+	Seconds float64 `json:"seconds,omitempty"`
+}
+
+// IsZero reports whether the dataset has no frequency.
+func (f Frequency) IsZero() bool {
+	return f == Frequency{}
+}
+
+// Duration returns the duration between updates as an ISO 8601 duration,
+// such as P1D or PT12H, or an empty string if it's not given.
+func (f Frequency) Duration() string {
+	var date, time string
+	for _, p := range []struct {
+		v      float64
+		suffix string
+		time   bool
+	}{
+		{f.Years, "Y", false},
+		{f.Months, "M", false},
+		{f.Weeks, "W", false},
+		{f.Days, "D", false},
+		{f.Hours, "H", true},
+		{f.Minutes, "M", true},
+		{f.Seconds, "S", true},
+	} {
+		if p.v == 0 {
+			continue
+		}
+
+		s := strconv.FormatFloat(p.v, 'f', -1, 64) + p.suffix
+		if p.time {
+			time += s
+		} else {
+			date += s
+		}
+	}
+
+	if date == "" && time == "" {
+		return ""
+	}
+
+	if time != "" {
+		time = "T" + time
+	}
+	return "P" + date + time
+}
+
+// String returns the duration between updates or the link to the
+// frequency if there is no duration.
+func (f Frequency) String() string {
+	if d := f.Duration(); d != "" {
+		return d
+	}
+	return f.About
+}
+
+// UnmarshalJSON decodes a frequency, which can be a link to it or an
+// object with the duration between updates, as time:DurationDescription
+// fields either in the object or in its value.
+func (f *Frequency) UnmarshalJSON(b []byte) error {
+	var val interface{}
+	if err := json.Unmarshal(b, &val); err != nil {
+		return err
+	}
+
+	*f = Frequency{}
+	switch v := val.(type) {
+	case nil:
+		return nil
+	case string:
+		f.About = v
+		return nil
+	case map[string]interface{}:
+		f.About, _ = v["_about"].(string)
+		return f.decodeDuration(v)
+	default:
+		return fmt.Errorf("expecting frequency string or object, got %T", v)
+	}
+}
+
+func (f *Frequency) decodeDuration(m map[string]interface{}) error {
+	fields := map[string]*float64{
+		"years":   &f.Years,
+		"months":  &f.Months,
+		"weeks":   &f.Weeks,
+		"days":    &f.Days,
+		"hours":   &f.Hours,
+		"minutes": &f.Minutes,
+		"seconds": &f.Seconds,
+	}
+
+	for k, v := range m {
+		name := localName(k)
+		if name == "value" || name == "_value" {
+			if nested, ok := v.(map[string]interface{}); ok {
+				if err := f.decodeDuration(nested); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		field, ok := fields[name]
+		if !ok {
+			continue
+		}
+
+		switch v := v.(type) {
+		case float64:
+			*field = v
+		case string:
+			n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return fmt.Errorf("invalid frequency %s %q", name, v)
+			}
+			*field = n
+		default:
+			return fmt.Errorf("expecting number for frequency %s, got %T", name, v)
+		}
+	}
+
+	return nil
+}
+
+// PeriodOfTime is the time period covered by a dataset. About is the link
+// to the period or its description if it's just text, and Start and End
+// are its bounds, if given or if they can be parsed from the text. Both
+// bounds are included in the period, and End is its last instant, so ends
+// given as a year or a day without time are the last nanosecond of it.
+type PeriodOfTime struct {
+	About string   `json:"_about,omitempty"`
+	Start Datetime `json:"startDate"`
+	End   Datetime `json:"endDate"`
+}
+
+// IsZero reports whether the dataset has no time period.
+func (p PeriodOfTime) IsZero() bool {
+	return p.About == "" && p.Start.IsZero() && p.End.IsZero()
+}
+
+// Contains reports whether the time is in the period. Periods without
+// start or end are open on that side, and periods without bounds contain
+// no time.
+func (p PeriodOfTime) Contains(t time.Time) bool {
+	if p.Start.IsZero() && p.End.IsZero() {
+		return false
+	}
+	return !t.Before(p.Start.Time) && (p.End.IsZero() || !t.After(p.End.Time))
+}
+
+// UnmarshalJSON decodes a period of time, which can be a string or an
+// object with its bounds in the startDate and endDate keys, or in the
+// hasBeginning and hasEnd instants of OWL-Time. The bounds of strings are
+// parsed if they're an ISO 8601 interval, such as 2010-01-01/2015-12-31,
+// a range of years, such as 2010-2015, or a year.
+func (p *PeriodOfTime) UnmarshalJSON(b []byte) error {
+	var val interface{}
+	if err := json.Unmarshal(b, &val); err != nil {
+		return err
+	}
+
+	*p = PeriodOfTime{}
+	switch v := val.(type) {
+	case nil:
+		return nil
+	case string:
+		*p = parsePeriodOfTime(v)
+		return nil
+	case map[string]interface{}:
+		p.About, _ = v["_about"].(string)
+		for k, kv := range v {
+			var dst *Datetime
+			switch localName(k) {
+			case "startDate", "start", "beginning", "hasBeginning":
+				dst = &p.Start
+			case "endDate", "end", "hasEnd":
+				dst = &p.End
+			default:
+				continue
+			}
+
+			s := instant(kv)
+			t, err := parseDatetime(s)
+			if err != nil {
+				return fmt.Errorf("error decoding period of time %s: %s", k, err)
+			}
+
+			if dst == &p.End {
+				t = endOfDay(s, t)
+			}
+			*dst = Datetime{Time: t, raw: s}
+		}
+		return nil
+	default:
+		return fmt.Errorf("expecting period of time string or object, got %T", v)
+	}
+}
+
+var yearRange = regexp.MustCompile(`^(\d{4})(?:\s*[-/]\s*(\d{4}))?$`)
+
+// parsePeriodOfTime returns the period of time described by the text,
+// with its bounds if they can be parsed. Years start on January 1st and
+// end on the last instant of December 31st.
+func parsePeriodOfTime(s string) PeriodOfTime {
+	p := PeriodOfTime{About: s}
+	text := strings.TrimSpace(s)
+	if m := yearRange.FindStringSubmatch(text); m != nil {
+		last := m[1]
+		if m[2] != "" {
+			last = m[2]
+		}
+
+		start, _ := strconv.Atoi(m[1])
+		end, _ := strconv.Atoi(last)
+		p.Start = Datetime{Time: time.Date(start, time.January, 1, 0, 0, 0, 0, time.UTC), raw: m[1]}
+		p.End = Datetime{Time: time.Date(end+1, time.January, 1, 0, 0, 0, -1, time.UTC), raw: last}
+		return p
+	}
+
+	parts := strings.Split(text, "/")
+	if len(parts) != 2 {
+		return p
+	}
+
+	start, err := parseDatetime(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return p
+	}
+
+	end, err := parseDatetime(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return p
+	}
+
+	p.Start = Datetime{Time: start, raw: strings.TrimSpace(parts[0])}
+	p.End = Datetime{Time: endOfDay(strings.TrimSpace(parts[1]), end), raw: strings.TrimSpace(parts[1])}
+	return p
+}
+
+var dateOnly = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// endOfDay returns the last instant of the day of t if the date it was
+// parsed from has no time, so the whole day is in a period ending on it.
+func endOfDay(s string, t time.Time) time.Time {
+	if !dateOnly.MatchString(strings.TrimSpace(s)) {
+		return t
+	}
+	return t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
+
+// instant returns the date of a bound of a period of time, which can be a
+// string or an OWL-Time instant with the date in one of its keys.
+func instant(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		for k, kv := range v {
+			switch localName(k) {
+			case "inXSDDateTime", "inXSDDateTimeStamp", "inXSDDate", "_value", "value":
+				return instant(kv)
+			}
+		}
+	}
+	return ""
+}
+
+// decodeDataset decodes a dataset, skipping its distributions if lazy is
+// true. The identifier can be a list, in which case Identifier is the
+// first one, and the keys that are not fields of the dataset are kept in
+// Extra.
+func decodeDataset(b []byte, d *Dataset, lazy bool) error {
+	var fields struct {
+		*datasetFields
+		Identifier   Strings         `json:"identifier"`
+		Distribution json.RawMessage `json:"distribution"`
+	}
+	fields.datasetFields = (*datasetFields)(d)
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+
+	if len(fields.Identifier) > 0 {
+		d.Identifier = fields.Identifier[0]
+	}
+	if len(fields.Identifier) > 1 {
+		d.Identifiers = fields.Identifier
+	}
+
+	if !lazy && len(fields.Distribution) > 0 {
+		if err := json.Unmarshal(fields.Distribution, &d.Distribution); err != nil {
+			return err
+		}
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(b, &all); err != nil {
+		return err
+	}
+
+	for k, v := range all {
+		if datasetKeys[k] {
+			continue
+		}
+
+		if d.Extra == nil {
+			d.Extra = make(map[string]json.RawMessage)
+		}
+		d.Extra[k] = v
+	}
+
+	return nil
+}
+
+// datasetKeys are the JSON keys of the fields of Dataset.
+var datasetKeys = jsonKeys(reflect.TypeOf(Dataset{}))
+
+func jsonKeys(t reflect.Type) map[string]bool {
+	keys := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}
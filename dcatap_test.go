@@ -0,0 +1,250 @@
+package datos
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const fullDataset = `{"result":{"items":[{
+	"_about":"http://datos.gob.es/catalogo/a",
+	"identifier":["http://datos.gob.es/catalogo/a","urn:a"],
+	"title":"Calidad del aire",
+	"contactPoint":{
+		"_about":"http://example.com/contact",
+		"fn":"Servicio de datos",
+		"hasEmail":"mailto:datos@example.com",
+		"vcard:hasURL":{"_about":"http://example.com"}
+	},
+	"landingPage":"http://example.com/aire",
+	"accrualPeriodicity":{
+		"_about":"http://example.com/freq",
+		"value":{"type":"http://www.w3.org/2006/time#DurationDescription","time:days":"1","hours":12}
+	},
+	"temporal":{
+		"startDate":"2019-01-01T00:00:00Z",
+		"hasEnd":{"inXSDDateTime":"2019-12-31T00:00:00Z"}
+	},
+	"conformsTo":["http://example.com/norma"],
+	"accessRights":"http://example.com/public",
+	"distribution":[{"_about":"dist","format":{"value":"text/csv"}}],
+	"extension":{"quality":5},
+	"type":"http://www.w3.org/ns/dcat#Dataset"
+}]}}`
+
+func TestDecodeDCATAP(t *testing.T) {
+	for _, lazy := range []bool{false, true} {
+		c := newTestClient(http.StatusOK, fullDataset)
+		c.lazy = lazy
+
+		ds, err := c.Datasets(context.Background(), Params{})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if len(ds) != 1 {
+			t.Fatalf("wrong number of datasets, expected: 1, got: %d", len(ds))
+		}
+
+		d := ds[0]
+		if d.Identifier != "http://datos.gob.es/catalogo/a" || len(d.Identifiers) != 2 || d.Identifiers[1] != "urn:a" {
+			t.Errorf("wrong identifiers: %q, %v", d.Identifier, d.Identifiers)
+		}
+
+		expectedContact := ContactPoint{
+			About: "http://example.com/contact",
+			Name:  "Servicio de datos",
+			Email: "datos@example.com",
+			URL:   "http://example.com",
+		}
+		if len(d.ContactPoint) != 1 || d.ContactPoint[0] != expectedContact {
+			t.Errorf("wrong contact points: %+v", d.ContactPoint)
+		}
+
+		if len(d.LandingPage) != 1 || d.LandingPage[0] != "http://example.com/aire" {
+			t.Errorf("wrong landing page: %v", d.LandingPage)
+		}
+
+		if f := d.AccrualPeriodicity; f.About != "http://example.com/freq" || f.Duration() != "P1DT12H" {
+			t.Errorf("wrong frequency: %+v", f)
+		}
+
+		start := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+		end := time.Date(2019, time.December, 31, 0, 0, 0, 0, time.UTC)
+		if !d.Temporal.Start.Equal(start) || !d.Temporal.End.Equal(end) {
+			t.Errorf("wrong temporal coverage: %+v", d.Temporal)
+		}
+
+		if len(d.ConformsTo) != 1 || d.AccessRights != "http://example.com/public" {
+			t.Errorf("wrong conformsTo or accessRights: %v, %q", d.ConformsTo, d.AccessRights)
+		}
+
+		if len(d.Extra) != 2 || string(d.Extra["extension"]) != `{"quality":5}` || d.Extra["type"] == nil {
+			t.Errorf("wrong extra keys: %v", d.Extra)
+		}
+
+		if lazy && d.Distribution != nil {
+			t.Errorf("expected no distributions when lazy, got: %v", d.Distribution)
+		} else if !lazy && len(d.Distribution) != 1 {
+			t.Errorf("wrong distributions: %v", d.Distribution)
+		}
+	}
+}
+
+func TestContactPoints(t *testing.T) {
+	var cps ContactPoints
+	err := json.Unmarshal([]byte(`["http://example.com/a", null, {"name":"B","email":["b@example.com"]}]`), &cps)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := ContactPoints{{About: "http://example.com/a"}, {Name: "B", Email: "b@example.com"}}
+	if len(cps) != len(expected) || cps[0] != expected[0] || cps[1] != expected[1] {
+		t.Errorf("wrong contact points, expected: %+v, got: %+v", expected, cps)
+	}
+
+	if err := json.Unmarshal([]byte(`12`), &cps); err == nil {
+		t.Errorf("expected error decoding number")
+	}
+}
+
+func TestFrequency(t *testing.T) {
+	cases := []struct {
+		json     string
+		about    string
+		duration string
+	}{
+		{`"http://purl.org/cld/freq/daily"`, "http://purl.org/cld/freq/daily", ""},
+		{`{"_about":"x","months":3}`, "x", "P3M"},
+		{`{"rdf:value":{"years":1}}`, "", "P1Y"},
+		{`{"minutes":"30"}`, "", "PT30M"},
+		{`null`, "", ""},
+	}
+
+	for _, c := range cases {
+		var f Frequency
+		if err := json.Unmarshal([]byte(c.json), &f); err != nil {
+			t.Errorf("unexpected error decoding %s: %s", c.json, err)
+			continue
+		}
+
+		if f.About != c.about || f.Duration() != c.duration {
+			t.Errorf("wrong frequency for %s, expected: %s %s, got: %s %s", c.json, c.about, c.duration, f.About, f.Duration())
+		}
+	}
+
+	var f Frequency
+	if err := json.Unmarshal([]byte(`{"days":"often"}`), &f); err == nil {
+		t.Errorf("expected error decoding invalid duration")
+	}
+}
+
+func TestPeriodOfTime(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	endOf := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 23, 59, 59, int(time.Second-1), time.UTC)
+	}
+
+	cases := []struct {
+		json       string
+		start, end time.Time
+	}{
+		{`"2010-2015"`, date(2010, time.January, 1), endOf(2015, time.December, 31)},
+		{`"2019"`, date(2019, time.January, 1), endOf(2019, time.December, 31)},
+		{`"2010-03-01/2011-02-28"`, date(2010, time.March, 1), endOf(2011, time.February, 28)},
+		{`"2010-03-01/2011-02-28T12:00:00Z"`, date(2010, time.March, 1), date(2011, time.February, 28).Add(12 * time.Hour)},
+		{`"desde hace años"`, time.Time{}, time.Time{}},
+		{`"2010-03-01/ayer"`, time.Time{}, time.Time{}},
+	}
+
+	for _, c := range cases {
+		var p PeriodOfTime
+		if err := json.Unmarshal([]byte(c.json), &p); err != nil {
+			t.Errorf("unexpected error decoding %s: %s", c.json, err)
+			continue
+		}
+
+		if `"`+p.About+`"` != c.json || !p.Start.Equal(c.start) || !p.End.Equal(c.end) {
+			t.Errorf("wrong period for %s: %+v", c.json, p)
+		}
+	}
+
+	p := PeriodOfTime{Start: Datetime{Time: date(2010, time.January, 1)}}
+	if !p.Contains(date(2020, time.May, 1)) || p.Contains(date(2009, time.May, 1)) {
+		t.Errorf("wrong result of Contains for a period without end")
+	}
+
+	p.End = Datetime{Time: endOf(2015, time.December, 31)}
+	if p.Contains(date(2016, time.January, 1)) || !p.Contains(p.End.Time) || !p.Contains(date(2015, time.December, 31).Add(18*time.Hour)) {
+		t.Errorf("wrong result of Contains for a closed period")
+	}
+
+	if err := json.Unmarshal([]byte(`"2015"`), &p); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !p.Contains(time.Date(2015, time.December, 31, 23, 59, 59, 0, time.UTC)) || p.Contains(date(2016, time.January, 1)) {
+		t.Errorf("expected period of a year to contain the whole last day only")
+	}
+
+	if err := json.Unmarshal([]byte(`{"startDate":"2015-01-01","endDate":"2015-06-30"}`), &p); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !p.End.Equal(endOf(2015, time.June, 30)) || p.End.Raw() != "2015-06-30" {
+		t.Errorf("wrong end of period with a date without time: %s", p.End)
+	}
+
+	if (PeriodOfTime{About: "x"}).Contains(date(2020, time.May, 1)) {
+		t.Errorf("expected period without bounds to contain no time")
+	}
+
+	if err := json.Unmarshal([]byte(`{"startDate":"ayer"}`), &p); err == nil {
+		t.Errorf("expected error decoding invalid date")
+	}
+}
+
+func TestReadDCATContactPoint(t *testing.T) {
+	const doc = `[{
+		"@id": "https://example.com/dataset/air",
+		"@type": "http://www.w3.org/ns/dcat#Dataset",
+		"http://www.w3.org/ns/dcat#contactPoint": {
+			"@id": "_:contact",
+			"http://www.w3.org/2006/vcard/ns#fn": "Datos",
+			"http://www.w3.org/2006/vcard/ns#hasEmail": {"@id": "mailto:datos@example.com"}
+		},
+		"http://www.w3.org/ns/dcat#landingPage": {"@id": "https://example.com/air"},
+		"http://purl.org/dc/terms/temporal": {
+			"http://www.w3.org/ns/dcat#startDate": "2019-01-01",
+			"http://www.w3.org/ns/dcat#endDate": "2019-12-31"
+		}
+	}]`
+
+	ds, err := ReadDCAT(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(ds) != 1 {
+		t.Fatalf("wrong number of datasets, expected: 1, got: %d", len(ds))
+	}
+
+	d := ds[0]
+	if len(d.ContactPoint) != 1 || d.ContactPoint[0] != (ContactPoint{Name: "Datos", Email: "datos@example.com"}) {
+		t.Errorf("wrong contact points: %+v", d.ContactPoint)
+	}
+
+	if len(d.LandingPage) != 1 || d.LandingPage[0] != "https://example.com/air" {
+		t.Errorf("wrong landing page: %v", d.LandingPage)
+	}
+
+	start := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if !d.Temporal.Start.Equal(start) || !d.Temporal.End.Equal(start.AddDate(1, 0, 0).Add(-time.Nanosecond)) {
+		t.Errorf("wrong temporal coverage: %+v", d.Temporal)
+	}
+}
@@ -0,0 +1,169 @@
+package datos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// DecodeWarning describes an item of a response that could not be decoded
+// and was skipped.
+type DecodeWarning struct {
+	// Path of the API endpoint that returned the item.
+	Path string
+	// Index of the item in the page of results.
+	Index int
+	// About contains the link to the item, if it could be found.
+	About string
+	// Publisher contains the link to the publisher of the item, if it
+	// could be found.
+	Publisher string
+	// Err is the error found decoding the item.
+	Err error
+}
+
+func (w DecodeWarning) String() string {
+	about := w.About
+	if about == "" {
+		about = fmt.Sprintf("item %d", w.Index)
+	}
+	return fmt.Sprintf("unable to decode %s from %q: %s", about, w.Path, w.Err)
+}
+
+// WithStrictDecoding makes the client fail when any of the items of a
+// response can not be decoded, instead of skipping it.
+func WithStrictDecoding() Option {
+	return func(c *Client) {
+		c.strict = true
+	}
+}
+
+// WithDecodeWarnings sets a function that will be called with every item
+// skipped because it could not be decoded.
+func WithDecodeWarnings(fn func(DecodeWarning)) Option {
+	return func(c *Client) {
+		c.onWarning = fn
+	}
+}
+
+// Warnings collects decode warnings. It is safe for concurrent use.
+type Warnings struct {
+	mut  sync.Mutex
+	list []DecodeWarning
+}
+
+// Add adds a warning to the collection. It can be used as the function
+// passed to WithDecodeWarnings.
+func (w *Warnings) Add(dw DecodeWarning) {
+	w.mut.Lock()
+	w.list = append(w.list, dw)
+	w.mut.Unlock()
+}
+
+// List returns the warnings collected so far.
+func (w *Warnings) List() []DecodeWarning {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+	return append([]DecodeWarning(nil), w.list...)
+}
+
+// Len returns the number of warnings collected so far.
+func (w *Warnings) Len() int {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+	return len(w.list)
+}
+
+type warningsKey struct{}
+
+// CollectWarnings returns a context that makes the client add to w the
+// warnings of all the requests made with it, so the warnings of a crawl can
+// be collected regardless of the client used.
+func CollectWarnings(ctx context.Context, w *Warnings) context.Context {
+	return context.WithValue(ctx, warningsKey{}, w)
+}
+
+type itemsResp struct {
+	Result struct {
+		pageInfo
+		Items []json.RawMessage `json:"items"`
+	} `json:"result"`
+}
+
+// getItems gets the items of the given path and decodes them into out,
+// which must be a pointer to a slice. Items that can not be decoded are
+// skipped, unless the client uses strict decoding.
+func (c *Client) getItems(ctx context.Context, path string, params Params, out interface{}) error {
+	var resp itemsResp
+	if err := c.get(ctx, path, params, &resp); err != nil {
+		return err
+	}
+
+	setPage(ctx, resp.Result.page(params, len(resp.Result.Items)))
+
+	slice := reflect.ValueOf(out).Elem()
+	lazy := c.skipDistributions(ctx)
+	for i, item := range resp.Result.Items {
+		v := reflect.New(slice.Type().Elem())
+		dst := v.Interface()
+		if d, ok := dst.(*Dataset); ok && lazy {
+			dst = &lazyDataset{d}
+		}
+
+		if err := decodeItem(item, dst); err != nil {
+			if c.strict {
+				return newError(ErrDecoding, err, "datos: unable to decode item %d from %q: %s", i, path, err)
+			}
+
+			about, publisher := itemInfo(item)
+			c.warn(ctx, DecodeWarning{path, i, about, publisher, err})
+			continue
+		}
+
+		slice.Set(reflect.Append(slice, v.Elem()))
+	}
+
+	return nil
+}
+
+// decodeItem decodes the item into v, recovering from any panic that may
+// happen in the process, so a malformed item can't crash the program.
+func decodeItem(item json.RawMessage, v interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic decoding item: %v", r)
+		}
+	}()
+
+	return json.Unmarshal(item, v)
+}
+
+// itemInfo returns the link and publisher of an item that could not be
+// decoded, if they are available.
+func itemInfo(item json.RawMessage) (about, publisher string) {
+	var v map[string]interface{}
+	_ = json.Unmarshal(item, &v)
+	about, _ = v["_about"].(string)
+	publisher, _ = v["publisher"].(string)
+	return about, publisher
+}
+
+func (c *Client) warn(ctx context.Context, w DecodeWarning) {
+	c.log(LevelWarn, "unable to decode item", Fields{
+		"path":      w.Path,
+		"index":     w.Index,
+		"about":     w.About,
+		"publisher": w.Publisher,
+		"error":     w.Err,
+	})
+
+	if c.onWarning != nil {
+		c.onWarning(w)
+	}
+
+	if ws, ok := ctx.Value(warningsKey{}).(*Warnings); ok {
+		ws.Add(w)
+	}
+}
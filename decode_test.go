@@ -0,0 +1,156 @@
+package datos
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const partiallyInvalidDatasets = `{"result":{"items":[
+	{"_about":"http://datos.gob.es/catalogo/a","modified":"not a date"},
+	{"_about":"http://datos.gob.es/catalogo/b","distribution":"invalid"},
+	{"_about":"http://datos.gob.es/catalogo/c","identifier":"c"}
+]}}`
+
+func TestDecodeSkipsInvalidItems(t *testing.T) {
+	var warnings []DecodeWarning
+	c := newTestClient(http.StatusOK, partiallyInvalidDatasets)
+	WithDecodeWarnings(func(w DecodeWarning) {
+		warnings = append(warnings, w)
+	})(c)
+
+	ds, err := c.Datasets(context.Background(), Params{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(ds) != 1 || ds[0].Identifier != "c" {
+		t.Errorf("expected only dataset c to be decoded, got: %v", ds)
+	}
+
+	if len(warnings) != 2 {
+		t.Fatalf("wrong number of warnings, expected: 2, got: %d", len(warnings))
+	}
+
+	for i, about := range []string{"http://datos.gob.es/catalogo/a", "http://datos.gob.es/catalogo/b"} {
+		if warnings[i].About != about || warnings[i].Index != i {
+			t.Errorf("wrong warning, expected item %d (%s), got: %s", i, about, warnings[i])
+		}
+	}
+}
+
+func TestDecodeStrict(t *testing.T) {
+	c := newTestClient(http.StatusOK, partiallyInvalidDatasets)
+	WithStrictDecoding()(c)
+
+	_, err := c.Datasets(context.Background(), Params{})
+	if !errors.Is(err, ErrDecoding) {
+		t.Errorf("expected decoding error, got: %v", err)
+	}
+}
+
+func TestCollectWarnings(t *testing.T) {
+	var warnings Warnings
+	ctx := CollectWarnings(context.Background(), &warnings)
+
+	c := newTestClient(http.StatusOK, partiallyInvalidDatasets)
+	for i := 0; i < 2; i++ {
+		if _, err := c.Datasets(ctx, Params{}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	if warnings.Len() != 4 {
+		t.Errorf("wrong number of warnings, expected: 4, got: %d", warnings.Len())
+	}
+
+	if _, err := c.Datasets(context.Background(), Params{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(warnings.List()) != 4 {
+		t.Errorf("expected warnings outside the context not to be collected")
+	}
+}
+
+func TestDecodeMultivaluedFields(t *testing.T) {
+	const response = `{"result":{"items":[{
+		"_about":"http://datos.gob.es/catalogo/a",
+		"theme":[
+			"http://datos.gob.es/kos/sector-publico/sector/salud",
+			{"_about":"http://datos.gob.es/kos/sector-publico/sector/sociedad-bienestar"}
+		],
+		"spatial":{"_about":"http://datos.gob.es/recurso/sector-publico/territorio/Autonomia/Aragon"},
+		"keyword":null,
+		"title":[{"_value":"Centros de salud","_lang":"es"},"Health centres"]
+	}]}}`
+
+	ds, err := newTestClient(http.StatusOK, response).Datasets(context.Background(), Params{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(ds) != 1 {
+		t.Fatalf("wrong number of datasets, expected: 1, got: %d", len(ds))
+	}
+
+	d := ds[0]
+	if len(d.Theme) != 2 || d.Theme[1] != "http://datos.gob.es/kos/sector-publico/sector/sociedad-bienestar" {
+		t.Errorf("wrong themes: %v", d.Theme)
+	}
+
+	if len(d.Spatial) != 1 || d.Spatial[0] != "http://datos.gob.es/recurso/sector-publico/territorio/Autonomia/Aragon" {
+		t.Errorf("wrong spatials: %v", d.Spatial)
+	}
+
+	if len(d.Keywords) != 0 {
+		t.Errorf("expected no keywords, got: %v", d.Keywords)
+	}
+
+	if len(d.Title) != 2 || d.Title[0].Text != "Centros de salud" || d.Title[1].Text != "Health centres" {
+		t.Errorf("wrong titles: %v", d.Title)
+	}
+
+	var s Strings
+	if err := s.UnmarshalJSON([]byte(`[{"lang":"es"}]`)); err == nil {
+		t.Errorf("expected error decoding object without value")
+	}
+}
+
+func TestDecodeDistributions(t *testing.T) {
+	cases := []struct {
+		json string
+		urls []string
+	}{
+		{`{"accessURL": "http://example.com/a.csv"}`, []string{"http://example.com/a.csv"}},
+		{`[{"accessURL": "http://example.com/a.csv"}, null, {"accessURL": "http://example.com/b.csv"}]`, []string{"http://example.com/a.csv", "http://example.com/b.csv"}},
+		{` []`, nil},
+		{`null`, nil},
+	}
+
+	for _, c := range cases {
+		var ds Distributions
+		if err := ds.UnmarshalJSON([]byte(c.json)); err != nil {
+			t.Errorf("unexpected error decoding %s: %s", c.json, err)
+			continue
+		}
+
+		var urls []string
+		for _, d := range ds {
+			urls = append(urls, d.AccessURL)
+		}
+
+		if strings.Join(urls, " ") != strings.Join(c.urls, " ") {
+			t.Errorf("wrong distributions decoding %s, expected: %v, got: %v", c.json, c.urls, urls)
+		}
+	}
+
+	for _, invalid := range []string{`"invalid"`, `12`, ``, `nul`} {
+		var ds Distributions
+		if err := ds.UnmarshalJSON([]byte(invalid)); err == nil {
+			t.Errorf("expected error decoding %q", invalid)
+		}
+	}
+}
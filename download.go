@@ -0,0 +1,355 @@
+package datos
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// DownloadOptions controls how distributions are downloaded.
+type DownloadOptions struct {
+	// Formats are the preferred formats of the distribution downloaded by
+	// DownloadDataset, in order of preference. They can be media types,
+	// such as "text/csv", or their subtypes, such as "csv". If none is
+	// given, the first distribution is downloaded.
+	Formats []string
+	// Progress is called every time a chunk of the distribution is
+	// downloaded with the number of bytes written so far and the total
+	// size of the distribution, which is -1 if it's unknown.
+	Progress func(written, total int64)
+	// ConnectTimeout limits the time until the server starts sending the
+	// file. By default, there is no limit.
+	ConnectTimeout time.Duration
+	// IdleTimeout limits the time without receiving any data from the
+	// server. By default, there is no limit.
+	IdleTimeout time.Duration
+	// Timeout limits the total time of the download once the server starts
+	// sending the file. By default, there is no limit.
+	Timeout time.Duration
+	// TimeoutPerMB is added to Timeout for every megabyte of the file, if
+	// its size is known, so large files get more time.
+	TimeoutPerMB time.Duration
+	// Sync makes DownloadFile flush the file to disk before closing it, so
+	// no data is lost on network filesystems that fail silently.
+	Sync bool
+}
+
+// DownloadInfo describes a downloaded distribution.
+type DownloadInfo struct {
+	// Distribution downloaded.
+	Distribution Distribution
+	// URL the distribution was downloaded from, after following any
+	// redirect.
+	URL string
+	// ContentType of the downloaded file.
+	ContentType string
+	// Bytes is the number of bytes downloaded.
+	Bytes int64
+	// Offset is the number of bytes of a partial download that were
+	// already downloaded and were not requested again.
+	Offset int64
+}
+
+// DownloadDataset downloads a distribution of the dataset into w, choosing
+// the first one in the preferred formats. ErrNotFound is returned if the
+// dataset has no distribution in any of them.
+func (c *Client) DownloadDataset(ctx context.Context, ds Dataset, w io.Writer, opts DownloadOptions) (DownloadInfo, error) {
+	dist, ok := selectDistribution(ds.Distribution, opts.Formats)
+	if !ok {
+		return DownloadInfo{}, newError(
+			ErrNotFound, nil,
+			"datos: no distribution in formats %s found for dataset %q",
+			strings.Join(opts.Formats, ", "), ds.About,
+		)
+	}
+
+	return c.DownloadDistribution(ctx, dist, w, opts)
+}
+
+// DownloadDistribution downloads the distribution into w. Downloads are
+// not limited by the timeout of the client, only by the context and the
+// timeouts of the options.
+func (c *Client) DownloadDistribution(ctx context.Context, dist Distribution, w io.Writer, opts DownloadOptions) (DownloadInfo, error) {
+	ctx, timer := newDownloadTimer(ctx, opts)
+	defer timer.stop()
+
+	resp, info, err := c.fetch(ctx, dist, 0, "", timer)
+	if err != nil {
+		return info, err
+	}
+	defer resp.Body.Close()
+
+	err = copyBody(&info, w, resp, opts, timer)
+	return info, timer.check(err, dist.AccessURL)
+}
+
+// DownloadFile downloads the distribution into the file at path. If the
+// file already exists, it's considered a partial download and only the rest
+// of the distribution is requested, as long as the file has not changed in
+// the server since the partial download started. The ETag or Last-Modified
+// header of the server is kept next to the file until the download is
+// complete to check that. If the server does not support range requests,
+// or the file can't be checked, it's downloaded again from the beginning.
+func (c *Client) DownloadFile(ctx context.Context, dist Distribution, path string, opts DownloadOptions) (DownloadInfo, error) {
+	var offset int64
+	var validator string
+	if fi, err := os.Stat(path); err == nil && !fi.IsDir() {
+		validator = readValidator(path)
+		if validator != "" {
+			offset = fi.Size()
+		}
+	}
+
+	ctx, timer := newDownloadTimer(ctx, opts)
+	defer timer.stop()
+
+	resp, info, err := c.fetch(ctx, dist, offset, validator, timer)
+	if offset > 0 && err != nil && isStatus(err, http.StatusRequestedRangeNotSatisfiable) {
+		offset = 0
+		resp, info, err = c.fetch(ctx, dist, 0, "", timer)
+	}
+
+	// A partial response that does not start where the file ends can't be
+	// appended to it.
+	if offset > 0 && err == nil && resp.StatusCode == http.StatusPartialContent && rangeStart(resp) != offset {
+		resp.Body.Close()
+		offset = 0
+		resp, info, err = c.fetch(ctx, dist, 0, "", timer)
+	}
+
+	if err != nil {
+		return info, err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 && resp.StatusCode == http.StatusPartialContent {
+		flags = os.O_WRONLY | os.O_APPEND
+		info.Offset = offset
+	} else if err := writeValidator(path, resp); err != nil {
+		return info, err
+	}
+
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return info, err
+	}
+
+	w := bufio.NewWriterSize(f, 64<<10)
+	err = copyBody(&info, w, resp, opts, timer)
+	if err == nil {
+		err = w.Flush()
+	}
+
+	if err == nil && opts.Sync {
+		err = f.Sync()
+	}
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = removeValidator(path)
+	}
+
+	return info, timer.check(err, dist.AccessURL)
+}
+
+// DistributionSize returns the size in bytes of the distribution as
+// reported by its server to a HEAD request, falling back to the size in the
+// catalog. If neither is known, -1 is returned.
+func (c *Client) DistributionSize(ctx context.Context, dist Distribution) (int64, error) {
+	req, err := http.NewRequest("HEAD", dist.AccessURL, nil)
+	if err != nil {
+		return -1, newError(ErrNotFound, err, "datos: invalid distribution URL %q: %s", dist.AccessURL, err)
+	}
+
+	resp, err := c.c.Do(req.WithContext(ctx))
+	if err != nil {
+		return -1, newError(ErrUpstreamUnavailable, err, "datos: unable to get size of %q: %s", dist.AccessURL, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return -1, newAPIError(resp, nil)
+	}
+
+	if resp.ContentLength >= 0 {
+		return resp.ContentLength, nil
+	}
+
+	if dist.ByteSize > 0 {
+		return int64(dist.ByteSize), nil
+	}
+
+	return -1, nil
+}
+
+// fetch requests the distribution starting at the given offset. If a
+// validator is given, the server is asked to send the whole distribution
+// instead if it no longer matches.
+func (c *Client) fetch(ctx context.Context, dist Distribution, offset int64, validator string, timer *downloadTimer) (*http.Response, DownloadInfo, error) {
+	info := DownloadInfo{Distribution: dist, URL: dist.AccessURL}
+	req, err := http.NewRequest("GET", dist.AccessURL, nil)
+	if err != nil {
+		return nil, info, newError(ErrNotFound, err, "datos: invalid distribution URL %q: %s", dist.AccessURL, err)
+	}
+
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		if validator != "" {
+			req.Header.Set("If-Range", validator)
+		}
+	}
+
+	client := &http.Client{
+		Transport:     c.c.Transport,
+		CheckRedirect: c.c.CheckRedirect,
+		Jar:           c.c.Jar,
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		err = newError(ErrUpstreamUnavailable, err, "datos: unable to download %q: %s", dist.AccessURL, err)
+		return nil, info, timer.check(err, dist.AccessURL)
+	}
+
+	if resp.StatusCode >= 400 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		resp.Body.Close()
+		return nil, info, newAPIError(resp, body)
+	}
+
+	if resp.Request != nil {
+		info.URL = resp.Request.URL.String()
+	}
+	info.ContentType = resp.Header.Get("Content-Type")
+	c.log(LevelDebug, "download", Fields{
+		"url":    info.URL,
+		"status": resp.StatusCode,
+		"offset": offset,
+	})
+
+	size := resp.ContentLength
+	if size < 0 && dist.ByteSize > 0 {
+		size = int64(dist.ByteSize)
+	}
+	timer.connected(size)
+
+	return resp, info, nil
+}
+
+func copyBody(info *DownloadInfo, w io.Writer, resp *http.Response, opts DownloadOptions, timer *downloadTimer) error {
+	if opts.Progress != nil {
+		total := resp.ContentLength
+		if total >= 0 {
+			total += info.Offset
+		}
+		w = &progressWriter{w: w, written: info.Offset, total: total, fn: opts.Progress}
+	}
+
+	var err error
+	info.Bytes, err = io.Copy(w, timer.reader(resp.Body))
+	if err != nil {
+		return newError(ErrUpstreamUnavailable, err, "datos: error downloading %q: %s", info.Distribution.AccessURL, err)
+	}
+
+	return nil
+}
+
+// rangeStart returns the first byte of the range of a partial response, or
+// -1 if it can't be found.
+func rangeStart(resp *http.Response) int64 {
+	var start int64
+	if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-", &start); err != nil {
+		return -1
+	}
+	return start
+}
+
+// validatorPath returns the path of the file keeping the validator of the
+// partial download at path.
+func validatorPath(path string) string {
+	return path + ".validator"
+}
+
+// readValidator returns the validator of the partial download at path, or
+// an empty string if there is none.
+func readValidator(path string) string {
+	data, err := ioutil.ReadFile(validatorPath(path))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// writeValidator keeps the validator of the response so the download can
+// be resumed later. Weak ETags can't be used with If-Range, so the
+// Last-Modified header is used instead in that case.
+func writeValidator(path string, resp *http.Response) error {
+	v := resp.Header.Get("ETag")
+	if v == "" || strings.HasPrefix(v, "W/") {
+		v = resp.Header.Get("Last-Modified")
+	}
+
+	if v == "" {
+		return removeValidator(path)
+	}
+	return ioutil.WriteFile(validatorPath(path), []byte(v), 0644)
+}
+
+func removeValidator(path string) error {
+	if err := os.Remove(validatorPath(path)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func isStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// selectDistribution returns the first distribution with a link in the
+// first of the formats that has any.
+func selectDistribution(dists []Distribution, formats []string) (Distribution, bool) {
+	if len(formats) == 0 {
+		for _, d := range dists {
+			if d.AccessURL != "" {
+				return d, true
+			}
+		}
+	}
+
+	for _, f := range formats {
+		for _, d := range dists {
+			if d.AccessURL != "" && hasFormat([]Distribution{d}, f) {
+				return d, true
+			}
+		}
+	}
+
+	return Distribution{}, false
+}
+
+type progressWriter struct {
+	w       io.Writer
+	written int64
+	total   int64
+	fn      func(written, total int64)
+}
+
+func (w *progressWriter) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	w.written += int64(n)
+	w.fn(w.written, w.total)
+	return n, err
+}
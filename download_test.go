@@ -0,0 +1,303 @@
+package datos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDownloadDataset(t *testing.T) {
+	var requested string
+	c := newTestClientFunc(func(r *http.Request) (int, string) {
+		requested = r.URL.String()
+		return http.StatusOK, "a,b\n1,2\n"
+	})
+
+	ds := Dataset{About: "foo", Distribution: Distributions{
+		{AccessURL: "http://example.com/foo.json", Format: format("application/json")},
+		{AccessURL: "http://example.com/foo.csv", Format: format("text/csv")},
+	}}
+
+	var buf bytes.Buffer
+	var progress int64
+	info, err := c.DownloadDataset(context.Background(), ds, &buf, DownloadOptions{
+		Formats:  []string{"xml", "csv"},
+		Progress: func(written, total int64) { progress = written },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if requested != "http://example.com/foo.csv" {
+		t.Errorf("wrong distribution downloaded: %s", requested)
+	}
+
+	if info.Bytes != 8 || progress != 8 || buf.String() != "a,b\n1,2\n" {
+		t.Errorf("wrong download, bytes: %d, progress: %d, content: %q", info.Bytes, progress, buf.String())
+	}
+
+	_, err = c.DownloadDataset(context.Background(), ds, &buf, DownloadOptions{Formats: []string{"xml"}})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+}
+
+func TestDownloadDistributionError(t *testing.T) {
+	c := newTestClient(http.StatusNotFound, "not found")
+	_, err := c.DownloadDistribution(
+		context.Background(),
+		Distribution{AccessURL: "http://example.com/foo.csv"},
+		new(bytes.Buffer),
+		DownloadOptions{},
+	)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected API error with status 404, got: %v", err)
+	}
+}
+
+func format(value string) (f struct {
+	About string `json:"_about"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}) {
+	f.Value = value
+	return f
+}
+
+func TestDownloadFileResume(t *testing.T) {
+	const content = "a,b\n1,2\n3,4\n"
+	c := newTestClient(http.StatusOK, content)
+	c.c.Transport = rangeTransport{c.c.Transport, content, `"v1"`}
+
+	dir, err := ioutil.TempDir("", "datos")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "data.csv")
+	writePartial(t, path, content[:6], `"v1"`)
+
+	dist := Distribution{AccessURL: "http://example.com/data.csv"}
+	info, err := c.DownloadFile(context.Background(), dist, path, DownloadOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, _ := ioutil.ReadFile(path)
+	if string(data) != content || info.Offset != 6 || info.Bytes != int64(len(content)-6) {
+		t.Errorf("wrong resumed download, offset: %d, bytes: %d, content: %q", info.Offset, info.Bytes, data)
+	}
+
+	if _, err := os.Stat(validatorPath(path)); !os.IsNotExist(err) {
+		t.Errorf("expected validator to be removed after the download, got: %v", err)
+	}
+
+	// A server without range support sends the whole file again.
+	c = newTestClient(http.StatusOK, content)
+	writePartial(t, path, "xxxxxx", `"v1"`)
+
+	if _, err := c.DownloadFile(context.Background(), dist, path, DownloadOptions{Sync: true}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, _ = ioutil.ReadFile(path)
+	if string(data) != content {
+		t.Errorf("expected file to be downloaded again, got: %q", data)
+	}
+}
+
+func TestDownloadFileChanged(t *testing.T) {
+	const content = "a,b\n5,6\n7,8\n"
+	var ranges []string
+	c := newTestClientFunc(func(r *http.Request) (int, string) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		return http.StatusOK, content
+	})
+	c.c.Transport = rangeTransport{c.c.Transport, content, `"v2"`}
+
+	dir, err := ioutil.TempDir("", "datos")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "data.csv")
+	dist := Distribution{AccessURL: "http://example.com/data.csv"}
+
+	cases := []struct {
+		name      string
+		validator string
+		ranges    []string
+	}{
+		{"changed validator", `"v1"`, []string{"bytes=6-"}},
+		{"no validator", "", []string{""}},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ranges = nil
+			writePartial(t, path, "a,b\n1,", tt.validator)
+
+			info, err := c.DownloadFile(context.Background(), dist, path, DownloadOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			data, _ := ioutil.ReadFile(path)
+			if string(data) != content || info.Offset != 0 {
+				t.Errorf("expected file to be downloaded again, offset: %d, content: %q", info.Offset, data)
+			}
+
+			if !reflect.DeepEqual(ranges, tt.ranges) {
+				t.Errorf("expected ranges %q, got: %q", tt.ranges, ranges)
+			}
+		})
+	}
+}
+
+func TestDownloadFileRangeMismatch(t *testing.T) {
+	const content = "a,b\n1,2\n3,4\n"
+	var ranges []string
+	c := newTestClientFunc(func(r *http.Request) (int, string) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		return http.StatusOK, content
+	})
+	transport := c.c.Transport
+	c.c.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		resp, err := transport.RoundTrip(r)
+		if err == nil && r.Header.Get("Range") != "" {
+			// The server sends a range other than the one requested.
+			resp.StatusCode = http.StatusPartialContent
+			resp.Header.Set("Content-Range", fmt.Sprintf("bytes 4-%d/%d", len(content)-1, len(content)))
+			resp.Body = ioutil.NopCloser(strings.NewReader(content[4:]))
+		}
+		return resp, err
+	})
+
+	dir, err := ioutil.TempDir("", "datos")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "data.csv")
+	writePartial(t, path, content[:6], `"v1"`)
+
+	dist := Distribution{AccessURL: "http://example.com/data.csv"}
+	info, err := c.DownloadFile(context.Background(), dist, path, DownloadOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, _ := ioutil.ReadFile(path)
+	if string(data) != content || info.Offset != 0 {
+		t.Errorf("expected file to be downloaded again, offset: %d, content: %q", info.Offset, data)
+	}
+
+	expected := []string{"bytes=6-", ""}
+	if !reflect.DeepEqual(ranges, expected) {
+		t.Errorf("expected ranges %q, got: %q", expected, ranges)
+	}
+}
+
+// writePartial writes a partial download at path along with its validator,
+// if any.
+func writePartial(t *testing.T, path, content, validator string) {
+	t.Helper()
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if validator == "" {
+		os.Remove(validatorPath(path))
+		return
+	}
+
+	if err := ioutil.WriteFile(validatorPath(path), []byte(validator), 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+// rangeTransport serves content with the given ETag honoring the Range
+// header of requests, unless their If-Range header does not match it.
+type rangeTransport struct {
+	http.RoundTripper
+	content string
+	etag    string
+}
+
+func (t rangeTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := t.RoundTripper.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+	resp.Header.Set("ETag", t.etag)
+
+	if ifRange := r.Header.Get("If-Range"); ifRange != "" && ifRange != t.etag {
+		return resp, nil
+	}
+
+	var start int
+	if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start); err == nil {
+		resp.StatusCode = http.StatusPartialContent
+		resp.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(t.content)-1, len(t.content)))
+		resp.Body = ioutil.NopCloser(strings.NewReader(t.content[start:]))
+	}
+	return resp, nil
+}
+
+func TestDistributionSize(t *testing.T) {
+	var method string
+	c := &Client{c: &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			method = r.Method
+			length := int64(-1)
+			if strings.HasSuffix(r.URL.Path, ".csv") {
+				length = 1024
+			}
+
+			return &http.Response{
+				StatusCode:    http.StatusOK,
+				Header:        make(http.Header),
+				Body:          ioutil.NopCloser(strings.NewReader("")),
+				ContentLength: length,
+				Request:       r,
+			}, nil
+		}),
+	}}
+
+	testCases := []struct {
+		dist     Distribution
+		expected int64
+	}{
+		{Distribution{AccessURL: "http://example.com/foo.csv", ByteSize: 10}, 1024},
+		{Distribution{AccessURL: "http://example.com/foo", ByteSize: 10}, 10},
+		{Distribution{AccessURL: "http://example.com/foo"}, -1},
+	}
+
+	for _, tt := range testCases {
+		size, err := c.DistributionSize(context.Background(), tt.dist)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if size != tt.expected {
+			t.Errorf("wrong size of %s, expected: %d, got: %d", tt.dist.AccessURL, tt.expected, size)
+		}
+	}
+
+	if method != "HEAD" {
+		t.Errorf("wrong method: %s", method)
+	}
+}
@@ -0,0 +1,169 @@
+package datos
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// DownloadJob is a distribution to download with a Downloader.
+type DownloadJob struct {
+	// ID identifies the job for the caller.
+	ID string
+	// Distribution to download.
+	Distribution Distribution
+	// Path of the file the distribution is downloaded into. If the file
+	// exists, the download is resumed as in Client.DownloadFile, so failed
+	// attempts are resumed as well.
+	Path string
+	// Create returns the writer the distribution is downloaded into if
+	// there is no Path. It's called once per attempt, so a retried download
+	// does not append to the data of a failed one. The writer is closed
+	// after every attempt.
+	Create func() (io.WriteCloser, error)
+}
+
+// DownloadResult is the outcome of a DownloadJob.
+type DownloadResult struct {
+	// Job downloaded.
+	Job DownloadJob
+	// Info about the download, if it succeeded.
+	Info DownloadInfo
+	// Attempts made to download the distribution.
+	Attempts int
+	// Err is the error of the last attempt, if all of them failed.
+	Err error
+}
+
+// Downloader downloads distributions in parallel, retrying the ones that
+// fail because the server is unavailable or rate limited.
+type Downloader struct {
+	client      *Client
+	concurrency int
+	retries     int
+	backoff     time.Duration
+	opts        DownloadOptions
+}
+
+// NewDownloader creates a downloader that uses the given client to
+// download up to concurrency distributions at the same time, retrying
+// every failed download up to the given number of retries.
+func NewDownloader(client *Client, concurrency, retries int) *Downloader {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	if retries < 0 {
+		retries = 0
+	}
+
+	return &Downloader{client: client, concurrency: concurrency, retries: retries, backoff: time.Second}
+}
+
+// SetOptions sets the options used to download every distribution, such
+// as the timeouts. Formats and Progress are ignored.
+func (d *Downloader) SetOptions(opts DownloadOptions) {
+	opts.Formats = nil
+	opts.Progress = nil
+	d.opts = opts
+}
+
+// Download downloads the given jobs and sends their results to the
+// returned channel, in the order they finish. The channel is closed once
+// all the jobs are done or the context is cancelled.
+func (d *Downloader) Download(ctx context.Context, jobs []DownloadJob) <-chan DownloadResult {
+	results := make(chan DownloadResult)
+	queue := make(chan DownloadJob)
+
+	var wg sync.WaitGroup
+	wg.Add(d.concurrency)
+	for i := 0; i < d.concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				select {
+				case results <- d.download(ctx, job):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(queue)
+		for _, job := range jobs {
+			select {
+			case queue <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+func (d *Downloader) download(ctx context.Context, job DownloadJob) DownloadResult {
+	result := DownloadResult{Job: job}
+	for result.Attempts <= d.retries {
+		if result.Attempts > 0 {
+			t := time.NewTimer(time.Duration(result.Attempts) * d.backoff)
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				t.Stop()
+				result.Err = ctx.Err()
+				return result
+			}
+		}
+
+		result.Attempts++
+		result.Info, result.Err = d.attempt(ctx, job)
+		if result.Err == nil || !retryable(result.Err) {
+			return result
+		}
+
+		if result.Attempts <= d.retries {
+			d.client.log(LevelInfo, "retrying download", Fields{
+				"url":     job.Distribution.AccessURL,
+				"attempt": result.Attempts,
+				"error":   result.Err,
+			})
+			d.client.retried(job.Distribution.AccessURL, result.Attempts, result.Err)
+		}
+	}
+
+	return result
+}
+
+func (d *Downloader) attempt(ctx context.Context, job DownloadJob) (DownloadInfo, error) {
+	if job.Path != "" {
+		return d.client.DownloadFile(ctx, job.Distribution, job.Path, d.opts)
+	}
+
+	w, err := job.Create()
+	if err != nil {
+		return DownloadInfo{}, err
+	}
+
+	info, err := d.client.DownloadDistribution(ctx, job.Distribution, w, d.opts)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+
+	return info, err
+}
+
+// retryable reports whether a request that failed with the given error
+// may succeed if it is made again.
+func retryable(err error) bool {
+	return errors.Is(err, ErrUpstreamUnavailable) || errors.Is(err, ErrRateLimited)
+}
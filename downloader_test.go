@@ -0,0 +1,77 @@
+package datos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestDownloader(t *testing.T) {
+	var mut sync.Mutex
+	attempts := make(map[string]int)
+	c := newTestClientFunc(func(r *http.Request) (int, string) {
+		mut.Lock()
+		defer mut.Unlock()
+		attempts[r.URL.Path]++
+		switch r.URL.Path {
+		case "/flaky":
+			if attempts[r.URL.Path] == 1 {
+				return http.StatusBadGateway, ""
+			}
+		case "/missing":
+			return http.StatusNotFound, ""
+		}
+		return http.StatusOK, "data of " + r.URL.Path
+	})
+
+	d := NewDownloader(c, 2, 2)
+	d.backoff = 0
+
+	var jobs []DownloadJob
+	bufs := make(map[string]*bytes.Buffer)
+	for _, id := range []string{"ok", "flaky", "missing"} {
+		buf := new(bytes.Buffer)
+		bufs[id] = buf
+		jobs = append(jobs, DownloadJob{
+			ID:           id,
+			Distribution: Distribution{AccessURL: "http://example.com/" + id},
+			Create: func() (io.WriteCloser, error) {
+				buf.Reset()
+				return nopWriteCloser{buf}, nil
+			},
+		})
+	}
+
+	results := make(map[string]DownloadResult)
+	for r := range d.Download(context.Background(), jobs) {
+		results[r.Job.ID] = r
+	}
+
+	for _, id := range []string{"ok", "flaky"} {
+		r := results[id]
+		if r.Err != nil {
+			t.Errorf("%s: unexpected error: %s", id, r.Err)
+		}
+
+		if expected := fmt.Sprintf("data of /%s", id); bufs[id].String() != expected {
+			t.Errorf("%s: wrong content, expected: %q, got: %q", id, expected, bufs[id].String())
+		}
+	}
+
+	if results["flaky"].Attempts != 2 {
+		t.Errorf("expected 2 attempts of flaky download, got: %d", results["flaky"].Attempts)
+	}
+
+	if r := results["missing"]; !errors.Is(r.Err, ErrNotFound) || r.Attempts != 1 {
+		t.Errorf("expected a single attempt failing with not found, got: %d attempts, err: %v", r.Attempts, r.Err)
+	}
+}
@@ -0,0 +1,98 @@
+package datos
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+)
+
+var (
+	// ErrNotFound is returned when the requested resource does not exist.
+	ErrNotFound = errors.New("datos: not found")
+	// ErrRateLimited is returned when the API rejects a request because too
+	// many requests have been made.
+	ErrRateLimited = errors.New("datos: rate limited")
+	// ErrDecoding is returned when the response of the API can not be
+	// decoded.
+	ErrDecoding = errors.New("datos: unable to decode response")
+	// ErrUpstreamUnavailable is returned when the API can not be reached or
+	// fails to serve a request.
+	ErrUpstreamUnavailable = errors.New("datos: upstream unavailable")
+)
+
+// kindError is an error of one of the kinds described by the sentinel
+// errors, that also wraps the error that caused it.
+type kindError struct {
+	kind error
+	msg  string
+	err  error
+}
+
+func newError(kind, err error, format string, args ...interface{}) error {
+	return &kindError{kind, fmt.Sprintf(format, args...), err}
+}
+
+func (e *kindError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the error that caused this one.
+func (e *kindError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether the error is of the given kind.
+func (e *kindError) Is(target error) bool {
+	return e.kind == target
+}
+
+// maxErrorBody is the maximum number of bytes of the response body kept
+// in an APIError.
+const maxErrorBody = 512
+
+// APIError is returned when the API responds with an error status code.
+// Depending on the status code, errors.Is reports it as ErrNotFound,
+// ErrRateLimited or ErrUpstreamUnavailable.
+type APIError struct {
+	// StatusCode of the response.
+	StatusCode int
+	// URL requested.
+	URL string
+	// Body contains the beginning of the response body.
+	Body string
+}
+
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	if len(body) > maxErrorBody {
+		body = body[:maxErrorBody]
+		for len(body) > 0 && !utf8.Valid(body) {
+			body = body[:len(body)-1]
+		}
+	}
+
+	var url string
+	if resp.Request != nil {
+		url = resp.Request.URL.String()
+	}
+
+	return &APIError{resp.StatusCode, url, string(body)}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("datos: unexpected status %d requesting %s", e.StatusCode, e.URL)
+}
+
+// Is reports whether the error corresponds to the given sentinel error.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrUpstreamUnavailable:
+		return e.StatusCode >= 500
+	default:
+		return false
+	}
+}
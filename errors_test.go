@@ -0,0 +1,103 @@
+package datos
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func newTestClient(status int, body string) *Client {
+	return newTestClientFunc(func(*http.Request) (int, string) {
+		return status, body
+	})
+}
+
+// newTestClientFunc returns a client whose responses are the status and
+// body returned by fn for each request.
+func newTestClientFunc(fn func(*http.Request) (int, string)) *Client {
+	return &Client{c: &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			status, body := fn(r)
+			return &http.Response{
+				StatusCode: status,
+				Header:     make(http.Header),
+				Body:       ioutil.NopCloser(strings.NewReader(body)),
+				Request:    r,
+			}, nil
+		}),
+	}}
+}
+
+func TestErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		status   int
+		body     string
+		expected error
+	}{
+		{"not found", http.StatusNotFound, "", ErrNotFound},
+		{"rate limited", http.StatusTooManyRequests, "", ErrRateLimited},
+		{"unavailable", http.StatusBadGateway, "", ErrUpstreamUnavailable},
+		{"decoding", http.StatusOK, "<html></html>", ErrDecoding},
+		{"dataset not found", http.StatusOK, `{"result":{"items":[]}}`, ErrNotFound},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestClient(tt.status, tt.body).Dataset(context.Background(), "foo", Params{})
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("wrong error, expected: %s, got: %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestErrorsUnwrap(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := &Client{c: http.DefaultClient}
+	_, err := c.Datasets(ctx, Params{})
+	if !errors.Is(err, ErrUpstreamUnavailable) {
+		t.Errorf("expected upstream unavailable error, got: %v", err)
+	}
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected error to wrap context.Canceled, got: %v", err)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	c := newTestClient(http.StatusServiceUnavailable, strings.Repeat("á", maxErrorBody))
+	_, err := c.Datasets(context.Background(), Params{Page: 2})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got: %v", err)
+	}
+
+	if apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("wrong status code, expected: %d, got: %d", http.StatusServiceUnavailable, apiErr.StatusCode)
+	}
+
+	if apiErr.URL != defaultBaseURL+"/catalog/dataset?_page=2" {
+		t.Errorf("wrong URL: %s", apiErr.URL)
+	}
+
+	if len(apiErr.Body) > maxErrorBody || !strings.HasPrefix(apiErr.Body, "áá") {
+		t.Errorf("wrong body snippet of %d bytes", len(apiErr.Body))
+	}
+
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected error to be only upstream unavailable")
+	}
+}
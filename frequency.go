@@ -0,0 +1,118 @@
+package datos
+
+import (
+	"strings"
+	"time"
+)
+
+// Periodicity is a known frequency of updates of a dataset.
+type Periodicity string
+
+// Periodicities of the frequencies of the Dublin Core collection
+// description vocabulary and the frequency authority table of the EU.
+const (
+	Continuous Periodicity = "continuous"
+	Hourly     Periodicity = "hourly"
+	Daily      Periodicity = "daily"
+	Weekly     Periodicity = "weekly"
+	Biweekly   Periodicity = "biweekly"
+	Monthly    Periodicity = "monthly"
+	Bimonthly  Periodicity = "bimonthly"
+	Quarterly  Periodicity = "quarterly"
+	Semiannual Periodicity = "semiannual"
+	Annual     Periodicity = "annual"
+	Biennial   Periodicity = "biennial"
+	Triennial  Periodicity = "triennial"
+	Irregular  Periodicity = "irregular"
+	Never      Periodicity = "never"
+)
+
+// year is the average length of a year in the Gregorian calendar.
+const year = time.Duration(365.2425 * 24 * float64(time.Hour))
+
+// periodicities are the known periodicities with the approximate time
+// between updates, which is 0 if there's no regular interval, and the
+// last segments of the links they can be recognized by.
+var periodicities = []struct {
+	periodicity Periodicity
+	interval    time.Duration
+	names       []string
+}{
+	{Continuous, 0, []string{"continuous", "cont", "update_cont"}},
+	{Hourly, time.Hour, []string{"hourly"}},
+	{Daily, 24 * time.Hour, []string{"daily"}},
+	{Weekly, 7 * 24 * time.Hour, []string{"weekly"}},
+	{Biweekly, 14 * 24 * time.Hour, []string{"biweekly"}},
+	{Monthly, year / 12, []string{"monthly"}},
+	{Bimonthly, year / 6, []string{"bimonthly"}},
+	{Quarterly, year / 4, []string{"quarterly"}},
+	{Semiannual, year / 2, []string{"semiannual", "annual_2"}},
+	{Annual, year, []string{"annual"}},
+	{Biennial, 2 * year, []string{"biennial"}},
+	{Triennial, 3 * year, []string{"triennial"}},
+	{Irregular, 0, []string{"irregular", "irreg"}},
+	{Never, 0, []string{"never"}},
+}
+
+// Periodicity returns the known periodicity of the frequency, recognized
+// by its link or by its duration, or an empty string if it's not known.
+func (f Frequency) Periodicity() Periodicity {
+	name := strings.ToLower(localName(strings.TrimRight(strings.TrimSpace(f.About), "/")))
+	for _, p := range periodicities {
+		for _, n := range p.names {
+			if name == n {
+				return p.periodicity
+			}
+		}
+	}
+
+	if d := f.durationInterval(); d > 0 {
+		for _, p := range periodicities {
+			if p.interval == d {
+				return p.periodicity
+			}
+		}
+	}
+	return ""
+}
+
+// Interval returns the approximate time between updates, using 30.44 days
+// for months and 365.24 days for years. It's 0 if the frequency is not
+// known or updates are not regular.
+func (f Frequency) Interval() time.Duration {
+	if d := f.durationInterval(); d > 0 {
+		return d
+	}
+
+	p := f.Periodicity()
+	for _, known := range periodicities {
+		if known.periodicity == p {
+			return known.interval
+		}
+	}
+	return 0
+}
+
+// durationInterval returns the time between updates given by the duration
+// of the frequency.
+func (f Frequency) durationInterval() time.Duration {
+	return time.Duration(f.Years*float64(year) +
+		f.Months*float64(year/12) +
+		f.Weeks*float64(7*24*time.Hour) +
+		f.Days*float64(24*time.Hour) +
+		f.Hours*float64(time.Hour) +
+		f.Minutes*float64(time.Minute) +
+		f.Seconds*float64(time.Second))
+}
+
+// Overdue reports whether the dataset should have been updated by the
+// given time according to its frequency, that is, if more than the
+// interval of its frequency has passed since it was modified. Datasets
+// without a regular frequency or a modification date are never overdue.
+func (d Dataset) Overdue(now time.Time) bool {
+	interval := d.AccrualPeriodicity.Interval()
+	if interval <= 0 || d.Modified.IsZero() {
+		return false
+	}
+	return now.Sub(d.Modified.Time) > interval
+}
@@ -0,0 +1,55 @@
+package datos
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFrequencyPeriodicity(t *testing.T) {
+	cases := []struct {
+		frequency   Frequency
+		periodicity Periodicity
+		interval    time.Duration
+	}{
+		{Frequency{About: "http://purl.org/cld/freq/daily"}, Daily, 24 * time.Hour},
+		{Frequency{About: "http://publications.europa.eu/resource/authority/frequency/MONTHLY"}, Monthly, year / 12},
+		{Frequency{About: "http://publications.europa.eu/resource/authority/frequency/IRREG"}, Irregular, 0},
+		{Frequency{Days: 7}, Weekly, 7 * 24 * time.Hour},
+		{Frequency{Years: 1}, Annual, year},
+		{Frequency{Months: 3}, Quarterly, year / 4},
+		{Frequency{Hours: 12}, "", 12 * time.Hour},
+		{Frequency{About: "http://example.com/a-veces"}, "", 0},
+		{Frequency{}, "", 0},
+	}
+
+	for _, c := range cases {
+		if p := c.frequency.Periodicity(); p != c.periodicity {
+			t.Errorf("wrong periodicity of %+v, expected: %q, got: %q", c.frequency, c.periodicity, p)
+		}
+
+		if i := c.frequency.Interval(); i != c.interval {
+			t.Errorf("wrong interval of %+v, expected: %s, got: %s", c.frequency, c.interval, i)
+		}
+	}
+}
+
+func TestDatasetOverdue(t *testing.T) {
+	modified := time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC)
+	d := Dataset{
+		Modified:           Datetime{Time: modified},
+		AccrualPeriodicity: Frequency{About: "http://purl.org/cld/freq/monthly"},
+	}
+
+	if d.Overdue(modified.AddDate(0, 0, 20)) {
+		t.Errorf("expected dataset not to be overdue after 20 days")
+	}
+
+	if !d.Overdue(modified.AddDate(0, 0, 40)) {
+		t.Errorf("expected dataset to be overdue after 40 days")
+	}
+
+	d.AccrualPeriodicity = Frequency{About: "http://purl.org/cld/freq/irregular"}
+	if d.Overdue(modified.AddDate(10, 0, 0)) {
+		t.Errorf("expected irregular dataset never to be overdue")
+	}
+}
@@ -1,5 +1,5 @@
 module github.com/erizocosmico/datos
 
-go 1.12
+go 1.13
 
 require github.com/sirupsen/logrus v1.4.2
@@ -0,0 +1,72 @@
+package datos
+
+import (
+	"net/http"
+	"time"
+)
+
+// Hooks are functions called by the client around the requests it makes,
+// both to the API and to download distributions, to attach logging, auth
+// headers, metrics and the like. Any of them can be nil.
+type Hooks struct {
+	// OnRequest is called before sending every request and can modify it,
+	// e.g. to add headers. If it returns an error, the request is not sent
+	// and fails with that error.
+	OnRequest func(req *http.Request) error
+	// OnResponse is called with the response to every request, before its
+	// body is read, or with the error if there is no response, along with
+	// the time it took.
+	OnResponse func(req *http.Request, resp *http.Response, err error, elapsed time.Duration)
+	// OnRetry is called before a failed download is retried by a
+	// Downloader, with the number of attempts made so far and the error of
+	// the last one.
+	OnRetry func(url string, attempt int, err error)
+}
+
+// WithHooks adds the given hooks to the client. Hooks added with several
+// calls are all called, in the order they were added.
+func WithHooks(h Hooks) Option {
+	return func(c *Client) {
+		c.hooks = append(c.hooks, h)
+	}
+}
+
+// hookTransport calls the hooks of a client around the requests made with
+// the next transport.
+type hookTransport struct {
+	next  http.RoundTripper
+	hooks []Hooks
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *hookTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Requests must not be modified by transports, so hooks get a copy.
+	req = req.Clone(req.Context())
+	for _, h := range t.hooks {
+		if h.OnRequest != nil {
+			if err := h.OnRequest(req); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	elapsed := time.Since(start)
+	for _, h := range t.hooks {
+		if h.OnResponse != nil {
+			h.OnResponse(req, resp, err, elapsed)
+		}
+	}
+
+	return resp, err
+}
+
+// retried calls the OnRetry hooks of the client.
+func (c *Client) retried(url string, attempt int, err error) {
+	for _, h := range c.hooks {
+		if h.OnRetry != nil {
+			h.OnRetry(url, attempt, err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package datos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHooks(t *testing.T) {
+	var auth string
+	var attempts int
+	transport := newTestClientFunc(func(r *http.Request) (int, string) {
+		auth = r.Header.Get("Authorization")
+		attempts++
+		if r.URL.Path == "/flaky" && attempts == 1 {
+			return http.StatusBadGateway, ""
+		}
+		return http.StatusOK, `{"result":{"items":[]}}`
+	}).c
+
+	var statuses []int
+	var retries []string
+	c, err := NewClient(
+		WithHTTPClient(transport),
+		WithHooks(Hooks{
+			OnRequest: func(r *http.Request) error {
+				r.Header.Set("Authorization", "Bearer foo")
+				return nil
+			},
+		}),
+		WithHooks(Hooks{
+			OnResponse: func(r *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+				statuses = append(statuses, resp.StatusCode)
+			},
+			OnRetry: func(url string, attempt int, err error) {
+				retries = append(retries, url)
+			},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := c.Themes(context.Background(), Params{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if auth != "Bearer foo" || len(statuses) != 1 || statuses[0] != http.StatusOK {
+		t.Errorf("expected hooks to be called, got auth: %q, statuses: %v", auth, statuses)
+	}
+
+	attempts = 0
+	d := NewDownloader(c, 1, 1)
+	d.backoff = 0
+	result := <-d.Download(context.Background(), []DownloadJob{{
+		Distribution: Distribution{AccessURL: "http://example.com/flaky"},
+		Create: func() (io.WriteCloser, error) {
+			return nopWriteCloser{new(bytes.Buffer)}, nil
+		},
+	}})
+
+	if result.Err != nil || len(retries) != 1 || retries[0] != "http://example.com/flaky" {
+		t.Errorf("expected retry hook to be called once, got: %v, err: %v", retries, result.Err)
+	}
+
+	errDenied := errors.New("denied")
+	c, err = NewClient(WithHTTPClient(transport), WithHooks(Hooks{
+		OnRequest: func(*http.Request) error { return errDenied },
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := c.Themes(context.Background(), Params{}); !errors.Is(err, errDenied) {
+		t.Errorf("expected request to be stopped by the hook, got: %v", err)
+	}
+}
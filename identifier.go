@@ -0,0 +1,106 @@
+package datos
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// catalogHost is the host of the datos.gob.es portal and API, whose links
+// to datasets all end with the slug of the dataset.
+const catalogHost = "datos.gob.es"
+
+// formatExtensions are the extensions the API accepts at the end of a link
+// to choose the format of the response.
+var formatExtensions = []string{".json", ".xml", ".rdf", ".ttl", ".csv"}
+
+// CanonicalID returns a stable key for a dataset identifier, so the same
+// dataset has the same key no matter which endpoint or portal the
+// identifier comes from. These identifiers have the same key:
+//
+//	http://datos.gob.es/catalogo/a02002834-centros-de-salud
+//	https://datos.gob.es/es/catalogo/a02002834-centros-de-salud
+//	https://datos.gob.es/apidata/catalog/dataset/a02002834-centros-de-salud.json
+//	a02002834-centros-de-salud
+//
+// Links to datos.gob.es become the slug of the dataset in lowercase. Links
+// to other portals lose their scheme, query and fragment, and URNs only
+// have their namespace lowercased. Any other identifier is taken as a slug.
+func CanonicalID(id string) string {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return ""
+	}
+
+	if len(id) > 4 && strings.EqualFold(id[:4], "urn:") {
+		parts := strings.SplitN(id[4:], ":", 2)
+		parts[0] = strings.ToLower(parts[0])
+		return "urn:" + strings.Join(parts, ":")
+	}
+
+	host, p := splitIdentifier(id)
+	if host == "" || host == catalogHost {
+		return canonicalSlug(p)
+	}
+
+	if p = strings.Trim(p, "/"); p == "" {
+		return host
+	}
+	return host + "/" + p
+}
+
+// splitIdentifier returns the host, in lowercase and without www, and the
+// unescaped path of an identifier. The host is empty if the identifier is
+// not a link.
+func splitIdentifier(id string) (host, p string) {
+	if !strings.Contains(id, "://") {
+		lower := strings.ToLower(id)
+		if !strings.HasPrefix(lower, catalogHost+"/") && !strings.HasPrefix(lower, "www.") {
+			return "", unescape(id)
+		}
+		id = "http://" + id
+	}
+
+	u, err := url.Parse(id)
+	if err != nil {
+		return "", unescape(id)
+	}
+
+	host = strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+	return host, u.Path
+}
+
+// canonicalSlug returns the last segment of the path in lowercase, without
+// any format extension.
+func canonicalSlug(p string) string {
+	slug := strings.ToLower(path.Base("/" + strings.Trim(p, "/")))
+	if slug == "/" {
+		return ""
+	}
+
+	for _, ext := range formatExtensions {
+		if strings.HasSuffix(slug, ext) {
+			return strings.TrimSuffix(slug, ext)
+		}
+	}
+	return slug
+}
+
+func unescape(s string) string {
+	if u, err := url.PathUnescape(s); err == nil {
+		return u
+	}
+	return s
+}
+
+// Key returns the canonical identifier of the dataset, as returned by
+// CanonicalID, to deduplicate datasets coming from different sources.
+func (d Dataset) Key() string {
+	if d.About != "" {
+		return CanonicalID(d.About)
+	}
+	return CanonicalID(d.Identifier)
+}
@@ -0,0 +1,44 @@
+package datos
+
+import "testing"
+
+func TestCanonicalID(t *testing.T) {
+	const slug = "a02002834-centros-de-salud"
+	cases := []struct {
+		id       string
+		expected string
+	}{
+		{"http://datos.gob.es/catalogo/a02002834-centros-de-salud", slug},
+		{"https://datos.gob.es/catalogo/a02002834-centros-de-salud/", slug},
+		{"https://datos.gob.es/es/catalogo/a02002834-centros-de-salud", slug},
+		{"https://www.datos.gob.es/en/catalogo/a02002834-centros-de-salud?foo=bar#top", slug},
+		{"https://datos.gob.es/apidata/catalog/dataset/a02002834-centros-de-salud.json", slug},
+		{"datos.gob.es/catalogo/a02002834-centros-de-salud", slug},
+		{"  A02002834-Centros-De-Salud ", slug},
+		{"a02002834-centros-de-salud", slug},
+		{"https://datos.gob.es/catalogo/l01280066-padr%C3%B3n-municipal", "l01280066-padrón-municipal"},
+		{"l01280066-padr%C3%B3n-municipal", "l01280066-padrón-municipal"},
+		{"urn:ine:es:TABLA:T3:472:23995", "urn:ine:es:TABLA:T3:472:23995"},
+		{"URN:INE:es:TABLA:T3:472:23995", "urn:ine:es:TABLA:T3:472:23995"},
+		{"https://opendata.aragon.es/datos/catalogo/dataset/calidad-del-aire", "opendata.aragon.es/datos/catalogo/dataset/calidad-del-aire"},
+		{"http://www.opendata.aragon.es/datos/catalogo/dataset/calidad-del-aire/", "opendata.aragon.es/datos/catalogo/dataset/calidad-del-aire"},
+		{"https://Datos.Madrid.es:443/egob/catalogo/300107-0-agenda-actividades-eventos", "datos.madrid.es/egob/catalogo/300107-0-agenda-actividades-eventos"},
+		{"https://example.com", "example.com"},
+		{"", ""},
+		{"   ", ""},
+	}
+
+	for _, c := range cases {
+		if got := CanonicalID(c.id); got != c.expected {
+			t.Errorf("CanonicalID(%q): expected %q, got %q", c.id, c.expected, got)
+		}
+	}
+}
+
+func TestDatasetKey(t *testing.T) {
+	a := Dataset{About: "http://datos.gob.es/catalogo/e05024401-turismo"}
+	b := Dataset{Identifier: "https://datos.gob.es/es/catalogo/e05024401-turismo"}
+	if a.Key() != b.Key() || a.Key() != "e05024401-turismo" {
+		t.Errorf("expected the same key, got %q and %q", a.Key(), b.Key())
+	}
+}
@@ -0,0 +1,73 @@
+package datos
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// ReadJSONLD reads the description of a single dataset serialized as
+// JSON-LD, in either its compacted or expanded form, as returned by some
+// _about URLs and regional portals instead of the plain JSON of the API.
+//
+// The dataset is the first node with the Dataset type or, if there is
+// none, the first node of the document, because descriptions of a single
+// dataset often leave out its type. The same properties as in ReadDCAT are
+// read.
+func ReadJSONLD(r io.Reader) (Dataset, error) {
+	doc, err := parseJSONLD(r)
+	if err != nil {
+		return Dataset{}, err
+	}
+
+	if ds := doc.datasets(); len(ds) > 0 {
+		return ds[0], nil
+	}
+
+	for _, n := range doc.all {
+		node := dcatNode{n, doc}
+		if len(node.strings("title")) > 0 {
+			return dcatDataset(node), nil
+		}
+	}
+
+	return Dataset{}, newError(ErrNotFound, nil, "datos: no dataset found in JSON-LD document")
+}
+
+// DatasetJSONLD requests the given URL, usually the _about URL of a dataset,
+// asking for JSON-LD and returns the dataset it describes. Responses are not
+// cached, since they're not API responses.
+func (c *Client) DatasetJSONLD(ctx context.Context, url string) (Dataset, error) {
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			return Dataset{}, err
+		}
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return Dataset{}, fmt.Errorf("datos: unable to create request: %s", err)
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Add("Accept", "application/ld+json, application/json;q=0.9")
+	resp, err := c.c.Do(req)
+	if err != nil {
+		return Dataset{}, newError(ErrUpstreamUnavailable, err, "datos: unable to get data from %q: %s", url, err)
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return Dataset{}, newError(ErrUpstreamUnavailable, err, "datos: error reading response body: %s", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return Dataset{}, newAPIError(resp, body)
+	}
+
+	return ReadJSONLD(bytes.NewReader(body))
+}
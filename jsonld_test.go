@@ -0,0 +1,95 @@
+package datos
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const testJSONLDCompacted = `{
+	"@context": {
+		"dct": "http://purl.org/dc/terms/",
+		"titulo": {"@id": "http://purl.org/dc/terms/title", "@language": "es"},
+		"palabras": {"@id": "http://www.w3.org/ns/dcat#keyword", "@container": "@set"},
+		"distribucion": "http://www.w3.org/ns/dcat#distribution"
+	},
+	"@id": "https://example.com/dataset/bus",
+	"titulo": "Paradas de autobús",
+	"palabras": ["transporte", "autobús"],
+	"dct:modified": "2020-05-04T10:00:00Z",
+	"distribucion": {"@list": [{"dct:format": "text/csv", "http://www.w3.org/ns/dcat#accessURL": {"@id": "https://example.com/bus.csv"}}]}
+}`
+
+const testJSONLDExpanded = `[{
+	"@id": "https://example.com/dataset/bus",
+	"@type": ["http://www.w3.org/ns/dcat#Dataset"],
+	"http://purl.org/dc/terms/title": [{"@value": "Paradas de autobús", "@language": "es"}],
+	"http://www.w3.org/ns/dcat#keyword": [{"@value": "transporte"}, {"@value": "autobús"}],
+	"http://purl.org/dc/terms/modified": [{"@value": "2020-05-04T10:00:00Z", "@type": "http://www.w3.org/2001/XMLSchema#dateTime"}],
+	"http://www.w3.org/ns/dcat#distribution": [{
+		"http://purl.org/dc/terms/format": [{"@value": "text/csv"}],
+		"http://www.w3.org/ns/dcat#accessURL": [{"@id": "https://example.com/bus.csv"}]
+	}]
+}]`
+
+func TestReadJSONLD(t *testing.T) {
+	for name, doc := range map[string]string{
+		"compacted": testJSONLDCompacted,
+		"expanded":  testJSONLDExpanded,
+	} {
+		t.Run(name, func(t *testing.T) {
+			d, err := ReadJSONLD(strings.NewReader(doc))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if d.About != "https://example.com/dataset/bus" || d.Title.Default() != "Paradas de autobús" ||
+				strings.Join(d.Keywords, ",") != "transporte,autobús" || d.Modified.Year() != 2020 {
+				t.Errorf("wrong dataset: %+v", d)
+			}
+
+			if len(d.Distribution) != 1 || d.Distribution[0].AccessURL != "https://example.com/bus.csv" ||
+				d.Distribution[0].Format.Value != "text/csv" {
+				t.Errorf("wrong distributions: %+v", d.Distribution)
+			}
+		})
+	}
+
+	_, err := ReadJSONLD(strings.NewReader(`{"@id": "https://example.com/foo"}`))
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+
+	_, err = ReadJSONLD(strings.NewReader(`{`))
+	if !errors.Is(err, ErrDecoding) {
+		t.Errorf("expected decoding error, got: %v", err)
+	}
+}
+
+func TestDatasetJSONLD(t *testing.T) {
+	var accept string
+	c := newTestClientFunc(func(r *http.Request) (int, string) {
+		accept = r.Header.Get("Accept")
+		return http.StatusOK, testJSONLDExpanded
+	})
+
+	d, err := c.DatasetJSONLD(context.Background(), "https://example.com/dataset/bus")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.HasPrefix(accept, "application/ld+json") {
+		t.Errorf("expected JSON-LD to be requested, got: %q", accept)
+	}
+
+	if d.About != "https://example.com/dataset/bus" {
+		t.Errorf("wrong dataset: %+v", d)
+	}
+
+	_, err = newTestClient(http.StatusNotFound, `{}`).DatasetJSONLD(context.Background(), "https://example.com/foo")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package datos
+
+import (
+	"context"
+	"errors"
+)
+
+// KeywordMatch is the way DatasetsByKeywords matches several keywords.
+type KeywordMatch byte
+
+const (
+	// AllKeywords matches the datasets with all the keywords.
+	AllKeywords KeywordMatch = iota
+	// AnyKeyword matches the datasets with any of the keywords.
+	AnyKeyword
+)
+
+// DatasetsByKeywords returns the datasets with all or any of the given
+// keywords, depending on match. The API only accepts one keyword per
+// request, so the results are combined in the client:
+//
+// With AllKeywords only the datasets with the first keyword are requested
+// and the rest of keywords are applied to the results, so a page of
+// results may contain fewer datasets than the page size.
+//
+// With AnyKeyword a request is made for each keyword with the same params
+// and the results are merged, without duplicates, and sorted by the sort
+// field of the params, so a page of results may contain more datasets than
+// the page size.
+func (c *Client) DatasetsByKeywords(
+	ctx context.Context,
+	keywords []string,
+	match KeywordMatch,
+	params Params,
+) ([]Dataset, error) {
+	if len(keywords) == 0 {
+		return nil, errors.New("datos: no keywords given")
+	}
+
+	if match == AllKeywords {
+		result, err := c.DatasetsByKeyword(ctx, keywords[0], params)
+		if err != nil {
+			return nil, err
+		}
+
+		datasets := result[:0]
+		for _, d := range result {
+			if hasKeywords(d, keywords[1:]) {
+				datasets = append(datasets, d)
+			}
+		}
+		return datasets, nil
+	}
+
+	var result []Dataset
+	seen := make(map[string]bool)
+	for _, kw := range keywords {
+		datasets, err := c.DatasetsByKeyword(ctx, kw, params)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, d := range datasets {
+			if key := d.Key(); !seen[key] || key == "" {
+				seen[key] = true
+				result = append(result, d)
+			}
+		}
+	}
+
+	if len(keywords) > 1 {
+		sortDatasets(result, params.Sort)
+	}
+
+	return result, nil
+}
+
+func hasKeywords(d Dataset, keywords []string) bool {
+	for _, kw := range keywords {
+		if !equalFold(d.Keywords, kw) {
+			return false
+		}
+	}
+	return true
+}
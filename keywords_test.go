@@ -0,0 +1,55 @@
+package datos
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDatasetsByKeywords(t *testing.T) {
+	responses := map[string]string{
+		"agua":   `{"result":{"items":[{"_about":"http://datos.gob.es/catalogo/a","keyword":["agua","riego"],"issued":"lun, 01 ene 2018 00:00:00 GMT+0000"},{"_about":"http://datos.gob.es/catalogo/b","keyword":["agua"],"issued":"mar, 01 ene 2019 00:00:00 GMT+0000"}]}}`,
+		"riego":  `{"result":{"items":[{"_about":"http://datos.gob.es/catalogo/a","keyword":["agua","riego"],"issued":"lun, 01 ene 2018 00:00:00 GMT+0000"},{"_about":"http://datos.gob.es/catalogo/c","keyword":["Riego"],"issued":"mié, 01 ene 2020 00:00:00 GMT+0000"}]}}`,
+		"sequía": `{"result":{"items":[]}}`,
+	}
+
+	var paths []string
+	c := newTestClientFunc(func(r *http.Request) (int, string) {
+		paths = append(paths, r.URL.Path)
+		kw := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		return http.StatusOK, responses[kw]
+	})
+
+	ctx := context.Background()
+	ds, err := c.DatasetsByKeywords(ctx, []string{"agua", "riego"}, AllKeywords, Params{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(ds) != 1 || ds[0].Key() != "a" {
+		t.Errorf("wrong datasets with all keywords: %v", ds)
+	}
+
+	if len(paths) != 1 || paths[0] != "/apidata/catalog/dataset/keyword/agua" {
+		t.Errorf("expected a single request for the first keyword, got: %v", paths)
+	}
+
+	ds, err = c.DatasetsByKeywords(ctx, []string{"agua", "riego", "sequía"}, AnyKeyword, Params{Sort: "-issued"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var keys []string
+	for _, d := range ds {
+		keys = append(keys, d.Key())
+	}
+
+	if strings.Join(keys, ",") != "c,b,a" {
+		t.Errorf("wrong datasets with any keyword, expected c,b,a, got: %s", strings.Join(keys, ","))
+	}
+
+	if _, err := c.DatasetsByKeywords(ctx, nil, AnyKeyword, Params{}); err == nil {
+		t.Errorf("expected error without keywords")
+	}
+}
@@ -0,0 +1,96 @@
+package datos
+
+import "strings"
+
+// Languages of the labels available for the fixed taxonomies.
+const (
+	Spanish = "es"
+	English = "en"
+)
+
+// themeLabels are the labels of the themes of the NTI-RISP taxonomy used by
+// the API, keyed by theme identifier and language. The API only returns
+// some of them in spanish.
+var themeLabels = map[string]map[string]string{
+	"ciencia-tecnologia":         {Spanish: "Ciencia y tecnología", English: "Science and technology"},
+	"comercio":                   {Spanish: "Comercio", English: "Commerce"},
+	"cultura-ocio":               {Spanish: "Cultura y ocio", English: "Culture and leisure"},
+	"demografia":                 {Spanish: "Demografía", English: "Demography"},
+	"deporte":                    {Spanish: "Deporte", English: "Sport"},
+	"economia":                   {Spanish: "Economía", English: "Economy"},
+	"educacion":                  {Spanish: "Educación", English: "Education"},
+	"empleo":                     {Spanish: "Empleo", English: "Employment"},
+	"energia":                    {Spanish: "Energía", English: "Energy"},
+	"hacienda":                   {Spanish: "Hacienda", English: "Treasury"},
+	"industria":                  {Spanish: "Industria", English: "Industry"},
+	"legislacion-justicia":       {Spanish: "Legislación y justicia", English: "Legislation and justice"},
+	"medio-ambiente":             {Spanish: "Medio ambiente", English: "Environment"},
+	"medio-rural-pesca":          {Spanish: "Medio rural y pesca", English: "Rural environment and fisheries"},
+	"salud":                      {Spanish: "Salud", English: "Health"},
+	"sector-publico":             {Spanish: "Sector público", English: "Public sector"},
+	"seguridad":                  {Spanish: "Seguridad", English: "Security"},
+	"sociedad-bienestar":         {Spanish: "Sociedad y bienestar", English: "Society and welfare"},
+	"transporte":                 {Spanish: "Transporte", English: "Transport"},
+	"turismo":                    {Spanish: "Turismo", English: "Tourism"},
+	"urbanismo-infraestructuras": {Spanish: "Urbanismo e infraestructuras", English: "Town planning and infrastructures"},
+	"vivienda":                   {Spanish: "Vivienda", English: "Housing"},
+}
+
+// spatialTypeLabels are the labels of the spatial types keyed by language.
+var spatialTypeLabels = map[SpatialType]map[string]string{
+	Autonomy: {Spanish: "Comunidad autónoma", English: "Autonomous community"},
+	Country:  {Spanish: "País", English: "Country"},
+	Province: {Spanish: "Provincia", English: "Province"},
+}
+
+// ID returns the identifier of the theme, which is the last segment of
+// its link.
+func (t Theme) ID() string {
+	if t.Notation != "" && !strings.Contains(t.Notation, "/") {
+		return t.Notation
+	}
+	return lastSegment(t.About)
+}
+
+// Label returns the label of the theme in the given language. If there is
+// no translation for the language, the first label returned by the API is
+// used instead.
+func (t Theme) Label(lang string) string {
+	if l, ok := ThemeLabel(t.ID(), lang); ok {
+		return l
+	}
+
+	if len(t.Labels) > 0 {
+		return t.Labels[0]
+	}
+
+	return t.ID()
+}
+
+// ThemeLabel returns the label of the theme with the given identifier
+// (e.g. "medio-ambiente") or link in the given language.
+func ThemeLabel(theme, lang string) (string, bool) {
+	labels, ok := themeLabels[lastSegment(theme)]
+	if !ok {
+		return "", false
+	}
+
+	l, ok := labels[strings.ToLower(lang)]
+	return l, ok
+}
+
+// Label returns the label of the spatial type in the given language.
+func (t SpatialType) Label(lang string) string {
+	if l, ok := spatialTypeLabels[t][strings.ToLower(lang)]; ok {
+		return l
+	}
+	return t.String()
+}
+
+func lastSegment(uri string) string {
+	uri = strings.TrimRight(uri, "/")
+	if i := strings.LastIndex(uri, "/"); i >= 0 {
+		return uri[i+1:]
+	}
+	return uri
+}
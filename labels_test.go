@@ -0,0 +1,60 @@
+package datos
+
+import "testing"
+
+func TestThemeLabel(t *testing.T) {
+	theme := Theme{
+		About:  "http://datos.gob.es/kos/sector-publico/sector/medio-ambiente",
+		Labels: []string{"Medio ambiente"},
+	}
+
+	if l := theme.Label(English); l != "Environment" {
+		t.Errorf("wrong english label, expected: Environment, got: %s", l)
+	}
+
+	if l := theme.Label(Spanish); l != "Medio ambiente" {
+		t.Errorf("wrong spanish label, expected: Medio ambiente, got: %s", l)
+	}
+
+	if l := theme.Label("fr"); l != "Medio ambiente" {
+		t.Errorf("expected fallback to API label, got: %s", l)
+	}
+
+	unknown := Theme{About: "http://example.com/foo", Labels: []string{"Foo"}}
+	if l := unknown.Label(English); l != "Foo" {
+		t.Errorf("expected fallback to API label, got: %s", l)
+	}
+}
+
+func TestSpatialTypeLabel(t *testing.T) {
+	if l := Province.Label(English); l != "Province" {
+		t.Errorf("wrong label, expected: Province, got: %s", l)
+	}
+
+	if l := Autonomy.Label("xx"); l != Autonomy.String() {
+		t.Errorf("expected fallback to %s, got: %s", Autonomy.String(), l)
+	}
+}
+
+func TestLabelsComplete(t *testing.T) {
+	// The NTI-RISP taxonomy has 22 themes.
+	if len(themeLabels) != 22 {
+		t.Errorf("wrong number of themes, expected: 22, got: %d", len(themeLabels))
+	}
+
+	for id, labels := range themeLabels {
+		for _, lang := range []string{Spanish, English} {
+			if labels[lang] == "" {
+				t.Errorf("missing %s label of theme %s", lang, id)
+			}
+		}
+	}
+
+	for _, typ := range []SpatialType{Autonomy, Country, Province} {
+		for _, lang := range []string{Spanish, English} {
+			if spatialTypeLabels[typ][lang] == "" {
+				t.Errorf("missing %s label of spatial type %s", lang, typ)
+			}
+		}
+	}
+}
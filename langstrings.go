@@ -0,0 +1,119 @@
+package datos
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// LangString is a text in a language. Lang is empty if the language is not
+// known.
+type LangString struct {
+	Text string `json:"text"`
+	Lang string `json:"lang"`
+}
+
+// LangStrings is a text in several languages, such as Spanish and the
+// co-official languages, or several texts without language.
+type LangStrings []LangString
+
+// In returns the text in the given language, or an empty string if there
+// is none. Regional variants match their language, so "es" matches "es-ES".
+func (s LangStrings) In(lang string) string {
+	for _, l := range s {
+		if strings.EqualFold(l.Lang, lang) {
+			return l.Text
+		}
+	}
+
+	for _, l := range s {
+		if i := strings.IndexAny(l.Lang, "-_"); i > 0 && strings.EqualFold(l.Lang[:i], lang) {
+			return l.Text
+		}
+	}
+	return ""
+}
+
+// Default returns the text in Spanish, the language of the portal. If there
+// is none, it returns the first text without language or the first one.
+func (s LangStrings) Default() string {
+	if text := s.In(Spanish); text != "" {
+		return text
+	}
+
+	for _, l := range s {
+		if l.Lang == "" {
+			return l.Text
+		}
+	}
+
+	if len(s) > 0 {
+		return s[0].Text
+	}
+	return ""
+}
+
+// Texts returns the texts in all languages.
+func (s LangStrings) Texts() []string {
+	result := make([]string, len(s))
+	for i, l := range s {
+		result[i] = l.Text
+	}
+	return result
+}
+
+// UnmarshalJSON decodes either a single text or a list of them. Texts can
+// be strings, without language, or objects with the text in the _value,
+// value or text keys and the language in the _lang, lang or @language
+// keys. Null decodes to no texts.
+func (s *LangStrings) UnmarshalJSON(b []byte) error {
+	var val interface{}
+	if err := json.Unmarshal(b, &val); err != nil {
+		return err
+	}
+
+	var values []interface{}
+	switch v := val.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		values = v
+	default:
+		values = []interface{}{v}
+	}
+
+	for _, elem := range values {
+		switch v := elem.(type) {
+		case nil:
+		case string:
+			if v != "" {
+				*s = append(*s, LangString{Text: v})
+			}
+		case map[string]interface{}:
+			text, ok := firstKey(v, "_value", "value", "text", "@value")
+			if !ok {
+				return fmt.Errorf("expecting object with _value, got keys %s", strings.Join(objectKeys(v), ", "))
+			}
+
+			lang, _ := firstKey(v, "_lang", "lang", "@language")
+			if text != "" {
+				*s = append(*s, LangString{Text: text, Lang: lang})
+			}
+		default:
+			return fmt.Errorf("expecting string, object or array, got %T", v)
+		}
+	}
+
+	return nil
+}
+
+// firstKey returns the string value of the first of the keys in the
+// object.
+func firstKey(m map[string]interface{}, keys ...string) (string, bool) {
+	for _, k := range keys {
+		if v, ok := m[k].(string); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
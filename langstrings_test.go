@@ -0,0 +1,73 @@
+package datos
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLangStrings(t *testing.T) {
+	var s LangStrings
+	err := json.Unmarshal([]byte(`[
+		{"_value": "Calidad del aire", "_lang": "es"},
+		{"_value": "Qualitat de l'aire", "_lang": "ca"},
+		{"_value": "Air quality", "_lang": "en-GB"},
+		null
+	]`), &s)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cases := map[string]string{
+		"es": "Calidad del aire",
+		"ca": "Qualitat de l'aire",
+		"en": "Air quality",
+		"EN": "Air quality",
+		"eu": "",
+	}
+	for lang, expected := range cases {
+		if got := s.In(lang); got != expected {
+			t.Errorf("wrong text in %s, expected: %q, got: %q", lang, expected, got)
+		}
+	}
+
+	if s.Default() != "Calidad del aire" {
+		t.Errorf("wrong default text: %s", s.Default())
+	}
+
+	if len(s.Texts()) != 3 {
+		t.Errorf("wrong texts: %v", s.Texts())
+	}
+}
+
+func TestLangStringsDefault(t *testing.T) {
+	cases := []struct {
+		json     string
+		expected string
+	}{
+		{`"Calidad del aire"`, "Calidad del aire"},
+		{`[{"text": "Air quality", "lang": "en"}, "Calidad del aire"]`, "Calidad del aire"},
+		{`[{"text": "Air quality", "lang": "en"}]`, "Air quality"},
+		{`{"@value": "Aire", "@language": "es-ES"}`, "Aire"},
+		{`null`, ""},
+		{`[]`, ""},
+	}
+
+	for _, c := range cases {
+		var s LangStrings
+		if err := json.Unmarshal([]byte(c.json), &s); err != nil {
+			t.Errorf("unexpected error decoding %s: %s", c.json, err)
+			continue
+		}
+
+		if got := s.Default(); got != c.expected {
+			t.Errorf("wrong default text of %s, expected: %q, got: %q", c.json, c.expected, got)
+		}
+	}
+
+	var s LangStrings
+	for _, invalid := range []string{`12`, `[{"_lang": "es"}]`} {
+		if err := json.Unmarshal([]byte(invalid), &s); err == nil {
+			t.Errorf("expected error decoding %s", invalid)
+		}
+	}
+}
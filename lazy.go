@@ -0,0 +1,102 @@
+package datos
+
+import (
+	"context"
+	"errors"
+)
+
+// loadPageSize is the page size used to load the distributions of a
+// dataset.
+const loadPageSize = 50
+
+// WithLazyDistributions makes the client skip the distributions of the
+// datasets it returns, which are the bulk of the responses, saving the
+// time and memory needed to decode them. Their Distribution field is nil
+// and the distributions can be loaded on demand with LoadDistributions.
+//
+// Queries filtering by format still decode the distributions, since
+// they're needed to apply the filter.
+func WithLazyDistributions() Option {
+	return func(c *Client) {
+		c.lazy = true
+	}
+}
+
+// LoadDistributions requests all the distributions of the dataset to the
+// catalog and sets them in the dataset, e.g. for datasets returned by a
+// client using WithLazyDistributions. The API does not return the
+// distributions of some datasets by dataset, so if there are none, they're
+// taken from the dataset itself, requested with its distributions.
+func (d *Dataset) LoadDistributions(ctx context.Context, c Catalog) error {
+	id := lastSegment(d.About)
+	if id == "" {
+		id = d.Key()
+	}
+
+	result, err := distributionsByDataset(ctx, c, id)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	if len(result) == 0 {
+		ds, derr := c.Dataset(withDistributions(ctx), id, Params{})
+		switch {
+		case derr == nil:
+			result = ds.Distribution
+		case err != nil:
+			return err
+		case !errors.Is(derr, ErrNotFound):
+			return derr
+		}
+	}
+
+	d.Distribution = result
+	return nil
+}
+
+// distributionsByDataset requests all the pages of distributions of the
+// dataset with the given ID.
+func distributionsByDataset(ctx context.Context, c Catalog, id string) (Distributions, error) {
+	var result Distributions
+	params := Params{PageSize: loadPageSize}
+	for {
+		page := Page{Total: -1}
+		dists, err := c.DistributionsByDataset(CapturePage(ctx, &page), id, params)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, dists...)
+		if page.Size > 0 && !page.HasNext() || page.Size == 0 && len(dists) < loadPageSize {
+			return result, nil
+		}
+		params.Page++
+	}
+}
+
+type distributionsKey struct{}
+
+// withDistributions returns a context that makes the client decode the
+// distributions of datasets even if it uses WithLazyDistributions.
+func withDistributions(ctx context.Context) context.Context {
+	return context.WithValue(ctx, distributionsKey{}, true)
+}
+
+// skipDistributions reports whether the distributions of the datasets
+// requested with the context must not be decoded.
+func (c *Client) skipDistributions(ctx context.Context) bool {
+	return c.lazy && ctx.Value(distributionsKey{}) == nil
+}
+
+// datasetFields has the same fields as Dataset, so it can be decoded
+// without the methods of Dataset.
+type datasetFields Dataset
+
+// lazyDataset decodes a dataset without its distributions.
+type lazyDataset struct {
+	*Dataset
+}
+
+func (d *lazyDataset) UnmarshalJSON(b []byte) error {
+	return decodeDataset(b, d.Dataset, true)
+}
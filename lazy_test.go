@@ -0,0 +1,145 @@
+package datos
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestLazyDistributions(t *testing.T) {
+	c, err := NewClient(WithLazyDistributions(), WithHTTPClient(newTestClientFunc(func(r *http.Request) (int, string) {
+		if !strings.HasPrefix(r.URL.Path, "/apidata/catalog/distribution/dataset/") {
+			return http.StatusOK, queryResponse
+		}
+
+		// 60 distributions, in pages of 50.
+		n := 50
+		if r.URL.Query().Get("_page") == "1" {
+			n = 10
+		}
+
+		items := make([]string, n)
+		for i := range items {
+			items[i] = fmt.Sprintf(`{"_about":"dist-%d","format":{"value":"text/csv"}}`, i)
+		}
+		return http.StatusOK, `{"result":{"items":[` + strings.Join(items, ",") + `]}}`
+	}).c))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ctx := context.Background()
+	ds, err := c.Datasets(ctx, Params{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(ds) != 3 || ds[0].About != "a" || ds[0].Title.Default() != "Calidad del aire" || ds[0].Modified.IsZero() {
+		t.Fatalf("wrong datasets: %+v", ds)
+	}
+
+	for _, d := range ds {
+		if d.Distribution != nil {
+			t.Errorf("expected distributions of %s not to be decoded, got: %v", d.About, d.Distribution)
+		}
+	}
+
+	if err := ds[0].LoadDistributions(ctx, c); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(ds[0].Distribution) != 60 {
+		t.Errorf("wrong number of distributions, expected: 60, got: %d", len(ds[0].Distribution))
+	}
+
+	ds, err = c.Query().Theme("medio-ambiente").Format("csv").Do(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(ds) != 2 || len(ds[0].Distribution) != 1 {
+		t.Errorf("expected distributions to be decoded to filter by format, got: %+v", ds)
+	}
+}
+
+// datasetResponse is a response of the API with a dataset and its
+// distributions, as returned by /catalog/dataset/{id}.
+const datasetResponse = `{"format":"linked-data-api","version":"0.2","result":{
+	"_about":"https://datos.gob.es/apidata/catalog/dataset/l01280796-calidad-del-aire.json",
+	"definition":"https://datos.gob.es/apidata/meta/catalog/dataset/_id/definition.json",
+	"extendedMetadataVersion":"https://datos.gob.es/apidata/catalog/dataset/l01280796-calidad-del-aire.json?_metadata=all",
+	"first":"https://datos.gob.es/apidata/catalog/dataset/l01280796-calidad-del-aire.json?_page=0",
+	"items":[{
+		"_about":"https://datos.gob.es/catalogo/l01280796-calidad-del-aire",
+		"identifier":"https://datos.gob.es/catalogo/l01280796-calidad-del-aire",
+		"title":[{"_value":"Calidad del aire","_lang":"es"}],
+		"distribution":[
+			{"_about":"https://datos.gob.es/catalogo/l01280796-calidad-del-aire/resource/1","accessURL":"https://example.com/aire.csv","format":{"_about":"https://datos.gob.es/apidata/catalog/dataset/formato","value":"text/csv"},"title":"Datos en CSV"},
+			{"_about":"https://datos.gob.es/catalogo/l01280796-calidad-del-aire/resource/2","accessURL":"https://example.com/aire.json","format":{"value":"application/json"},"title":"Datos en JSON"}
+		]
+	}],
+	"itemsPerPage":10,
+	"page":0,
+	"startIndex":1,
+	"type":["http://purl.org/linked-data/api/vocab#ListEndpoint","http://purl.org/linked-data/api/vocab#Page"]
+}}`
+
+func TestLoadDistributionsFallback(t *testing.T) {
+	cases := []struct {
+		name          string
+		status        int
+		body          string
+		datasetStatus int
+		expected      int
+		err           bool
+	}{
+		{"no distributions by dataset", http.StatusOK, `{"format":"linked-data-api","version":"0.2","result":{"items":[],"itemsPerPage":50,"page":0,"startIndex":1}}`, http.StatusOK, 2, false},
+		{"distributions by dataset not found", http.StatusNotFound, "", http.StatusOK, 2, false},
+		{"dataset not found either", http.StatusOK, `{"result":{"items":[]}}`, http.StatusNotFound, 0, false},
+		{"both not found", http.StatusNotFound, "", http.StatusNotFound, 0, true},
+		{"dataset unavailable", http.StatusOK, `{"result":{"items":[]}}`, http.StatusServiceUnavailable, 0, true},
+		{"distributions unavailable", http.StatusServiceUnavailable, "", http.StatusOK, 0, true},
+	}
+
+	for _, tt := range cases {
+		var paths []string
+		c, err := NewClient(WithLazyDistributions(), WithHTTPClient(newTestClientFunc(func(r *http.Request) (int, string) {
+			paths = append(paths, r.URL.Path)
+			if strings.HasPrefix(r.URL.Path, "/apidata/catalog/distribution/dataset/") {
+				return tt.status, tt.body
+			}
+			return tt.datasetStatus, datasetResponse
+		}).c))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		d := Dataset{About: "https://datos.gob.es/catalogo/l01280796-calidad-del-aire"}
+		err = d.LoadDistributions(context.Background(), c)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%s: expected error", tt.name)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.name, err)
+			continue
+		}
+
+		if len(d.Distribution) != tt.expected {
+			t.Errorf("%s: wrong number of distributions, expected: %d, got: %d", tt.name, tt.expected, len(d.Distribution))
+		}
+
+		if tt.expected > 0 && d.Distribution[0].AccessURL != "https://example.com/aire.csv" {
+			t.Errorf("%s: wrong distributions: %+v", tt.name, d.Distribution)
+		}
+
+		if len(paths) != 2 || paths[1] != "/apidata/catalog/dataset/l01280796-calidad-del-aire" {
+			t.Errorf("%s: wrong requests: %v", tt.name, paths)
+		}
+	}
+}
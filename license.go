@@ -0,0 +1,131 @@
+package datos
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// License is the link to the license of a dataset. The most common open
+// licenses of the portal are recognized, even if they're linked in
+// different ways, so they can be identified and named.
+type License string
+
+// licenseInfo is a known license with the links it can be recognized by,
+// without scheme, www. prefix or trailing slash.
+type licenseInfo struct {
+	id    string
+	name  string
+	links []string
+}
+
+var licenses = []licenseInfo{
+	{"CC-BY-4.0", "Creative Commons Attribution 4.0", []string{
+		"creativecommons.org/licenses/by/4.0",
+		"publications.europa.eu/resource/authority/licence/cc_by_4_0",
+	}},
+	{"CC-BY-3.0", "Creative Commons Attribution 3.0", []string{
+		"creativecommons.org/licenses/by/3.0",
+		"creativecommons.org/licenses/by/3.0/es",
+	}},
+	{"CC-BY-SA-4.0", "Creative Commons Attribution-ShareAlike 4.0", []string{
+		"creativecommons.org/licenses/by-sa/4.0",
+		"publications.europa.eu/resource/authority/licence/cc_bysa_4_0",
+	}},
+	{"CC-BY-SA-3.0", "Creative Commons Attribution-ShareAlike 3.0", []string{
+		"creativecommons.org/licenses/by-sa/3.0",
+		"creativecommons.org/licenses/by-sa/3.0/es",
+	}},
+	{"CC0-1.0", "Creative Commons Zero 1.0", []string{
+		"creativecommons.org/publicdomain/zero/1.0",
+		"publications.europa.eu/resource/authority/licence/cc0",
+	}},
+	{"ODbL-1.0", "Open Data Commons Open Database License 1.0", []string{
+		"opendatacommons.org/licenses/odbl",
+		"opendatacommons.org/licenses/odbl/1.0",
+		"publications.europa.eu/resource/authority/licence/odc_odbl",
+	}},
+	{"ODC-By-1.0", "Open Data Commons Attribution License 1.0", []string{
+		"opendatacommons.org/licenses/by",
+		"opendatacommons.org/licenses/by/1.0",
+		"publications.europa.eu/resource/authority/licence/odc_by",
+	}},
+	{"PDDL-1.0", "Open Data Commons Public Domain Dedication and License 1.0", []string{
+		"opendatacommons.org/licenses/pddl",
+		"opendatacommons.org/licenses/pddl/1.0",
+		"publications.europa.eu/resource/authority/licence/odc_pddl",
+	}},
+	{"datos.gob.es", "Aviso legal de datos.gob.es", []string{
+		"datos.gob.es/avisolegal",
+		"datos.gob.es/es/avisolegal",
+		"datos.gob.es/aviso-legal",
+		"datos.gob.es/es/aviso-legal",
+		"datos.gob.es/es/terminos-de-uso",
+	}},
+}
+
+// info returns the known license the link refers to. Links to the legal
+// code or the deeds in other languages of Creative Commons licenses are
+// recognized as well.
+func (l License) info() (licenseInfo, bool) {
+	link := strings.ToLower(strings.TrimSpace(string(l)))
+	if i := strings.Index(link, "://"); i >= 0 {
+		link = link[i+3:]
+	}
+	link = strings.TrimPrefix(link, "www.")
+	link = strings.TrimRight(link, "/")
+
+	if i := strings.LastIndex(link, "/"); i >= 0 {
+		last := link[i+1:]
+		if strings.HasPrefix(last, "legalcode") || strings.HasPrefix(last, "deed") {
+			link = link[:i]
+		}
+	}
+
+	for _, info := range licenses {
+		for _, l := range info.links {
+			if link == l {
+				return info, true
+			}
+		}
+	}
+	return licenseInfo{}, false
+}
+
+// Known reports whether the license is one of the recognized ones.
+func (l License) Known() bool {
+	_, ok := l.info()
+	return ok
+}
+
+// ID returns the identifier of a known license, its SPDX identifier if it
+// has one, or an empty string if it's not known.
+func (l License) ID() string {
+	info, _ := l.info()
+	return info.id
+}
+
+// Name returns the name of a known license or the link to it if it's not
+// known.
+func (l License) Name() string {
+	if info, ok := l.info(); ok {
+		return info.name
+	}
+	return string(l)
+}
+
+// UnmarshalJSON decodes a license, which can be a link or an object with
+// the link in the _about key.
+func (l *License) UnmarshalJSON(b []byte) error {
+	var val interface{}
+	if err := json.Unmarshal(b, &val); err != nil {
+		return err
+	}
+
+	str, err := stringValue(val)
+	if err != nil {
+		return err
+	}
+
+	*l = License(str)
+	return nil
+}
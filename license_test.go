@@ -0,0 +1,52 @@
+package datos
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLicense(t *testing.T) {
+	cases := []struct {
+		license License
+		id      string
+		name    string
+	}{
+		{"https://creativecommons.org/licenses/by/4.0/", "CC-BY-4.0", "Creative Commons Attribution 4.0"},
+		{"http://creativecommons.org/licenses/by/4.0/legalcode.es", "CC-BY-4.0", "Creative Commons Attribution 4.0"},
+		{"http://creativecommons.org/licenses/by/3.0/es/deed.es", "CC-BY-3.0", "Creative Commons Attribution 3.0"},
+		{"http://publications.europa.eu/resource/authority/licence/CC_BY_4_0", "CC-BY-4.0", "Creative Commons Attribution 4.0"},
+		{"https://opendatacommons.org/licenses/odbl/1.0/", "ODbL-1.0", "Open Data Commons Open Database License 1.0"},
+		{"http://www.datos.gob.es/avisolegal", "datos.gob.es", "Aviso legal de datos.gob.es"},
+		{"https://example.com/licencia", "", "https://example.com/licencia"},
+		{"", "", ""},
+	}
+
+	for _, c := range cases {
+		if id := c.license.ID(); id != c.id {
+			t.Errorf("wrong id of %q, expected: %q, got: %q", c.license, c.id, id)
+		}
+
+		if name := c.license.Name(); name != c.name {
+			t.Errorf("wrong name of %q, expected: %q, got: %q", c.license, c.name, name)
+		}
+
+		if known := c.license.Known(); known != (c.id != "") {
+			t.Errorf("wrong known of %q: %v", c.license, known)
+		}
+	}
+}
+
+func TestLicenseUnmarshalJSON(t *testing.T) {
+	var d Dataset
+	if err := json.Unmarshal([]byte(`{"license":{"_about":"http://creativecommons.org/licenses/by/4.0/"}}`), &d); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if d.License.ID() != "CC-BY-4.0" {
+		t.Errorf("wrong license: %q", d.License)
+	}
+
+	if err := json.Unmarshal([]byte(`{"license":12}`), &d); err == nil {
+		t.Errorf("expected error decoding number")
+	}
+}
@@ -0,0 +1,66 @@
+package datos
+
+import "fmt"
+
+// LogLevel is the severity of a log message.
+type LogLevel byte
+
+const (
+	// LevelDebug is used for the details of every request.
+	LevelDebug LogLevel = iota
+	// LevelInfo is used for notable events, like retries.
+	LevelInfo
+	// LevelWarn is used for problems that don't make a call fail, like
+	// items that could not be decoded.
+	LevelWarn
+	// LevelError is used for problems that make a call fail.
+	LevelError
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return fmt.Sprintf("LogLevel(%d)", byte(l))
+	}
+}
+
+// Fields are the structured data of a log message, such as the URL of a
+// request.
+type Fields map[string]interface{}
+
+// Logger receives the diagnostics of the client. It must be safe for
+// concurrent use.
+type Logger interface {
+	Log(level LogLevel, msg string, fields Fields)
+}
+
+// LoggerFunc is a function implementing the Logger interface.
+type LoggerFunc func(level LogLevel, msg string, fields Fields)
+
+// Log implements the Logger interface.
+func (f LoggerFunc) Log(level LogLevel, msg string, fields Fields) {
+	f(level, msg, fields)
+}
+
+// WithLogger makes the client send its diagnostics to the given logger. By
+// default, the client doesn't log anything.
+func WithLogger(l Logger) Option {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
+
+// log sends the message to the logger of the client, if any.
+func (c *Client) log(level LogLevel, msg string, fields Fields) {
+	if c.logger != nil {
+		c.logger.Log(level, msg, fields)
+	}
+}
@@ -0,0 +1,38 @@
+package datos
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestWithLogger(t *testing.T) {
+	var mut sync.Mutex
+	var levels []LogLevel
+	var fields []Fields
+	logger := LoggerFunc(func(level LogLevel, msg string, f Fields) {
+		mut.Lock()
+		levels = append(levels, level)
+		fields = append(fields, f)
+		mut.Unlock()
+	})
+
+	c := newTestClient(http.StatusOK, `{"result":{"items":[{"_about":"a"},{"_about":1}]}}`)
+	WithLogger(logger)(c)
+	if _, err := c.Publishers(context.Background(), Params{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(levels) != 2 || levels[0] != LevelDebug || levels[1] != LevelWarn {
+		t.Fatalf("wrong log messages: %v %v", levels, fields)
+	}
+
+	if fields[0]["url"] != defaultBaseURL+"/catalog/publisher?" || fields[0]["status"] != http.StatusOK {
+		t.Errorf("wrong request fields: %v", fields[0])
+	}
+
+	if fields[1]["index"] != 1 {
+		t.Errorf("wrong decode warning fields: %v", fields[1])
+	}
+}
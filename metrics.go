@@ -0,0 +1,208 @@
+package datos
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// durationBuckets are the upper bounds in seconds of the buckets of the
+// request duration histogram.
+var durationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Metrics collects metrics of the requests made by clients: the number of
+// requests and errors, their latency and the bytes received, by endpoint.
+// Requests to download distributions have the "download" endpoint. It's
+// an http.Handler serving the metrics in the Prometheus text format, so
+// they can be scraped along with the ones of the program:
+//
+//	metrics := datos.NewMetrics()
+//	client, err := datos.NewClient(datos.WithMetrics(metrics))
+//	http.Handle("/metrics/datos", metrics)
+//
+// It's safe for concurrent use.
+type Metrics struct {
+	mut       sync.Mutex
+	endpoints map[string]*endpointMetrics
+}
+
+type endpointMetrics struct {
+	// requests by status code, or "error" if there was no response.
+	requests map[string]int64
+	errors   int64
+	bytes    int64
+	// buckets has the count of every duration bucket plus the +Inf one.
+	buckets []int64
+	sum     float64
+}
+
+// NewMetrics returns an empty collection of metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{endpoints: make(map[string]*endpointMetrics)}
+}
+
+// WithMetrics makes the client record the metrics of its requests in m.
+// The same metrics can be shared by several clients.
+func WithMetrics(m *Metrics) Option {
+	return func(c *Client) {
+		WithHooks(Hooks{
+			OnResponse: func(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+				endpoint := endpointOf(c.baseURL(), req.URL.String())
+				m.observe(endpoint, resp, err, elapsed)
+				if resp != nil {
+					resp.Body = &countingBody{resp.Body, m, endpoint}
+				}
+			},
+		})(c)
+	}
+}
+
+// endpointFilters are the path segments of the API that filter datasets
+// or distributions, which are kept in the endpoint of a request.
+var endpointFilters = map[string]bool{
+	"title": true, "publisher": true, "theme": true, "format": true,
+	"keyword": true, "spatial": true, "modified": true, "issued": true,
+	"dataset": true,
+}
+
+// endpointOf returns the endpoint of the API of the given URL without any
+// identifiers, so the number of endpoints is bounded, e.g.
+// /catalog/dataset/theme for /catalog/dataset/theme/salud.
+func endpointOf(base, url string) string {
+	if !strings.HasPrefix(url, base+"/") {
+		return "download"
+	}
+
+	path := strings.SplitN(url[len(base):], "?", 2)[0]
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	endpoint := parts
+	if len(parts) > 2 {
+		endpoint = parts[:2]
+		if endpointFilters[parts[2]] {
+			endpoint = parts[:3]
+		}
+	}
+	return "/" + strings.Join(endpoint, "/")
+}
+
+func (m *Metrics) endpoint(name string) *endpointMetrics {
+	e, ok := m.endpoints[name]
+	if !ok {
+		e = &endpointMetrics{
+			requests: make(map[string]int64),
+			buckets:  make([]int64, len(durationBuckets)+1),
+		}
+		m.endpoints[name] = e
+	}
+	return e
+}
+
+func (m *Metrics) observe(endpoint string, resp *http.Response, err error, elapsed time.Duration) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	e := m.endpoint(endpoint)
+	code := "error"
+	if resp != nil {
+		code = fmt.Sprint(resp.StatusCode)
+	}
+	e.requests[code]++
+
+	if err != nil || resp.StatusCode >= 400 {
+		e.errors++
+	}
+
+	secs := elapsed.Seconds()
+	e.sum += secs
+	i := sort.SearchFloat64s(durationBuckets, secs)
+	e.buckets[i]++
+}
+
+func (m *Metrics) addBytes(endpoint string, n int) {
+	m.mut.Lock()
+	m.endpoint(endpoint).bytes += int64(n)
+	m.mut.Unlock()
+}
+
+// countingBody counts the bytes read from a response body.
+type countingBody struct {
+	io.ReadCloser
+	m        *Metrics
+	endpoint string
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.m.addBytes(b.endpoint, n)
+	}
+	return n, err
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_ = m.Write(w)
+}
+
+// Write writes the metrics in the Prometheus text format.
+func (m *Metrics) Write(w io.Writer) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	names := make([]string, 0, len(m.endpoints))
+	for name := range m.endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("# HELP datos_requests_total Requests made by the client.\n")
+	b.WriteString("# TYPE datos_requests_total counter\n")
+	for _, name := range names {
+		e := m.endpoints[name]
+		codes := make([]string, 0, len(e.requests))
+		for code := range e.requests {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+
+		for _, code := range codes {
+			fmt.Fprintf(&b, "datos_requests_total{endpoint=%q,code=%q} %d\n", name, code, e.requests[code])
+		}
+	}
+
+	b.WriteString("# HELP datos_request_errors_total Requests that failed or got an error status.\n")
+	b.WriteString("# TYPE datos_request_errors_total counter\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "datos_request_errors_total{endpoint=%q} %d\n", name, m.endpoints[name].errors)
+	}
+
+	b.WriteString("# HELP datos_response_bytes_total Bytes read from the responses.\n")
+	b.WriteString("# TYPE datos_response_bytes_total counter\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "datos_response_bytes_total{endpoint=%q} %d\n", name, m.endpoints[name].bytes)
+	}
+
+	b.WriteString("# HELP datos_request_duration_seconds Time until the response headers were received.\n")
+	b.WriteString("# TYPE datos_request_duration_seconds histogram\n")
+	for _, name := range names {
+		e := m.endpoints[name]
+		var count int64
+		for i, le := range durationBuckets {
+			count += e.buckets[i]
+			fmt.Fprintf(&b, "datos_request_duration_seconds_bucket{endpoint=%q,le=\"%g\"} %d\n", name, le, count)
+		}
+		count += e.buckets[len(durationBuckets)]
+		fmt.Fprintf(&b, "datos_request_duration_seconds_bucket{endpoint=%q,le=\"+Inf\"} %d\n", name, count)
+		fmt.Fprintf(&b, "datos_request_duration_seconds_sum{endpoint=%q} %g\n", name, e.sum)
+		fmt.Fprintf(&b, "datos_request_duration_seconds_count{endpoint=%q} %d\n", name, count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,68 @@
+package datos
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEndpointOf(t *testing.T) {
+	cases := map[string]string{
+		defaultBaseURL + "/catalog/dataset?_page=1":                    "/catalog/dataset",
+		defaultBaseURL + "/catalog/dataset/a02002834-centros-de-salud": "/catalog/dataset",
+		defaultBaseURL + "/catalog/dataset/theme/salud?_pageSize=10":   "/catalog/dataset/theme",
+		defaultBaseURL + "/catalog/dataset/spatial/Autonomia/Aragon":   "/catalog/dataset/spatial",
+		defaultBaseURL + "/catalog/distribution/dataset/foo":           "/catalog/distribution/dataset",
+		"https://example.com/foo.csv":                                  "download",
+	}
+
+	for url, expected := range cases {
+		if got := endpointOf(defaultBaseURL, url); got != expected {
+			t.Errorf("endpointOf(%q): expected %q, got %q", url, expected, got)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics()
+	c, err := NewClient(WithMetrics(m), WithHTTPClient(newTestClientFunc(func(r *http.Request) (int, string) {
+		if strings.Contains(r.URL.Path, "missing") {
+			return http.StatusNotFound, ""
+		}
+		return http.StatusOK, `{"result":{"items":[]}}`
+	}).c))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ctx := context.Background()
+	c.DatasetsByTheme(ctx, "salud", Params{})
+	c.DatasetsByTheme(ctx, "turismo", Params{})
+	c.DatasetsByTheme(ctx, "missing", Params{})
+
+	var buf bytes.Buffer
+	if _, err := c.DownloadDistribution(ctx, Distribution{AccessURL: "https://example.com/foo.csv"}, &buf, DownloadOptions{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	out := rec.Body.String()
+
+	for _, line := range []string{
+		`datos_requests_total{endpoint="/catalog/dataset/theme",code="200"} 2`,
+		`datos_requests_total{endpoint="/catalog/dataset/theme",code="404"} 1`,
+		`datos_requests_total{endpoint="download",code="200"} 1`,
+		`datos_request_errors_total{endpoint="/catalog/dataset/theme"} 1`,
+		`datos_response_bytes_total{endpoint="download"} 23`,
+		`datos_request_duration_seconds_bucket{endpoint="/catalog/dataset/theme",le="+Inf"} 3`,
+		`datos_request_duration_seconds_count{endpoint="download"} 1`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("expected metrics to contain %q, got:\n%s", line, out)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+// Package mirror keeps a copy of the catalog in a SQLite database, with
+// a table for every kind of item and tables relating datasets to their
+// themes, spatials and keywords. It works with any SQLite driver for
+// database/sql, which must be registered by the caller.
+package mirror
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/erizocosmico/datos"
+)
+
+// pageSize is the page size used to page through the catalog.
+const pageSize = 50
+
+// timeFormat is the format of the dates stored in the database.
+const timeFormat = time.RFC3339
+
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS publishers (
+		about TEXT PRIMARY KEY,
+		notation TEXT NOT NULL,
+		label TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS themes (
+		about TEXT PRIMARY KEY,
+		id TEXT NOT NULL,
+		label TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS spatials (
+		about TEXT PRIMARY KEY,
+		label TEXT NOT NULL,
+		type TEXT NOT NULL,
+		country TEXT NOT NULL,
+		autonomy TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS datasets (
+		about TEXT PRIMARY KEY,
+		identifier TEXT NOT NULL,
+		title TEXT NOT NULL,
+		description TEXT NOT NULL,
+		publisher TEXT NOT NULL,
+		license TEXT NOT NULL,
+		issued TEXT NOT NULL,
+		modified TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS distributions (
+		dataset TEXT NOT NULL REFERENCES datasets(about) ON DELETE CASCADE,
+		access_url TEXT NOT NULL,
+		format TEXT NOT NULL,
+		byte_size INTEGER NOT NULL,
+		title TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS dataset_themes (
+		dataset TEXT NOT NULL REFERENCES datasets(about) ON DELETE CASCADE,
+		theme TEXT NOT NULL,
+		PRIMARY KEY (dataset, theme)
+	)`,
+	`CREATE TABLE IF NOT EXISTS dataset_spatials (
+		dataset TEXT NOT NULL REFERENCES datasets(about) ON DELETE CASCADE,
+		spatial TEXT NOT NULL,
+		PRIMARY KEY (dataset, spatial)
+	)`,
+	`CREATE TABLE IF NOT EXISTS dataset_keywords (
+		dataset TEXT NOT NULL REFERENCES datasets(about) ON DELETE CASCADE,
+		keyword TEXT NOT NULL,
+		PRIMARY KEY (dataset, keyword)
+	)`,
+	`CREATE INDEX IF NOT EXISTS distributions_dataset ON distributions (dataset)`,
+}
+
+// datasetTables are the tables with rows of a dataset, other than the
+// datasets table.
+var datasetTables = []string{"distributions", "dataset_themes", "dataset_spatials", "dataset_keywords"}
+
+// Stats of an update of the mirror.
+type Stats struct {
+	// Added is the number of new datasets.
+	Added int
+	// Updated is the number of datasets modified since the last update.
+	Updated int
+	// Unchanged is the number of datasets not modified since the last
+	// update, which are not written again.
+	Unchanged int
+	// Removed is the number of datasets no longer in the catalog.
+	Removed int
+}
+
+// Update creates the schema in the database if needed and copies the
+// catalog into it. Publishers, themes and spatials are always written
+// again, but only the datasets that are new or whose modified date changed
+// since the last update are, and the ones no longer in the catalog are
+// removed. The catalog can be a datos.Client or a datos.OfflineClient
+// reading a snapshot.
+func Update(ctx context.Context, db *sql.DB, catalog datos.Catalog) (Stats, error) {
+	var stats Stats
+	for _, q := range schema {
+		if _, err := db.ExecContext(ctx, q); err != nil {
+			return stats, fmt.Errorf("mirror: unable to create schema: %s", err)
+		}
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return stats, err
+	}
+	defer tx.Rollback()
+
+	if err := updateTaxonomies(ctx, tx, catalog); err != nil {
+		return stats, err
+	}
+
+	known, err := modifiedDates(ctx, tx)
+	if err != nil {
+		return stats, err
+	}
+
+	seen := make(map[string]bool)
+	params := datos.Params{PageSize: pageSize}
+	for {
+		datasets, err := catalog.Datasets(ctx, params)
+		if err != nil {
+			return stats, err
+		}
+
+		for _, d := range datasets {
+			seen[d.About] = true
+			modified, ok := known[d.About]
+			if ok && modified == formatTime(d.Modified) {
+				stats.Unchanged++
+				continue
+			}
+
+			if err := writeDataset(ctx, tx, d, ok); err != nil {
+				return stats, err
+			}
+
+			if ok {
+				stats.Updated++
+			} else {
+				stats.Added++
+			}
+		}
+
+		if len(datasets) < pageSize {
+			break
+		}
+		params.Page++
+	}
+
+	for about := range known {
+		if !seen[about] {
+			if err := deleteDataset(ctx, tx, about); err != nil {
+				return stats, err
+			}
+			stats.Removed++
+		}
+	}
+
+	return stats, tx.Commit()
+}
+
+func updateTaxonomies(ctx context.Context, tx *sql.Tx, catalog datos.Catalog) error {
+	params := datos.Params{PageSize: pageSize}
+	for {
+		publishers, err := catalog.Publishers(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range publishers {
+			if err := exec(ctx, tx,
+				"INSERT OR REPLACE INTO publishers (about, notation, label) VALUES (?, ?, ?)",
+				p.About, p.Notation, p.Label,
+			); err != nil {
+				return err
+			}
+		}
+
+		if len(publishers) < pageSize {
+			break
+		}
+		params.Page++
+	}
+
+	params.Page = 0
+	for {
+		themes, err := catalog.Themes(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		for _, t := range themes {
+			if err := exec(ctx, tx,
+				"INSERT OR REPLACE INTO themes (about, id, label) VALUES (?, ?, ?)",
+				t.About, t.ID(), t.Label(datos.Spanish),
+			); err != nil {
+				return err
+			}
+		}
+
+		if len(themes) < pageSize {
+			break
+		}
+		params.Page++
+	}
+
+	params.Page = 0
+	for {
+		spatials, err := catalog.Spatials(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range spatials {
+			var typ string
+			if t, ok := s.SpatialType(); ok {
+				typ = t.String()
+			}
+
+			if err := exec(ctx, tx,
+				"INSERT OR REPLACE INTO spatials (about, label, type, country, autonomy) VALUES (?, ?, ?, ?, ?)",
+				s.About, s.Label, typ, s.Country, s.Autonomy,
+			); err != nil {
+				return err
+			}
+		}
+
+		if len(spatials) < pageSize {
+			break
+		}
+		params.Page++
+	}
+
+	return nil
+}
+
+// modifiedDates returns the modified dates of the datasets in the database
+// by link.
+func modifiedDates(ctx context.Context, tx *sql.Tx) (map[string]string, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT about, modified FROM datasets")
+	if err != nil {
+		return nil, fmt.Errorf("mirror: unable to read datasets: %s", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]string)
+	for rows.Next() {
+		var about, modified string
+		if err := rows.Scan(&about, &modified); err != nil {
+			return nil, fmt.Errorf("mirror: unable to read datasets: %s", err)
+		}
+		result[about] = modified
+	}
+
+	return result, rows.Err()
+}
+
+func writeDataset(ctx context.Context, tx *sql.Tx, d datos.Dataset, exists bool) error {
+	if exists {
+		if err := deleteDataset(ctx, tx, d.About); err != nil {
+			return err
+		}
+	}
+
+	title := d.Title.Default()
+
+	if err := exec(ctx, tx,
+		`INSERT INTO datasets (about, identifier, title, description, publisher, license, issued, modified)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		d.About, d.Identifier, title, description(d), d.Publisher, string(d.License),
+		formatTime(d.Issued), formatTime(d.Modified),
+	); err != nil {
+		return err
+	}
+
+	for _, dist := range d.Distribution {
+		if err := exec(ctx, tx,
+			"INSERT INTO distributions (dataset, access_url, format, byte_size, title) VALUES (?, ?, ?, ?, ?)",
+			d.About, dist.AccessURL, dist.Format.Value, int64(dist.ByteSize), strings.Join(dist.Title, " / "),
+		); err != nil {
+			return err
+		}
+	}
+
+	relations := []struct {
+		table  string
+		values []string
+	}{
+		{"dataset_themes", d.Theme},
+		{"dataset_spatials", d.Spatial},
+		{"dataset_keywords", d.Keywords},
+	}
+
+	for _, r := range relations {
+		for _, v := range r.values {
+			if err := exec(ctx, tx,
+				fmt.Sprintf("INSERT OR IGNORE INTO %s VALUES (?, ?)", r.table),
+				d.About, v,
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// deleteDataset removes the dataset and all its rows. Rows are removed
+// explicitly because SQLite does not enforce foreign keys by default.
+func deleteDataset(ctx context.Context, tx *sql.Tx, about string) error {
+	for _, table := range append(datasetTables, "datasets") {
+		column := "dataset"
+		if table == "datasets" {
+			column = "about"
+		}
+
+		if err := exec(ctx, tx, fmt.Sprintf("DELETE FROM %s WHERE %s = ?", table, column), about); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// description returns the description of the dataset in Spanish, or
+// another one if there is none.
+func description(d datos.Dataset) string {
+	return d.Description.Default()
+}
+
+func formatTime(d datos.Datetime) string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.UTC().Format(timeFormat)
+}
+
+func exec(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) error {
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("mirror: unable to write to the database: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package mirror
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/erizocosmico/datos"
+)
+
+func TestUpdate(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	catalog := newCatalog(t, map[string]string{
+		"a": "dom, 18 nov 2012 23:00:00 GMT+0000",
+		"b": "dom, 18 nov 2012 23:00:00 GMT+0000",
+		"c": "dom, 18 nov 2012 23:00:00 GMT+0000",
+	})
+
+	stats, err := Update(ctx, db, catalog)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if stats != (Stats{Added: 3}) {
+		t.Errorf("wrong stats: %+v", stats)
+	}
+
+	if n := testDB.count("INSERT INTO distributions"); n != 3 {
+		t.Errorf("wrong number of distributions written, expected: 3, got: %d", n)
+	}
+
+	catalog = newCatalog(t, map[string]string{
+		"a": "dom, 18 nov 2012 23:00:00 GMT+0000",
+		"b": "lun, 19 nov 2012 23:00:00 GMT+0000",
+		"d": "dom, 18 nov 2012 23:00:00 GMT+0000",
+	})
+
+	stats, err = Update(ctx, db, catalog)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if stats != (Stats{Added: 1, Updated: 1, Unchanged: 1, Removed: 1}) {
+		t.Errorf("wrong stats: %+v", stats)
+	}
+
+	if n := testDB.count("INSERT INTO distributions"); n != 5 {
+		t.Errorf("expected only changed datasets to be written, got %d distributions written", n)
+	}
+
+	if _, ok := testDB.datasets["http://datos.gob.es/catalogo/c"]; ok {
+		t.Errorf("expected removed dataset to be deleted")
+	}
+}
+
+func newCatalog(t *testing.T, modified map[string]string) datos.Catalog {
+	t.Helper()
+	lines := []string{
+		`{"type":"publisher","item":{"_about":"http://datos.gob.es/recurso/sector-publico/org/Organismo/L01280796","notation":"L01280796","prefLabel":"Ayuntamiento de Madrid"}}`,
+		`{"type":"theme","item":{"_about":"http://datos.gob.es/kos/sector-publico/sector/salud","prefLabel":["Salud"]}}`,
+	}
+
+	for id, m := range modified {
+		lines = append(lines, fmt.Sprintf(
+			`{"type":"dataset","item":{"_about":"http://datos.gob.es/catalogo/%s","title":"%s",`+
+				`"theme":"http://datos.gob.es/kos/sector-publico/sector/salud","keyword":["a","b"],`+
+				`"modified":%q,"distribution":{"accessURL":"http://example.com/%s.csv"}}}`,
+			id, id, m, id,
+		))
+	}
+
+	c, err := datos.ReadSnapshot(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return c
+}
+
+// fakeDB is a database driver that keeps the modified dates of the
+// datasets written and the statements executed.
+type fakeDB struct {
+	datasets   map[string]string
+	statements []string
+}
+
+var testDB *fakeDB
+
+func init() {
+	sql.Register("fake", fakeDriver{})
+}
+
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	testDB = &fakeDB{datasets: make(map[string]string)}
+	db, err := sql.Open("fake", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return db
+}
+
+func (db *fakeDB) count(prefix string) int {
+	var n int
+	for _, s := range db.statements {
+		if strings.HasPrefix(s, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt(query), nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return fakeConn{}, nil }
+func (fakeConn) Commit() error                             { return nil }
+func (fakeConn) Rollback() error                           { return nil }
+
+type fakeStmt string
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	query := strings.TrimSpace(string(s))
+	testDB.statements = append(testDB.statements, query)
+	switch {
+	case strings.HasPrefix(query, "INSERT INTO datasets"):
+		testDB.datasets[args[0].(string)] = args[7].(string)
+	case strings.HasPrefix(query, "DELETE FROM datasets"):
+		delete(testDB.datasets, args[0].(string))
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	rows := &fakeRows{}
+	for about, modified := range testDB.datasets {
+		rows.values = append(rows.values, []driver.Value{about, modified})
+	}
+	return rows, nil
+}
+
+type fakeRows struct {
+	values [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return []string{"about", "modified"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
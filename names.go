@@ -0,0 +1,108 @@
+package datos
+
+import (
+	"strings"
+	"unicode"
+)
+
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "ä", "a", "â", "a",
+	"é", "e", "è", "e", "ë", "e", "ê", "e",
+	"í", "i", "ì", "i", "ï", "i", "î", "i",
+	"ó", "o", "ò", "o", "ö", "o", "ô", "o",
+	"ú", "u", "ù", "u", "ü", "u", "û", "u",
+	"ñ", "n", "ç", "c", "·", "",
+	"Á", "A", "À", "A", "Ä", "A", "Â", "A",
+	"É", "E", "È", "E", "Ë", "E", "Ê", "E",
+	"Í", "I", "Ì", "I", "Ï", "I", "Î", "I",
+	"Ó", "O", "Ò", "O", "Ö", "O", "Ô", "O",
+	"Ú", "U", "Ù", "U", "Ü", "U", "Û", "U",
+	"Ñ", "N", "Ç", "C",
+)
+
+// StripAccents removes the accents and diacritics used in spanish and the
+// other co-official languages from the given text, keeping its case. The
+// catalan middle dot is removed too, so "col·lecció" becomes "colleccio".
+func StripAccents(s string) string {
+	return accents.Replace(s)
+}
+
+// articles removed from the beginning of place names, in spanish and the
+// co-official languages.
+var articles = []string{"la", "el", "las", "los", "a", "o", "as", "os", "l", "les", "es", "sa"}
+
+// prefixes removed from the beginning of place names.
+var prefixes = []string{
+	"provincia de", "comunidad autonoma de", "comunidad de", "comunidad foral de",
+	"comunitat", "region de", "principado de", "ciudad autonoma de", "islas", "illes",
+}
+
+// placeAliases maps alternative names of places, once normalized, to the
+// normalized name used by the API.
+var placeAliases = map[string]string{
+	"gerona":              "girona",
+	"lerida":              "lleida",
+	"orense":              "ourense",
+	"guipuzcoa":           "gipuzkoa",
+	"vizcaya":             "bizkaia",
+	"alava":               "arabaalava",
+	"araba":               "arabaalava",
+	"alavaaraba":          "arabaalava",
+	"baleares":            "balears",
+	"balearis":            "balears",
+	"castello":            "castellon",
+	"castellondelaplana":  "castellon",
+	"alacant":             "alicante",
+	"catalunya":           "cataluna",
+	"euskadi":             "paisvasco",
+	"nafarroa":            "navarra",
+	"valenciana":          "comunitatvalenciana",
+	"comunidadvalenciana": "comunitatvalenciana",
+	"tenerife":            "santacruzdetenerife",
+	"spain":               "espana",
+}
+
+// normalizeText lowercases the text, removes its accents and replaces
+// anything that is not a letter or a digit with a single space.
+func normalizeText(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(StripAccents(text)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// normalizePlace normalizes the name of a place so different spellings of
+// the same place, like "A Coruña", "La Coruna" or "Coruña", are equal.
+func normalizePlace(name string) string {
+	if strings.Contains(name, "://") {
+		name = lastSegment(name)
+	}
+
+	name = normalizeText(name)
+	for _, p := range prefixes {
+		name = strings.TrimPrefix(name, p+" ")
+	}
+
+	words := strings.Fields(name)
+	if len(words) > 1 {
+		for _, a := range articles {
+			if words[0] == a {
+				words = words[1:]
+				break
+			}
+		}
+	}
+
+	name = strings.Join(words, "")
+	if alias, ok := placeAliases[name]; ok {
+		return alias
+	}
+
+	return name
+}
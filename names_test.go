@@ -0,0 +1,63 @@
+package datos
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestNormalizePlace(t *testing.T) {
+	testCases := []struct {
+		names    []string
+		expected string
+	}{
+		{[]string{"A Coruña", "La Coruna", "Coruña", "A-Coruna", "provincia de A Coruña"}, "coruna"},
+		{[]string{"Girona", "Gerona"}, "girona"},
+		{[]string{"Lleida", "Lérida"}, "lleida"},
+		{[]string{"Araba/Álava", "Álava", "Araba"}, "arabaalava"},
+		{[]string{"Islas Baleares", "Illes Balears", "Baleares"}, "balears"},
+		{[]string{"Castilla-La Mancha", "Castilla La Mancha"}, "castillalamancha"},
+		{[]string{territorio + "Provincia/Girona", "girona"}, "girona"},
+	}
+
+	for _, tt := range testCases {
+		for _, name := range tt.names {
+			if got := normalizePlace(name); got != tt.expected {
+				t.Errorf("normalizePlace(%q): expected: %q, got: %q", name, tt.expected, got)
+			}
+		}
+	}
+}
+
+func TestStripAccents(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected string
+	}{
+		{"València", "Valencia"},
+		{"A CORUÑA", "A CORUNA"},
+		{"Col·legi d'Educació", "Collegi d'Educacio"},
+		{"Pingüino", "Pinguino"},
+		{"Girona", "Girona"},
+	}
+
+	for _, tt := range testCases {
+		if result := StripAccents(tt.text); result != tt.expected {
+			t.Errorf("wrong result for %q, expected: %s, got: %s", tt.text, tt.expected, result)
+		}
+	}
+}
+
+func TestResolveSpatialNormalized(t *testing.T) {
+	c := newTestClient(http.StatusOK, spatialsResponse)
+	for _, name := range []string{"Gerona", "provincia de Girona", "GIRONA"} {
+		s, err := c.ResolveSpatial(context.Background(), Province, name)
+		if err != nil {
+			t.Fatalf("unexpected error resolving %q: %s", name, err)
+		}
+
+		if s.ID() != "Girona" {
+			t.Errorf("wrong spatial for %q, expected: Girona, got: %s", name, s.ID())
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package datos
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Catalog is the interface to query the catalog implemented by both Client
+// and OfflineClient, so tools can work with or without network access.
+type Catalog interface {
+	Publishers(ctx context.Context, params Params) ([]Publisher, error)
+	Spatials(ctx context.Context, params Params) ([]Spatial, error)
+	Themes(ctx context.Context, params Params) ([]Theme, error)
+	Datasets(ctx context.Context, params Params) ([]Dataset, error)
+	Dataset(ctx context.Context, id string, params Params) (Dataset, error)
+	DatasetsByTitle(ctx context.Context, title string, params Params) ([]Dataset, error)
+	DatasetsByPublisher(ctx context.Context, publisherID string, params Params) ([]Dataset, error)
+	DatasetsByTheme(ctx context.Context, themeID string, params Params) ([]Dataset, error)
+	DatasetsByFormat(ctx context.Context, format string, params Params) ([]Dataset, error)
+	DatasetsByKeyword(ctx context.Context, keyword string, params Params) ([]Dataset, error)
+	DatasetsBySpatial(ctx context.Context, typ SpatialType, spatial string, params Params) ([]Dataset, error)
+	DatasetsModifiedBetween(ctx context.Context, from, to time.Time, params Params) ([]Dataset, error)
+	DatasetsIssuedBetween(ctx context.Context, from, to time.Time, params Params) ([]Dataset, error)
+	Distributions(ctx context.Context, params Params) ([]Distribution, error)
+	DistributionsByDataset(ctx context.Context, datasetID string, params Params) ([]Distribution, error)
+	DistributionsByFormat(ctx context.Context, format string, params Params) ([]Distribution, error)
+}
+
+var (
+	_ Catalog = (*Client)(nil)
+	_ Catalog = (*OfflineClient)(nil)
+)
+
+// defaultPageSize is the page size used by the API when none is given.
+const defaultPageSize = 10
+
+// OfflineClient queries a snapshot of the catalog instead of the API. It
+// has the same query methods as Client and filters the datasets the same
+// way the API does. Results are paged according to the params and sorted
+// by issued or modified date or by title, other sort fields are ignored.
+type OfflineClient struct {
+	header     SnapshotHeader
+	publishers []Publisher
+	themes     []Theme
+	spatials   []Spatial
+	datasets   []Dataset
+	// index of every dataset in datasets by its key.
+	index map[string]int
+}
+
+// Header returns the header of the snapshot. Snapshots written before the
+// format was versioned have an empty header of version 0.
+func (c *OfflineClient) Header() SnapshotHeader {
+	return c.header
+}
+
+// Import adds the given datasets, e.g. the ones read from another catalog
+// with ReadDCAT, so they can be queried along with the ones of the
+// snapshot. Datasets with the same key as an existing one replace it, so
+// the same dataset coming from different sources is only added once.
+func (c *OfflineClient) Import(datasets ...Dataset) {
+	if c.index == nil {
+		c.index = make(map[string]int)
+	}
+
+	for _, d := range datasets {
+		key := d.Key()
+		if i, ok := c.index[key]; ok && key != "" {
+			c.datasets[i] = d
+		} else {
+			c.index[key] = len(c.datasets)
+			c.datasets = append(c.datasets, d)
+		}
+	}
+}
+
+// Publishers lists all data publishers.
+func (c *OfflineClient) Publishers(ctx context.Context, params Params) ([]Publisher, error) {
+	start, end := pageBounds(ctx, len(c.publishers), params)
+	return c.publishers[start:end], nil
+}
+
+// Spatials lists all spatials.
+func (c *OfflineClient) Spatials(ctx context.Context, params Params) ([]Spatial, error) {
+	start, end := pageBounds(ctx, len(c.spatials), params)
+	return c.spatials[start:end], nil
+}
+
+// Themes lists all themes.
+func (c *OfflineClient) Themes(ctx context.Context, params Params) ([]Theme, error) {
+	start, end := pageBounds(ctx, len(c.themes), params)
+	return c.themes[start:end], nil
+}
+
+// Datasets returns all datasets.
+func (c *OfflineClient) Datasets(ctx context.Context, params Params) ([]Dataset, error) {
+	return c.query(ctx, new(DatasetQuery), params), nil
+}
+
+// Dataset returns the dataset with the given ID, which can be in any of the
+// forms accepted by CanonicalID.
+func (c *OfflineClient) Dataset(ctx context.Context, id string, params Params) (Dataset, error) {
+	key := CanonicalID(id)
+	if i, ok := c.index[key]; ok && key != "" {
+		return c.datasets[i], nil
+	}
+
+	for _, d := range c.datasets {
+		if d.Identifier == id || CanonicalID(d.Identifier) == key {
+			return d, nil
+		}
+	}
+
+	return Dataset{}, newError(ErrNotFound, nil, "datos: dataset not found with id %q", id)
+}
+
+// DatasetsByTitle returns the datasets matching the given title.
+func (c *OfflineClient) DatasetsByTitle(ctx context.Context, title string, params Params) ([]Dataset, error) {
+	return c.query(ctx, &DatasetQuery{title: title}, params), nil
+}
+
+// DatasetsByPublisher returns all datasets of the given publisher.
+func (c *OfflineClient) DatasetsByPublisher(ctx context.Context, publisherID string, params Params) ([]Dataset, error) {
+	return c.query(ctx, &DatasetQuery{publisher: publisherID}, params), nil
+}
+
+// DatasetsByTheme returns all datasets of the given theme.
+func (c *OfflineClient) DatasetsByTheme(ctx context.Context, themeID string, params Params) ([]Dataset, error) {
+	return c.query(ctx, &DatasetQuery{theme: themeID}, params), nil
+}
+
+// DatasetsByFormat returns all datasets with a distribution in the given
+// format.
+func (c *OfflineClient) DatasetsByFormat(ctx context.Context, format string, params Params) ([]Dataset, error) {
+	return c.query(ctx, &DatasetQuery{format: format}, params), nil
+}
+
+// DatasetsByKeyword returns all datasets with the given keyword.
+func (c *OfflineClient) DatasetsByKeyword(ctx context.Context, keyword string, params Params) ([]Dataset, error) {
+	return c.query(ctx, &DatasetQuery{keyword: keyword}, params), nil
+}
+
+// DatasetsBySpatial returns all datasets of the given spatial, which is
+// resolved the same way as in Client.DatasetsBySpatial.
+func (c *OfflineClient) DatasetsBySpatial(ctx context.Context, typ SpatialType, spatial string, params Params) ([]Dataset, error) {
+	var spatials []Spatial
+	for _, s := range c.spatials {
+		if t, ok := s.SpatialType(); ok && t == typ {
+			spatials = append(spatials, s)
+		}
+	}
+
+	s, err := resolveSpatial(spatials, typ, spatial)
+	if err == nil {
+		spatial = s.ID()
+	} else if errors.Is(err, ErrNotFound) && len(spatials) > 0 {
+		return nil, err
+	}
+
+	return c.query(ctx, &DatasetQuery{spatialType: typ, spatial: spatial}, params), nil
+}
+
+// DatasetsModifiedBetween returns the datasets modified between the given
+// date range.
+func (c *OfflineClient) DatasetsModifiedBetween(ctx context.Context, from, to time.Time, params Params) ([]Dataset, error) {
+	return c.query(ctx, &DatasetQuery{after: from, before: to}, params), nil
+}
+
+// DatasetsIssuedBetween returns the datasets issued between the given date
+// range.
+func (c *OfflineClient) DatasetsIssuedBetween(ctx context.Context, from, to time.Time, params Params) ([]Dataset, error) {
+	return c.query(ctx, &DatasetQuery{issuedAfter: from, issuedBefore: to}, params), nil
+}
+
+// Distributions returns all distributions.
+func (c *OfflineClient) Distributions(ctx context.Context, params Params) ([]Distribution, error) {
+	return c.distributions(ctx, c.datasets, "", params), nil
+}
+
+// DistributionsByDataset returns all distributions of a dataset.
+func (c *OfflineClient) DistributionsByDataset(ctx context.Context, datasetID string, params Params) ([]Distribution, error) {
+	d, err := c.Dataset(ctx, datasetID, params)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+
+	return c.distributions(ctx, []Dataset{d}, "", params), nil
+}
+
+// DistributionsByFormat returns all distributions with the given format.
+func (c *OfflineClient) DistributionsByFormat(ctx context.Context, format string, params Params) ([]Distribution, error) {
+	return c.distributions(ctx, c.datasets, format, params), nil
+}
+
+// query returns the page of the datasets matching q.
+func (c *OfflineClient) query(ctx context.Context, q *DatasetQuery, params Params) []Dataset {
+	var result []Dataset
+	for _, d := range c.datasets {
+		if q.matches(d) {
+			result = append(result, d)
+		}
+	}
+
+	sortDatasets(result, params.Sort)
+	start, end := pageBounds(ctx, len(result), params)
+	return result[start:end]
+}
+
+// distributions returns the page of the distributions of the given
+// datasets in the given format, or in any format if it's empty.
+func (c *OfflineClient) distributions(ctx context.Context, datasets []Dataset, format string, params Params) []Distribution {
+	var result []Distribution
+	for _, d := range datasets {
+		for _, dist := range d.Distribution {
+			if format == "" || hasFormat([]Distribution{dist}, format) {
+				result = append(result, dist)
+			}
+		}
+	}
+
+	start, end := pageBounds(ctx, len(result), params)
+	return result[start:end]
+}
+
+// sortDatasets sorts the datasets by the given field, which can be
+// prefixed with - to sort them in descending order.
+func sortDatasets(datasets []Dataset, field string) {
+	desc := strings.HasPrefix(field, "-")
+	var less func(a, b Dataset) bool
+	switch strings.TrimPrefix(field, "-") {
+	case "issued":
+		less = func(a, b Dataset) bool { return a.Issued.Before(b.Issued.Time) }
+	case "modified":
+		less = func(a, b Dataset) bool { return a.Modified.Before(b.Modified.Time) }
+	case "title":
+		less = func(a, b Dataset) bool { return a.Title.Default() < b.Title.Default() }
+	default:
+		return
+	}
+
+	sort.SliceStable(datasets, func(i, j int) bool {
+		if desc {
+			return less(datasets[j], datasets[i])
+		}
+		return less(datasets[i], datasets[j])
+	})
+}
+
+// pageBounds returns the bounds of the page of the given params in a list
+// of n items and stores the page in the context, like the client does with
+// the paging information of the API.
+func pageBounds(ctx context.Context, n int, params Params) (start, end int) {
+	size := int(params.PageSize)
+	if size == 0 {
+		size = defaultPageSize
+	}
+
+	start = int(params.Page) * size
+	if start > n {
+		start = n
+	}
+
+	end = start + size
+	if end > n {
+		end = n
+	}
+
+	setPage(ctx, Page{
+		Number: params.Page,
+		Size:   uint(size),
+		Items:  end - start,
+		Total:  n,
+	})
+	return start, end
+}
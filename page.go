@@ -0,0 +1,101 @@
+package datos
+
+import "context"
+
+// Page is the paging information of a response of the API.
+type Page struct {
+	// Number of the page, starting at 0.
+	Number uint
+	// Size is the maximum number of items of the page.
+	Size uint
+	// Items is the number of items in the page, including the ones that
+	// could not be decoded.
+	Items int
+	// Total is the number of items of all pages, or -1 if it's unknown.
+	Total int
+	// First, Next and Prev are the links to the first, next and previous
+	// pages, if there are any.
+	First, Next, Prev string
+}
+
+// HasNext reports whether there are more pages after this one. When the
+// response has no link to the next page nor the total of items, a full
+// page is taken as a sign that there may be more.
+func (p Page) HasNext() bool {
+	switch {
+	case p.Next != "":
+		return true
+	case p.Total >= 0:
+		return int(p.Number+1)*int(p.Size) < p.Total
+	default:
+		return p.Size > 0 && p.Items >= int(p.Size)
+	}
+}
+
+// Pages returns the number of pages, or -1 if the total of items is
+// unknown.
+func (p Page) Pages() int {
+	if p.Total < 0 || p.Size == 0 {
+		return -1
+	}
+	return (p.Total + int(p.Size) - 1) / int(p.Size)
+}
+
+type pageKey struct{}
+
+// CapturePage returns a context that makes the client store in p the
+// paging information of the requests made with it, so callers can show
+// progress or know when to stop. If several requests are made with the
+// context, p has the page of the last one.
+func CapturePage(ctx context.Context, p *Page) context.Context {
+	return context.WithValue(ctx, pageKey{}, p)
+}
+
+// setPage stores the page in the page captured in the context, if any.
+func setPage(ctx context.Context, page Page) {
+	if p, ok := ctx.Value(pageKey{}).(*Page); ok {
+		*p = page
+	}
+}
+
+// pageInfo is the paging information of a response of the API.
+type pageInfo struct {
+	First        string `json:"first"`
+	Next         string `json:"next"`
+	Prev         string `json:"prev"`
+	Page         *uint  `json:"page"`
+	ItemsPerPage *uint  `json:"itemsPerPage"`
+	TotalItems   *int   `json:"totalItems"`
+}
+
+// page returns the page of a response requested with the given params. The
+// params are used when the response has no paging information.
+func (i pageInfo) page(params Params, items int) Page {
+	p := Page{
+		Number: params.Page,
+		Size:   params.PageSize,
+		Items:  items,
+		Total:  -1,
+		First:  i.First,
+		Next:   i.Next,
+		Prev:   i.Prev,
+	}
+
+	if p.Size == 0 {
+		p.Size = defaultPageSize
+	}
+
+	if i.Page != nil {
+		p.Number = *i.Page
+	}
+
+	if i.ItemsPerPage != nil {
+		p.Size = *i.ItemsPerPage
+	}
+
+	if i.TotalItems != nil {
+		p.Total = *i.TotalItems
+	}
+
+	return p
+}
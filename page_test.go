@@ -0,0 +1,68 @@
+package datos
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestCapturePage(t *testing.T) {
+	c := newTestClient(http.StatusOK, `{"result":{
+		"first":"https://datos.gob.es/apidata/catalog/dataset?_page=0",
+		"next":"https://datos.gob.es/apidata/catalog/dataset?_page=2",
+		"prev":"https://datos.gob.es/apidata/catalog/dataset?_page=0",
+		"page":1,"itemsPerPage":2,"startIndex":3,"totalItems":5,
+		"items":[{"_about":"a"},{"_about":"b"}]
+	}}`)
+
+	var page Page
+	ctx := CapturePage(context.Background(), &page)
+	if _, err := c.Datasets(ctx, Params{Page: 1, PageSize: 2}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := Page{
+		Number: 1,
+		Size:   2,
+		Items:  2,
+		Total:  5,
+		First:  "https://datos.gob.es/apidata/catalog/dataset?_page=0",
+		Next:   "https://datos.gob.es/apidata/catalog/dataset?_page=2",
+		Prev:   "https://datos.gob.es/apidata/catalog/dataset?_page=0",
+	}
+	if page != expected {
+		t.Errorf("wrong page, expected: %+v, got: %+v", expected, page)
+	}
+
+	if !page.HasNext() || page.Pages() != 3 {
+		t.Errorf("expected 3 pages with a next one, got %d pages", page.Pages())
+	}
+
+	c = newTestClient(http.StatusOK, `{"result":{"items":[{"_about":"a"}]}}`)
+	if _, err := c.Datasets(ctx, Params{PageSize: 2}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if page.Total != -1 || page.Pages() != -1 || page.HasNext() {
+		t.Errorf("expected unknown total without next page, got: %+v", page)
+	}
+}
+
+func TestPageHasNext(t *testing.T) {
+	cases := []struct {
+		page     Page
+		expected bool
+	}{
+		{Page{Next: "next", Total: -1}, true},
+		{Page{Number: 0, Size: 10, Total: 11}, true},
+		{Page{Number: 1, Size: 10, Total: 20}, false},
+		{Page{Size: 10, Items: 10, Total: -1}, true},
+		{Page{Size: 10, Items: 9, Total: -1}, false},
+	}
+
+	for _, c := range cases {
+		if got := c.page.HasNext(); got != c.expected {
+			t.Errorf("HasNext of %+v: expected %v, got %v", c.page, c.expected, got)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package datos
+
+import (
+	"net/url"
+	"strings"
+)
+
+const portalURL = "https://datos.gob.es/es/catalogo/"
+
+// PortalURL returns the link to the page of the dataset with the given
+// identifier in the datos.gob.es portal. The identifier can also be the
+// link to the dataset returned by the API.
+func PortalURL(id string) string {
+	id = strings.TrimSpace(id)
+	if strings.Contains(id, "://") {
+		id = lastSegment(id)
+	}
+	return portalURL + url.PathEscape(id)
+}
+
+// PortalURL returns the link to the page of the dataset in the
+// datos.gob.es portal.
+func (d Dataset) PortalURL() string {
+	if d.About != "" {
+		return PortalURL(d.About)
+	}
+	return PortalURL(d.Identifier)
+}
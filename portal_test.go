@@ -0,0 +1,21 @@
+package datos
+
+import "testing"
+
+func TestPortalURL(t *testing.T) {
+	expected := "https://datos.gob.es/es/catalogo/l01280796-calidad-del-aire"
+	for _, id := range []string{
+		"l01280796-calidad-del-aire",
+		"http://datos.gob.es/catalogo/l01280796-calidad-del-aire",
+		"https://datos.gob.es/es/catalogo/l01280796-calidad-del-aire/",
+	} {
+		if got := PortalURL(id); got != expected {
+			t.Errorf("PortalURL(%q): expected: %s, got: %s", id, expected, got)
+		}
+	}
+
+	d := Dataset{Identifier: "https://datos.gob.es/catalogo/l01280796-calidad-del-aire"}
+	if got := d.PortalURL(); got != expected {
+		t.Errorf("wrong dataset portal URL, expected: %s, got: %s", expected, got)
+	}
+}
@@ -0,0 +1,151 @@
+package datos
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// publisherCache keeps the list of all publishers so they can be searched
+// without requesting them every time.
+type publisherCache struct {
+	mut        sync.Mutex
+	publishers []Publisher
+}
+
+// allPublishers returns all the publishers, requesting them only the first
+// time.
+func (c *Client) allPublishers(ctx context.Context) ([]Publisher, error) {
+	c.publishers.mut.Lock()
+	defer c.publishers.mut.Unlock()
+
+	if c.publishers.publishers != nil {
+		return c.publishers.publishers, nil
+	}
+
+	var result []Publisher
+	params := Params{PageSize: 100}
+	for {
+		publishers, err := c.Publishers(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, publishers...)
+		if len(publishers) < int(params.PageSize) {
+			break
+		}
+		params.Page++
+	}
+
+	c.publishers.publishers = result
+	return result, nil
+}
+
+// SearchPublishers returns the publishers whose label matches the given
+// query, ignoring case and accents. Every word of the query must be part of
+// the label, allowing a typo in words of four or more letters. A publisher
+// whose notation is the query is also returned. Results are sorted with
+// exact matches first, then labels starting with the query and then the
+// rest.
+func (c *Client) SearchPublishers(ctx context.Context, q string) ([]Publisher, error) {
+	publishers, err := c.allPublishers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := normalizeText(q)
+	if query == "" {
+		return nil, nil
+	}
+
+	type match struct {
+		Publisher
+		rank int
+	}
+
+	var matches []match
+	for _, p := range publishers {
+		label := normalizeText(p.Label)
+		switch {
+		case label == query || strings.EqualFold(p.Notation, strings.TrimSpace(q)):
+			matches = append(matches, match{p, 0})
+		case strings.HasPrefix(label, query):
+			matches = append(matches, match{p, 1})
+		case matchesWords(label, strings.Fields(query)):
+			matches = append(matches, match{p, 2})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].rank < matches[j].rank
+	})
+
+	result := make([]Publisher, len(matches))
+	for i, m := range matches {
+		result[i] = m.Publisher
+	}
+	return result, nil
+}
+
+// matchesWords reports whether all the words are part of the text, or
+// differ in at most one letter from a word of the text if they are long
+// enough.
+func matchesWords(text string, words []string) bool {
+	textWords := strings.Fields(text)
+	for _, w := range words {
+		if strings.Contains(text, w) {
+			continue
+		}
+
+		var found bool
+		if len(w) >= 4 {
+			for _, tw := range textWords {
+				if withinOneEdit(w, tw) {
+					found = true
+					break
+				}
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// withinOneEdit reports whether a can be turned into b by inserting,
+// removing or replacing at most one character.
+func withinOneEdit(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) > len(rb) {
+		ra, rb = rb, ra
+	}
+
+	if len(rb)-len(ra) > 1 {
+		return false
+	}
+
+	var i, j, edits int
+	for i < len(ra) && j < len(rb) {
+		if ra[i] == rb[j] {
+			i++
+			j++
+			continue
+		}
+
+		edits++
+		if edits > 1 {
+			return false
+		}
+
+		if len(ra) == len(rb) {
+			i++
+		}
+		j++
+	}
+
+	return edits+(len(rb)-j)+(len(ra)-i) <= 1
+}
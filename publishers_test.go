@@ -0,0 +1,56 @@
+package datos
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+const publishersResponse = `{"result":{"items":[
+	{"_about":"http://datos.gob.es/recurso/sector-publico/org/Organismo/L01280796","notation":"L01280796","prefLabel":"Ayuntamiento de Madrid"},
+	{"_about":"http://datos.gob.es/recurso/sector-publico/org/Organismo/A13002908","notation":"A13002908","prefLabel":"Comunidad de Madrid"},
+	{"_about":"http://datos.gob.es/recurso/sector-publico/org/Organismo/L01080193","notation":"L01080193","prefLabel":"Ayuntamiento de Barcelona"},
+	{"_about":"http://datos.gob.es/recurso/sector-publico/org/Organismo/E00003901","notation":"E00003901","prefLabel":"Instituto Nacional de Estadística"}
+]}}`
+
+func TestSearchPublishers(t *testing.T) {
+	c := newTestClient(http.StatusOK, publishersResponse)
+	testCases := []struct {
+		query    string
+		expected []string
+	}{
+		{"madrid", []string{"L01280796", "A13002908"}},
+		{"comunidad de madrid", []string{"A13002908"}},
+		{"ayuntamiento", []string{"L01280796", "L01080193"}},
+		{"estadistica", []string{"E00003901"}},
+		{"instituto estadística", []string{"E00003901"}},
+		{"ayuntamiento barcelna", []string{"L01080193"}},
+		{"l01080193", []string{"L01080193"}},
+		{"sevilla", nil},
+		{"", nil},
+	}
+
+	for _, tt := range testCases {
+		ps, err := c.SearchPublishers(context.Background(), tt.query)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		var got []string
+		for _, p := range ps {
+			got = append(got, p.Notation)
+		}
+
+		if len(got) != len(tt.expected) {
+			t.Errorf("%q: expected: %v, got: %v", tt.query, tt.expected, got)
+			continue
+		}
+
+		for i := range got {
+			if got[i] != tt.expected[i] {
+				t.Errorf("%q: expected: %v, got: %v", tt.query, tt.expected, got)
+				break
+			}
+		}
+	}
+}
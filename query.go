@@ -0,0 +1,259 @@
+package datos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DatasetQuery builds a query of datasets filtered by several dimensions at
+// once, e.g. theme and format. The API only filters by one dimension per
+// request, so the most selective one is used to build the request path
+// and the rest are applied to the results. Because of that, a page of
+// results may contain fewer datasets than the page size.
+type DatasetQuery struct {
+	c            *Client
+	title        string
+	publisher    string
+	theme        string
+	format       string
+	keyword      string
+	spatial      string
+	spatialType  SpatialType
+	after        time.Time
+	before       time.Time
+	issuedAfter  time.Time
+	issuedBefore time.Time
+	params       Params
+}
+
+// Query returns a new query of datasets.
+func (c *Client) Query() *DatasetQuery {
+	return &DatasetQuery{c: c}
+}
+
+// Title only returns datasets whose title contains the given text.
+func (q *DatasetQuery) Title(title string) *DatasetQuery {
+	q.title = title
+	return q
+}
+
+// Publisher only returns datasets with the given publisher ID.
+func (q *DatasetQuery) Publisher(publisherID string) *DatasetQuery {
+	q.publisher = publisherID
+	return q
+}
+
+// Theme only returns datasets with the given theme ID.
+func (q *DatasetQuery) Theme(themeID string) *DatasetQuery {
+	q.theme = themeID
+	return q
+}
+
+// Format only returns datasets with a distribution in the given format.
+func (q *DatasetQuery) Format(format string) *DatasetQuery {
+	q.format = format
+	return q
+}
+
+// Keyword only returns datasets with the given keyword.
+func (q *DatasetQuery) Keyword(keyword string) *DatasetQuery {
+	q.keyword = keyword
+	return q
+}
+
+// Spatial only returns datasets with the given spatial, which is resolved
+// the same way as in DatasetsBySpatial.
+func (q *DatasetQuery) Spatial(typ SpatialType, spatial string) *DatasetQuery {
+	q.spatialType = typ
+	q.spatial = spatial
+	return q
+}
+
+// ModifiedAfter only returns datasets modified after the given time.
+func (q *DatasetQuery) ModifiedAfter(t time.Time) *DatasetQuery {
+	q.after = t
+	return q
+}
+
+// ModifiedBefore only returns datasets modified before the given time.
+func (q *DatasetQuery) ModifiedBefore(t time.Time) *DatasetQuery {
+	q.before = t
+	return q
+}
+
+// IssuedAfter only returns datasets issued after the given time.
+func (q *DatasetQuery) IssuedAfter(t time.Time) *DatasetQuery {
+	q.issuedAfter = t
+	return q
+}
+
+// IssuedBefore only returns datasets issued before the given time.
+func (q *DatasetQuery) IssuedBefore(t time.Time) *DatasetQuery {
+	q.issuedBefore = t
+	return q
+}
+
+// Params sets the page, page size and order of the request.
+func (q *DatasetQuery) Params(params Params) *DatasetQuery {
+	q.params = params
+	return q
+}
+
+// Do performs the query and returns the matching datasets.
+func (q *DatasetQuery) Do(ctx context.Context) ([]Dataset, error) {
+	if q.spatial != "" {
+		s, err := q.c.ResolveSpatial(ctx, q.spatialType, q.spatial)
+		if err == nil {
+			q.spatial = s.ID()
+		} else if errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	if q.format != "" {
+		ctx = withDistributions(ctx)
+	}
+
+	var result []Dataset
+	if err := q.c.getItems(ctx, q.path(), q.params, &result); err != nil {
+		return nil, err
+	}
+
+	datasets := result[:0]
+	for _, d := range result {
+		if q.matches(d) {
+			datasets = append(datasets, d)
+		}
+	}
+
+	return datasets, nil
+}
+
+// path returns the request path of the query, which uses the most
+// selective of its filters.
+func (q *DatasetQuery) path() string {
+	switch {
+	case q.publisher != "":
+		return "/catalog/dataset/publisher/" + url.PathEscape(q.publisher)
+	case q.spatial != "":
+		return fmt.Sprintf("/catalog/dataset/spatial/%s/%s", q.spatialType, url.PathEscape(q.spatial))
+	case q.keyword != "":
+		return "/catalog/dataset/keyword/" + url.PathEscape(q.keyword)
+	case q.title != "":
+		return "/catalog/dataset/title/" + url.PathEscape(q.title)
+	case q.theme != "":
+		return "/catalog/dataset/theme/" + url.PathEscape(q.theme)
+	case q.format != "":
+		return "/catalog/dataset/format/" + url.PathEscape(q.format)
+	case !q.after.IsZero() || !q.before.IsZero():
+		return dateRangePath("modified", q.after, q.before)
+	case !q.issuedAfter.IsZero() || !q.issuedBefore.IsZero():
+		return dateRangePath("issued", q.issuedAfter, q.issuedBefore)
+	default:
+		return "/catalog/dataset"
+	}
+}
+
+// dateRangePath returns the request path of the datasets with the given
+// date field in the range. A zero end of the range means now.
+func dateRangePath(field string, from, to time.Time) string {
+	if to.IsZero() {
+		to = time.Now()
+	}
+
+	return fmt.Sprintf(
+		"/catalog/dataset/%s/begin/%s/end/%s",
+		field,
+		from.Format(time.RFC3339),
+		to.Format(time.RFC3339),
+	)
+}
+
+func (q *DatasetQuery) matches(d Dataset) bool {
+	if q.title != "" && !containsFold(d.Title.Texts(), q.title) {
+		return false
+	}
+
+	if q.publisher != "" && !strings.HasSuffix(d.Publisher, "/"+q.publisher) {
+		return false
+	}
+
+	if q.theme != "" && !hasSuffix(d.Theme, "/"+q.theme) {
+		return false
+	}
+
+	if q.keyword != "" && !equalFold(d.Keywords, q.keyword) {
+		return false
+	}
+
+	if q.spatial != "" && !hasSuffix(d.Spatial, fmt.Sprintf("/%s/%s", q.spatialType, q.spatial)) {
+		return false
+	}
+
+	if q.format != "" && !hasFormat(d.Distribution, q.format) {
+		return false
+	}
+
+	if !q.after.IsZero() && !d.Modified.After(q.after) {
+		return false
+	}
+
+	if !q.before.IsZero() && !d.Modified.Before(q.before) {
+		return false
+	}
+
+	if !q.issuedAfter.IsZero() && !d.Issued.After(q.issuedAfter) {
+		return false
+	}
+
+	if !q.issuedBefore.IsZero() && !d.Issued.Before(q.issuedBefore) {
+		return false
+	}
+
+	return true
+}
+
+func containsFold(values []string, s string) bool {
+	s = strings.ToLower(s)
+	for _, v := range values {
+		if strings.Contains(strings.ToLower(v), s) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasSuffix(values []string, suffix string) bool {
+	for _, v := range values {
+		if strings.HasSuffix(v, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFormat reports whether any of the distributions has the given format,
+// which can be either a media type, such as "text/csv", or its subtype,
+// such as "csv".
+func hasFormat(dists []Distribution, format string) bool {
+	for _, d := range dists {
+		v := d.Format.Value
+		if strings.EqualFold(v, format) || strings.EqualFold(lastSegment(v), format) {
+			return true
+		}
+	}
+	return false
+}
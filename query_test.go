@@ -0,0 +1,73 @@
+package datos
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const queryResponse = `{"result":{"items":[
+	{"_about":"a","title":"Calidad del aire","issued":"vie, 01 ene 2016 10:00:00 GMT+0000","theme":"http://datos.gob.es/kos/sector-publico/sector/medio-ambiente","modified":"lun, 02 ene 2017 10:00:00 GMT+0000","distribution":{"format":{"value":"text/csv"}}},
+	{"_about":"b","title":"Ruido","issued":"dom, 01 ene 2012 10:00:00 GMT+0000","theme":"http://datos.gob.es/kos/sector-publico/sector/medio-ambiente","modified":"lun, 02 ene 2017 10:00:00 GMT+0000","distribution":{"format":{"value":"application/json"}}},
+	{"_about":"c","title":"Residuos","theme":"http://datos.gob.es/kos/sector-publico/sector/medio-ambiente","modified":"vie, 01 ene 2010 10:00:00 GMT+0000","distribution":[{"format":{"value":"text/csv"}}]}
+]}}`
+
+func TestDatasetQuery(t *testing.T) {
+	var paths []string
+	c := newTestClientFunc(func(r *http.Request) (int, string) {
+		paths = append(paths, r.URL.Path)
+		return http.StatusOK, queryResponse
+	})
+
+	ds, err := c.Query().
+		Theme("medio-ambiente").
+		Format("csv").
+		ModifiedAfter(time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)).
+		Do(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(ds) != 1 || ds[0].About != "a" {
+		t.Errorf("wrong datasets: %v", ds)
+	}
+
+	expected := "/apidata/catalog/dataset/theme/medio-ambiente"
+	if len(paths) != 1 || paths[0] != expected {
+		t.Errorf("wrong paths, expected: %s, got: %v", expected, paths)
+	}
+}
+
+func TestDatasetQueryIssued(t *testing.T) {
+	var paths []string
+	c := newTestClientFunc(func(r *http.Request) (int, string) {
+		paths = append(paths, r.URL.Path)
+		return http.StatusOK, queryResponse
+	})
+
+	from := time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC)
+	ds, err := c.Query().Theme("medio-ambiente").IssuedAfter(from).Do(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(ds) != 1 || ds[0].About != "a" {
+		t.Errorf("wrong datasets: %v", ds)
+	}
+
+	ds, err = c.Query().IssuedAfter(from).IssuedBefore(to).Do(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(ds) != 1 || ds[0].About != "a" {
+		t.Errorf("wrong datasets: %v", ds)
+	}
+
+	expected := "/apidata/catalog/dataset/issued/begin/2015-01-01T00:00:00Z/end/2017-01-01T00:00:00Z"
+	if len(paths) != 2 || paths[1] != expected {
+		t.Errorf("wrong paths, expected: %s, got: %v", expected, paths)
+	}
+}
@@ -0,0 +1,219 @@
+package datos
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"unicode/utf8"
+)
+
+// RecordMode controls when a Recorder performs real requests.
+type RecordMode int
+
+const (
+	// RecordOnce replays the recorded responses and records the ones that
+	// are missing. It's the default mode.
+	RecordOnce RecordMode = iota
+	// ReplayOnly only replays recorded responses and fails the requests
+	// that were not recorded, so no request reaches the network.
+	ReplayOnly
+	// RecordAll performs every request and records all the responses
+	// again, replacing the previous ones.
+	RecordAll
+)
+
+// Recorder is an http.RoundTripper that records the responses of the API
+// to golden files on the first run and replays them afterwards, so tests
+// and pipelines using the client are reproducible. It can be used in a
+// client with WithTransport:
+//
+//	client, err := datos.NewClient(datos.WithTransport(datos.NewRecorder("testdata", datos.RecordOnce)))
+//
+// Each response is recorded as a JSON file in the directory of the
+// recorder, named after the URL of the request. Responses with a 5xx or 429
+// status are not recorded, since they're usually transient.
+type Recorder struct {
+	dir  string
+	mode RecordMode
+
+	// Transport performs the requests that have to be recorded. If it's
+	// nil, a transport with the certificates required to call the API is
+	// used, which are only fetched when there is something to record.
+	Transport http.RoundTripper
+
+	fallback bootstrapTransport
+}
+
+// NewRecorder returns a recorder storing its golden files in the given
+// directory.
+func NewRecorder(dir string, mode RecordMode) *Recorder {
+	return &Recorder{dir: dir, mode: mode}
+}
+
+// recording is the content of a golden file.
+type recording struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+	// Binary is set when the body is not valid UTF-8 and it's stored
+	// encoded in base64.
+	Binary bool `json:"binary,omitempty"`
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := r.path(req)
+	if r.mode != RecordAll {
+		rec, err := readRecording(path)
+		if err == nil {
+			return rec.response(req), nil
+		}
+
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		if r.mode == ReplayOnly {
+			return nil, fmt.Errorf("datos: no recorded response for %s %s", req.Method, req.URL)
+		}
+	}
+
+	resp, err := r.transport().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	rec := recording{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Status: resp.StatusCode,
+		Header: resp.Header,
+		Body:   string(body),
+	}
+
+	if !utf8.Valid(body) {
+		rec.Binary = true
+		rec.Body = base64.StdEncoding.EncodeToString(body)
+	}
+
+	if err := writeRecording(path, rec); err != nil {
+		return nil, fmt.Errorf("datos: unable to record response: %s", err)
+	}
+
+	return resp, nil
+}
+
+// transport returns the transport used to perform real requests.
+func (r *Recorder) transport() http.RoundTripper {
+	if r.Transport != nil {
+		return r.Transport
+	}
+	return &r.fallback
+}
+
+var unsafeChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// path returns the golden file of the request. The name has the host, path
+// and query of the URL to make the files easy to find, and a hash of the
+// method and the URL because the name is truncated and some characters are
+// replaced.
+func (r *Recorder) path(req *http.Request) string {
+	u := req.URL
+	name := unsafeChars.ReplaceAllString(u.Host+u.Path+"?"+u.RawQuery, "_")
+	if len(name) > 100 {
+		name = name[:100]
+	}
+
+	sum := sha256.Sum256([]byte(req.Method + " " + u.String()))
+	return filepath.Join(r.dir, fmt.Sprintf("%s-%s.json", name, hex.EncodeToString(sum[:4])))
+}
+
+func readRecording(path string) (recording, error) {
+	var rec recording
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return rec, err
+	}
+
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return rec, newError(ErrDecoding, err, "datos: invalid recorded response %q: %s", path, err)
+	}
+
+	return rec, nil
+}
+
+func writeRecording(path string, rec recording) error {
+	data, err := json.MarshalIndent(rec, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(path), ".tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// response returns the recorded response to the given request.
+func (rec recording) response(req *http.Request) *http.Response {
+	body := []byte(rec.Body)
+	if rec.Binary {
+		body, _ = base64.StdEncoding.DecodeString(rec.Body)
+	}
+
+	header := rec.Header
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,129 @@
+package datos
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos-recorder")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var requests int
+	status := http.StatusOK
+	live := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		requests++
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       ioutil.NopCloser(strings.NewReader(`{"result":{"items":[{"_about":"foo"}]}}`)),
+			Request:    r,
+		}, nil
+	})
+
+	newClient := func(mode RecordMode) *Client {
+		rec := NewRecorder(dir, mode)
+		rec.Transport = live
+		c, err := NewClient(WithTransport(rec))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		return c
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		ps, err := newClient(RecordOnce).Publishers(ctx, Params{})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if len(ps) != 1 || ps[0].About != "foo" {
+			t.Errorf("wrong publishers: %v", ps)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("expected response to be replayed, got %d requests", requests)
+	}
+
+	if _, err := newClient(ReplayOnly).Publishers(ctx, Params{}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if _, err := newClient(ReplayOnly).Themes(ctx, Params{}); err == nil {
+		t.Errorf("expected error replaying a request that was not recorded")
+	}
+
+	if _, err := newClient(RecordAll).Publishers(ctx, Params{}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if requests != 2 {
+		t.Errorf("expected response to be recorded again, got %d requests", requests)
+	}
+
+	status = http.StatusServiceUnavailable
+	newClient(RecordOnce).Spatials(ctx, Params{})
+	newClient(RecordOnce).Spatials(ctx, Params{})
+	if requests != 4 {
+		t.Errorf("expected failed responses not to be recorded, got %d requests", requests)
+	}
+}
+
+func TestRecorderBinary(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos-recorder")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	body := "\x1f\x8b\x08\x00\xff"
+	rec := NewRecorder(dir, RecordOnce)
+	rec.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+			Request:    r,
+		}, nil
+	})
+
+	req, _ := http.NewRequest("GET", "https://example.com/file.gz", nil)
+	if _, err := rec.RoundTrip(req); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	resp, err := NewRecorder(dir, ReplayOnly).RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, _ := ioutil.ReadAll(resp.Body)
+	if string(data) != body {
+		t.Errorf("wrong replayed body: %q", data)
+	}
+}
+
+func TestWithTransport(t *testing.T) {
+	hc := &http.Client{}
+	c, err := NewClient(WithHTTPClient(hc), WithTransport(NewRecorder("testdata", ReplayOnly)))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if hc.Transport != nil {
+		t.Errorf("expected given HTTP client not to be modified")
+	}
+
+	if _, ok := c.c.Transport.(*Recorder); !ok {
+		t.Errorf("expected recorder transport, got: %T", c.c.Transport)
+	}
+}
@@ -0,0 +1,156 @@
+package datos
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// CacheOpener opens a cache at the given location, whose meaning depends
+// on the backend, e.g. a folder for disk caches.
+type CacheOpener func(location string) (Cache, error)
+
+// CatalogOpener opens a catalog at the given location, whose meaning
+// depends on the backend, e.g. a file for snapshots.
+type CatalogOpener func(location string) (Catalog, error)
+
+var registry = struct {
+	sync.RWMutex
+	caches   map[string]CacheOpener
+	catalogs map[string]CatalogOpener
+}{
+	caches:   make(map[string]CacheOpener),
+	catalogs: make(map[string]CatalogOpener),
+}
+
+// RegisterCache makes a cache backend available by the given name to
+// OpenCache, so other packages can add backends from their init
+// functions. It panics if the name is already registered or open is nil.
+func RegisterCache(name string, open CacheOpener) {
+	registry.Lock()
+	defer registry.Unlock()
+	if open == nil {
+		panic("datos: RegisterCache opener is nil")
+	}
+	if _, ok := registry.caches[name]; ok {
+		panic("datos: RegisterCache called twice for " + name)
+	}
+	registry.caches[name] = open
+}
+
+// RegisterCatalog makes a catalog backend available by the given name to
+// OpenCatalog, so other packages can add backends from their init
+// functions. It panics if the name is already registered or open is nil.
+func RegisterCatalog(name string, open CatalogOpener) {
+	registry.Lock()
+	defer registry.Unlock()
+	if open == nil {
+		panic("datos: RegisterCatalog opener is nil")
+	}
+	if _, ok := registry.catalogs[name]; ok {
+		panic("datos: RegisterCatalog called twice for " + name)
+	}
+	registry.catalogs[name] = open
+}
+
+// OpenCache opens a cache with the backend registered with the given
+// name. The disk and memory backends are always registered.
+func OpenCache(name, location string) (Cache, error) {
+	registry.RLock()
+	open, ok := registry.caches[name]
+	registry.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("datos: unknown cache backend %q", name)
+	}
+	return open(location)
+}
+
+// OpenCatalog opens a catalog with the backend registered with the given
+// name. The api, snapshot and dcat backends are always registered: api is
+// the public API, or the one at the given URL if any, snapshot is a file
+// written by SaveSnapshot and dcat is a DCAT-AP catalog in JSON-LD.
+func OpenCatalog(name, location string) (Catalog, error) {
+	registry.RLock()
+	open, ok := registry.catalogs[name]
+	registry.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("datos: unknown catalog backend %q", name)
+	}
+	return open(location)
+}
+
+// CacheBackends returns the sorted names of the registered cache
+// backends.
+func CacheBackends() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	var names []string
+	for name := range registry.caches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CatalogBackends returns the sorted names of the registered catalog
+// backends.
+func CatalogBackends() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	var names []string
+	for name := range registry.catalogs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterCache("memory", func(string) (Cache, error) {
+		return NewMemoryCache(), nil
+	})
+	RegisterCache("disk", func(location string) (Cache, error) {
+		c, err := NewDiskCache(location)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	})
+
+	RegisterCatalog("api", func(location string) (Catalog, error) {
+		var opts []Option
+		if location != "" {
+			opts = append(opts, WithBaseURL(location))
+		}
+
+		c, err := NewClient(opts...)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	})
+	RegisterCatalog("snapshot", func(location string) (Catalog, error) {
+		c, err := OpenSnapshot(location)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	})
+	RegisterCatalog("dcat", func(location string) (Catalog, error) {
+		f, err := os.Open(location)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		datasets, err := ReadDCAT(f)
+		if err != nil {
+			return nil, err
+		}
+
+		c := new(OfflineClient)
+		c.Import(datasets...)
+		return c, nil
+	})
+}
@@ -0,0 +1,81 @@
+package datos
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenCache(t *testing.T) {
+	cache, err := OpenCache("memory", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, ok := cache.(*MemoryCache); !ok {
+		t.Errorf("expected memory cache, got: %T", cache)
+	}
+
+	if _, err := OpenCache("redis", "localhost:6379"); err == nil {
+		t.Errorf("expected error opening unknown backend")
+	}
+
+	RegisterCache("test-cache", func(location string) (Cache, error) {
+		return NewMemoryCache(), nil
+	})
+
+	names := CacheBackends()
+	if len(names) != 3 || names[0] != "disk" || names[2] != "test-cache" {
+		t.Errorf("wrong cache backends: %v", names)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic registering a backend twice")
+		}
+	}()
+	RegisterCache("test-cache", func(location string) (Cache, error) {
+		return nil, nil
+	})
+}
+
+func TestOpenCatalog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datos-registry")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "catalog.jsonld")
+	if err := ioutil.WriteFile(path, []byte(testDCAT), 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c, err := OpenCatalog("dcat", path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ds, err := c.Datasets(context.Background(), Params{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(ds) != 1 || ds[0].Identifier != "air" {
+		t.Errorf("wrong datasets: %+v", ds)
+	}
+
+	if c, err := OpenCatalog("snapshot", filepath.Join(dir, "missing.jsonl")); err == nil || c != nil {
+		t.Errorf("expected error and no catalog opening a missing snapshot, got: %v", c)
+	}
+
+	if _, err := OpenCatalog("ckan", ""); err == nil {
+		t.Errorf("expected error opening unknown backend")
+	}
+
+	if names := CatalogBackends(); len(names) != 3 || names[0] != "api" {
+		t.Errorf("wrong catalog backends: %v", names)
+	}
+}
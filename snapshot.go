@@ -0,0 +1,224 @@
+package datos
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// snapshotPageSize is the page size used to download the catalog.
+const snapshotPageSize = 50
+
+// SnapshotVersion is the version of the format of the snapshots written by
+// this release. It's increased every time the format changes in a way
+// previous releases can't read.
+//
+// A snapshot is a JSON lines file. Every line is an object with the type
+// of the item and the item itself:
+//
+//	{"type":"header","item":{"version":1,"created":"2019-03-01T10:00:00Z","source":"https://datos.gob.es/apidata"}}
+//	{"type":"publisher","item":{"_about":"...","notation":"L01280796","prefLabel":"Ayuntamiento de Madrid"}}
+//
+// The first line is the header, with the version of the format, the time
+// the snapshot was created and the URL of the API it was downloaded from.
+// It's followed by all the publishers, themes, spatials and datasets, of
+// types publisher, theme, spatial and dataset respectively, as returned by
+// the API.
+//
+// Snapshots written before the format was versioned have no header and are
+// read as version 0.
+const SnapshotVersion = 1
+
+// snapshotMigrations upgrade the items of a snapshot to the next version
+// of the format, so the migration at index i upgrades items of version i
+// to version i+1.
+var snapshotMigrations = []func(*snapshotItem) error{
+	// Version 1 only added the header.
+	func(*snapshotItem) error { return nil },
+}
+
+// SnapshotHeader describes a snapshot.
+type SnapshotHeader struct {
+	// Version of the format of the snapshot.
+	Version int `json:"version"`
+	// Created is the time the snapshot was created.
+	Created time.Time `json:"created"`
+	// Source is the URL of the API the snapshot was downloaded from.
+	Source string `json:"source"`
+}
+
+// snapshotItem is a line of a snapshot file.
+type snapshotItem struct {
+	// Type of the item: header, dataset, publisher, theme or spatial.
+	Type string `json:"type"`
+	// Item as returned by the API.
+	Item json.RawMessage `json:"item"`
+}
+
+// snapshotPaths are the paths downloaded in a snapshot by item type.
+var snapshotPaths = []struct {
+	typ  string
+	path string
+}{
+	{"publisher", "/catalog/publisher"},
+	{"theme", "/catalog/theme"},
+	{"spatial", "/catalog/spatial"},
+	{"dataset", "/catalog/dataset"},
+}
+
+// WriteSnapshot downloads the whole catalog, that is, all the publishers,
+// themes, spatials and datasets along with their distributions, and writes
+// it to w in the format described in SnapshotVersion. Items are written as
+// returned by the API, so snapshots can be loaded by future versions that
+// decode more fields. The snapshot can be queried without network access
+// with an OfflineClient.
+func (c *Client) WriteSnapshot(ctx context.Context, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+
+	header, err := json.Marshal(SnapshotHeader{SnapshotVersion, time.Now().UTC(), c.baseURL()})
+	if err != nil {
+		return err
+	}
+
+	if err := enc.Encode(snapshotItem{"header", header}); err != nil {
+		return err
+	}
+
+	for _, p := range snapshotPaths {
+		params := Params{PageSize: snapshotPageSize}
+		for {
+			var resp itemsResp
+			if err := c.get(ctx, p.path, params, &resp); err != nil {
+				return err
+			}
+
+			for _, item := range resp.Result.Items {
+				if err := enc.Encode(snapshotItem{p.typ, item}); err != nil {
+					return err
+				}
+			}
+
+			if len(resp.Result.Items) < snapshotPageSize {
+				break
+			}
+			params.Page++
+		}
+	}
+
+	return bw.Flush()
+}
+
+// SaveSnapshot downloads the whole catalog into the file at path, as
+// WriteSnapshot does. The file is only replaced once the snapshot is
+// complete.
+func (c *Client) SaveSnapshot(ctx context.Context, path string) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	err = c.WriteSnapshot(ctx, f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// ReadSnapshot reads a snapshot written by WriteSnapshot by this or any
+// previous release and returns an OfflineClient to query it. Items that
+// can not be decoded are skipped. ErrDecoding is returned if the snapshot
+// was written by a newer release with a format that can't be read.
+func ReadSnapshot(r io.Reader) (*OfflineClient, error) {
+	c := &OfflineClient{index: make(map[string]int)}
+	dec := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		var item snapshotItem
+		if err := dec.Decode(&item); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, newError(ErrDecoding, err, "datos: invalid snapshot item %d: %s", line, err)
+		}
+
+		if line == 1 && item.Type == "header" {
+			if err := json.Unmarshal(item.Item, &c.header); err != nil {
+				return nil, newError(ErrDecoding, err, "datos: invalid snapshot header: %s", err)
+			}
+
+			if c.header.Version > SnapshotVersion {
+				return nil, newError(
+					ErrDecoding, nil,
+					"datos: snapshot version %d is newer than the supported version %d",
+					c.header.Version, SnapshotVersion,
+				)
+			}
+
+			if c.header.Version < 0 {
+				return nil, newError(ErrDecoding, nil, "datos: invalid snapshot version %d", c.header.Version)
+			}
+			continue
+		}
+
+		for _, migrate := range snapshotMigrations[c.header.Version:] {
+			if err := migrate(&item); err != nil {
+				return nil, newError(ErrDecoding, err, "datos: unable to migrate snapshot item %d: %s", line, err)
+			}
+		}
+
+		var err error
+		switch item.Type {
+		case "publisher":
+			var p Publisher
+			if err = decodeItem(item.Item, &p); err == nil {
+				c.publishers = append(c.publishers, p)
+			}
+		case "theme":
+			var t Theme
+			if err = decodeItem(item.Item, &t); err == nil {
+				c.themes = append(c.themes, t)
+			}
+		case "spatial":
+			var s Spatial
+			if err = decodeItem(item.Item, &s); err == nil {
+				c.spatials = append(c.spatials, s)
+			}
+		case "dataset":
+			var d Dataset
+			if err = decodeItem(item.Item, &d); err != nil {
+				break
+			}
+
+			// The catalog may change while it's paged through, so the same
+			// dataset can appear more than once.
+			c.Import(d)
+		default:
+			return nil, newError(ErrDecoding, nil, "datos: invalid snapshot item %d: unknown type %q", line, item.Type)
+		}
+	}
+
+	return c, nil
+}
+
+// OpenSnapshot reads the snapshot in the file at path and returns an
+// OfflineClient to query it.
+func OpenSnapshot(path string) (*OfflineClient, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("datos: unable to open snapshot: %s", err)
+	}
+	defer f.Close()
+
+	return ReadSnapshot(f)
+}
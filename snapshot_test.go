@@ -0,0 +1,162 @@
+package datos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+var snapshotResponses = map[string]string{
+	"/catalog/publisher": `{"_about":"http://datos.gob.es/recurso/sector-publico/org/Organismo/L01280796","notation":"L01280796","prefLabel":"Ayuntamiento de Madrid"}`,
+	"/catalog/theme":     `{"_about":"http://datos.gob.es/kos/sector-publico/sector/salud","notation":"salud","prefLabel":["Salud"]}`,
+	"/catalog/spatial":   `{"_about":"http://datos.gob.es/recurso/sector-publico/territorio/Provincia/Madrid","label":"Madrid"}`,
+}
+
+func newSnapshotClient(t *testing.T) *Client {
+	t.Helper()
+	var datasets []string
+	for i := 0; i < snapshotPageSize+2; i++ {
+		theme := "salud"
+		if i%2 == 1 {
+			theme = "turismo"
+		}
+
+		datasets = append(datasets, fmt.Sprintf(
+			`{"_about":"http://datos.gob.es/catalogo/ds-%d","identifier":"ds-%d",`+
+				`"title":"Dataset %d","theme":"http://datos.gob.es/kos/sector-publico/sector/%s",`+
+				`"spatial":"http://datos.gob.es/recurso/sector-publico/territorio/Provincia/Madrid",`+
+				`"issued":"dom, %02d nov 2012 23:00:00 GMT+0000",`+
+				`"distribution":{"accessURL":"http://example.com/%d.csv","format":{"value":"text/csv"}}}`,
+			i, i, i, theme, i%28+1, i,
+		))
+	}
+
+	return newTestClientFunc(func(r *http.Request) (int, string) {
+		path := strings.TrimPrefix(r.URL.Path, "/apidata")
+		if path == "/catalog/dataset" {
+			page := datasets[:snapshotPageSize]
+			if r.URL.Query().Get("_page") == "1" {
+				page = datasets[snapshotPageSize:]
+			}
+			return http.StatusOK, `{"result":{"items":[` + strings.Join(page, ",") + `]}}`
+		}
+
+		item, ok := snapshotResponses[path]
+		if !ok {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		return http.StatusOK, `{"result":{"items":[` + item + `]}}`
+	})
+}
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	if err := newSnapshotClient(t).WriteSnapshot(ctx, &buf); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c, err := ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if h := c.Header(); h.Version != SnapshotVersion || h.Source != defaultBaseURL || h.Created.IsZero() {
+		t.Errorf("wrong header: %+v", h)
+	}
+
+	ps, _ := c.Publishers(ctx, Params{})
+	if len(ps) != 1 || ps[0].Notation != "L01280796" {
+		t.Errorf("wrong publishers: %v", ps)
+	}
+
+	ds, _ := c.Datasets(ctx, Params{PageSize: 100})
+	if len(ds) != snapshotPageSize+2 {
+		t.Errorf("wrong number of datasets, expected: %d, got: %d", snapshotPageSize+2, len(ds))
+	}
+
+	ds, _ = c.DatasetsByTheme(ctx, "turismo", Params{PageSize: 5, Page: 1, Sort: "-issued"})
+	if len(ds) != 5 {
+		t.Fatalf("wrong number of datasets, expected: 5, got: %d", len(ds))
+	}
+
+	for i, d := range ds {
+		if !hasSuffix(d.Theme, "/turismo") {
+			t.Errorf("wrong theme of dataset %s: %v", d.Identifier, d.Theme)
+		}
+
+		if i > 0 && d.Issued.After(ds[i-1].Issued.Time) {
+			t.Errorf("datasets not sorted by issued date")
+		}
+	}
+
+	ds, err = c.DatasetsBySpatial(ctx, Province, "madrid", Params{})
+	if err != nil || len(ds) != defaultPageSize {
+		t.Errorf("wrong datasets by spatial: %d, err: %v", len(ds), err)
+	}
+
+	_, err = c.DatasetsBySpatial(ctx, Province, "Huesca", Params{})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+
+	from := time.Date(2012, time.November, 10, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2012, time.November, 12, 0, 0, 0, 0, time.UTC)
+	ds, _ = c.DatasetsModifiedBetween(ctx, from, to, Params{})
+	if len(ds) != 0 {
+		t.Errorf("expected no datasets modified, got: %d", len(ds))
+	}
+
+	d, err := c.Dataset(ctx, "ds-3", Params{})
+	if err != nil || d.Title.Default() != "Dataset 3" {
+		t.Errorf("wrong dataset: %v, err: %v", d.Title, err)
+	}
+
+	dists, _ := c.DistributionsByDataset(ctx, "ds-3", Params{})
+	if len(dists) != 1 || dists[0].AccessURL != "http://example.com/3.csv" {
+		t.Errorf("wrong distributions: %v", dists)
+	}
+
+	dists, _ = c.DistributionsByFormat(ctx, "json", Params{})
+	if len(dists) != 0 {
+		t.Errorf("expected no json distributions, got: %d", len(dists))
+	}
+}
+
+func TestReadSnapshotError(t *testing.T) {
+	_, err := ReadSnapshot(strings.NewReader(`{"type":"foo","item":{}}`))
+	if !errors.Is(err, ErrDecoding) {
+		t.Errorf("expected decoding error, got: %v", err)
+	}
+}
+
+func TestReadSnapshotVersions(t *testing.T) {
+	theme := `{"type":"theme","item":{"_about":"http://datos.gob.es/kos/sector-publico/sector/salud"}}`
+
+	c, err := ReadSnapshot(strings.NewReader(theme))
+	if err != nil {
+		t.Fatalf("unexpected error reading snapshot with no header: %s", err)
+	}
+
+	themes, _ := c.Themes(context.Background(), Params{})
+	if c.Header().Version != 0 || len(themes) != 1 {
+		t.Errorf("wrong snapshot with no header, header: %+v, themes: %d", c.Header(), len(themes))
+	}
+
+	header := fmt.Sprintf(`{"type":"header","item":{"version":%d}}`, SnapshotVersion+1)
+	_, err = ReadSnapshot(strings.NewReader(header + "\n" + theme))
+	if !errors.Is(err, ErrDecoding) {
+		t.Errorf("expected decoding error reading newer snapshot, got: %v", err)
+	}
+
+	header = `{"type":"header","item":{"version":-1}}`
+	_, err = ReadSnapshot(strings.NewReader(header + "\n" + theme))
+	if !errors.Is(err, ErrDecoding) {
+		t.Errorf("expected decoding error reading negative version, got: %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package datos
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// ID returns the identifier of the spatial, which is the last segment of its
+// link, e.g. "Aragon".
+func (s Spatial) ID() string {
+	return lastSegment(s.About)
+}
+
+// SpatialType returns the type of the spatial. The second result is false
+// if the type is not one of the known spatial types.
+func (s Spatial) SpatialType() (SpatialType, bool) {
+	typ := lastSegment(s.Type)
+	if typ == "" {
+		// Links look like .../territorio/Provincia/Huesca.
+		parts := strings.Split(strings.TrimRight(s.About, "/"), "/")
+		if len(parts) >= 2 {
+			typ = parts[len(parts)-2]
+		}
+	}
+
+	for _, t := range []SpatialType{Autonomy, Country, Province} {
+		if strings.EqualFold(typ, t.String()) {
+			return t, true
+		}
+	}
+
+	return 0, false
+}
+
+// spatialCache keeps the list of all spatials, which is fixed, so the
+// hierarchy can be navigated without requesting it every time.
+type spatialCache struct {
+	mut      sync.Mutex
+	spatials []Spatial
+}
+
+// allSpatials returns all the spatials, requesting them only the first
+// time.
+func (c *Client) allSpatials(ctx context.Context) ([]Spatial, error) {
+	c.spatials.mut.Lock()
+	defer c.spatials.mut.Unlock()
+
+	if c.spatials.spatials != nil {
+		return c.spatials.spatials, nil
+	}
+
+	var result []Spatial
+	params := Params{PageSize: 100}
+	for {
+		spatials, err := c.Spatials(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, spatials...)
+		if len(spatials) < int(params.PageSize) {
+			break
+		}
+		params.Page++
+	}
+
+	c.spatials.spatials = result
+	return result, nil
+}
+
+func (c *Client) spatialsOfType(ctx context.Context, typ SpatialType) ([]Spatial, error) {
+	spatials, err := c.allSpatials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Spatial
+	for _, s := range spatials {
+		if t, ok := s.SpatialType(); ok && t == typ {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+// Autonomies returns all the autonomous communities.
+func (c *Client) Autonomies(ctx context.Context) ([]Spatial, error) {
+	return c.spatialsOfType(ctx, Autonomy)
+}
+
+// Provinces returns the provinces of the given autonomous community, which
+// can be given by its label, identifier or link. If autonomy is empty, all
+// provinces are returned.
+func (c *Client) Provinces(ctx context.Context, autonomy string) ([]Spatial, error) {
+	provinces, err := c.spatialsOfType(ctx, Province)
+	if err != nil || autonomy == "" {
+		return provinces, err
+	}
+
+	a, err := c.ResolveSpatial(ctx, Autonomy, autonomy)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Spatial
+	for _, p := range provinces {
+		if p.Autonomy == a.About || lastSegment(p.Autonomy) == a.ID() {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+// ResolveSpatial returns the spatial of the given type matching the given
+// label, identifier or link. Names are matched ignoring case, accents,
+// articles and using both official and common names, so "A Coruña",
+// "La Coruna" and "Coruña" are the same province. ErrNotFound is returned
+// if there is none.
+func (c *Client) ResolveSpatial(ctx context.Context, typ SpatialType, name string) (Spatial, error) {
+	spatials, err := c.spatialsOfType(ctx, typ)
+	if err != nil {
+		return Spatial{}, err
+	}
+
+	return resolveSpatial(spatials, typ, name)
+}
+
+// resolveSpatial returns the spatial matching the given name among the
+// given spatials of type typ.
+func resolveSpatial(spatials []Spatial, typ SpatialType, name string) (Spatial, error) {
+	for _, s := range spatials {
+		if s.About == name || strings.EqualFold(s.ID(), name) || strings.EqualFold(s.Label, name) {
+			return s, nil
+		}
+	}
+
+	normalized := normalizePlace(name)
+	for _, s := range spatials {
+		if normalizePlace(s.ID()) == normalized || normalizePlace(s.Label) == normalized {
+			return s, nil
+		}
+	}
+
+	return Spatial{}, newError(ErrNotFound, nil, "datos: %s not found: %q", typ.Label(English), name)
+}
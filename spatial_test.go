@@ -0,0 +1,58 @@
+package datos
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+const territorio = "http://datos.gob.es/recurso/sector-publico/territorio/"
+
+const spatialsResponse = `{"result":{"items":[
+	{"_about":"` + territorio + `Pais/España","label":"España","type":"Pais"},
+	{"_about":"` + territorio + `Autonomia/Aragon","label":"Aragón","pais":"` + territorio + `Pais/España","type":"Autonomia"},
+	{"_about":"` + territorio + `Autonomia/Cataluna","label":"Cataluña","pais":"` + territorio + `Pais/España","type":"Autonomia"},
+	{"_about":"` + territorio + `Provincia/Huesca","label":"Huesca","autonomia":"` + territorio + `Autonomia/Aragon","type":"Provincia"},
+	{"_about":"` + territorio + `Provincia/Teruel","label":"Teruel","autonomia":"` + territorio + `Autonomia/Aragon","type":"Provincia"},
+	{"_about":"` + territorio + `Provincia/Girona","label":"Girona","autonomia":"` + territorio + `Autonomia/Cataluna","type":"Provincia"}
+]}}`
+
+func TestAutonomies(t *testing.T) {
+	as, err := newTestClient(http.StatusOK, spatialsResponse).Autonomies(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(as) != 2 || as[0].ID() != "Aragon" || as[1].ID() != "Cataluna" {
+		t.Errorf("wrong autonomies: %v", as)
+	}
+}
+
+func TestProvinces(t *testing.T) {
+	c := newTestClient(http.StatusOK, spatialsResponse)
+	for _, autonomy := range []string{"Aragón", "aragon", territorio + "Autonomia/Aragon"} {
+		ps, err := c.Provinces(context.Background(), autonomy)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if len(ps) != 2 || ps[0].Label != "Huesca" || ps[1].Label != "Teruel" {
+			t.Errorf("wrong provinces of %s: %v", autonomy, ps)
+		}
+	}
+
+	ps, err := c.Provinces(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(ps) != 3 {
+		t.Errorf("wrong number of provinces, expected: 3, got: %d", len(ps))
+	}
+
+	_, err = c.Provinces(context.Background(), "Atlantis")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+}
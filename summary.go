@@ -0,0 +1,59 @@
+package datos
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// DatasetSummary has the fields of a dataset needed to list datasets: its
+// link, identifier, title and modification date.
+type DatasetSummary struct {
+	// About contains a link to the information about this object.
+	About      string      `json:"_about"`
+	Identifier string      `json:"identifier"`
+	Title      LangStrings `json:"title"`
+	Modified   Datetime    `json:"modified"`
+}
+
+// UnmarshalJSON decodes the summary like a dataset, so the identifier can
+// be a list, in which case Identifier is the first one.
+func (s *DatasetSummary) UnmarshalJSON(b []byte) error {
+	type summaryFields DatasetSummary
+	var fields struct {
+		*summaryFields
+		Identifier Strings `json:"identifier"`
+	}
+	fields.summaryFields = (*summaryFields)(s)
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+
+	if len(fields.Identifier) > 0 {
+		s.Identifier = fields.Identifier[0]
+	}
+	return nil
+}
+
+// DatasetSummaries returns the summaries of all datasets. The API has no
+// way to select the fields of the response, so the whole datasets are
+// still requested, but only the fields of the summaries are decoded, which
+// is much faster and uses less memory than decoding the datasets with
+// their distributions.
+func (c *Client) DatasetSummaries(ctx context.Context, params Params) ([]DatasetSummary, error) {
+	var result []DatasetSummary
+	if err := c.getItems(ctx, "/catalog/dataset", params, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Summary returns the summary of the dataset.
+func (d Dataset) Summary() DatasetSummary {
+	return DatasetSummary{
+		About:      d.About,
+		Identifier: d.Identifier,
+		Title:      d.Title,
+		Modified:   d.Modified,
+	}
+}
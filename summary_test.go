@@ -0,0 +1,67 @@
+package datos
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestDatasetSummaries(t *testing.T) {
+	c := newTestClient(http.StatusOK, queryResponse)
+	summaries, err := c.DatasetSummaries(context.Background(), Params{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	datasets, err := c.Datasets(context.Background(), Params{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(summaries) != len(datasets) {
+		t.Fatalf("wrong number of summaries, expected: %d, got: %d", len(datasets), len(summaries))
+	}
+
+	for i, s := range summaries {
+		expected := datasets[i].Summary()
+		if s.About != expected.About || s.Identifier != expected.Identifier || s.Title.Default() != expected.Title.Default() ||
+			!s.Modified.Equal(expected.Modified.Time) {
+			t.Errorf("wrong summary, expected: %+v, got: %+v", expected, s)
+		}
+	}
+}
+
+func TestDatasetSummariesIdentifiers(t *testing.T) {
+	for _, response := range []string{fullDataset, `{"result":{"items":[{"identifier":null},{"identifier":{"_about":"urn:b"}}]}}`} {
+		c := newTestClient(http.StatusOK, response)
+		summaries, err := c.DatasetSummaries(context.Background(), Params{})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		datasets, err := c.Datasets(context.Background(), Params{})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if len(summaries) != len(datasets) {
+			t.Fatalf("wrong number of summaries, expected: %d, got: %d", len(datasets), len(summaries))
+		}
+
+		for i, s := range summaries {
+			if s.Identifier != datasets[i].Identifier {
+				t.Errorf("wrong identifier, expected: %q, got: %q", datasets[i].Identifier, s.Identifier)
+			}
+		}
+	}
+
+	var s DatasetSummary
+	if err := json.Unmarshal([]byte(`{"identifier":["urn:a","urn:b"],"title":"A"}`), &s); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if s.Identifier != "urn:a" || s.Title.Default() != "A" {
+		t.Errorf("wrong summary with a list of identifiers: %+v", s)
+	}
+}
@@ -0,0 +1,109 @@
+package datos
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// downloadTimer cancels a download when any of the timeouts of its options
+// expires.
+type downloadTimer struct {
+	opts   DownloadOptions
+	cancel context.CancelFunc
+	start  time.Time
+
+	mut     sync.Mutex
+	expired string
+	connect *time.Timer
+	idle    *time.Timer
+	total   *time.Timer
+}
+
+func newDownloadTimer(ctx context.Context, opts DownloadOptions) (context.Context, *downloadTimer) {
+	ctx, cancel := context.WithCancel(ctx)
+	t := &downloadTimer{opts: opts, cancel: cancel, start: time.Now()}
+	if opts.ConnectTimeout > 0 {
+		t.connect = time.AfterFunc(opts.ConnectTimeout, t.expire("connect"))
+	}
+	return ctx, t
+}
+
+func (t *downloadTimer) expire(name string) func() {
+	return func() {
+		t.mut.Lock()
+		if t.expired == "" {
+			t.expired = name
+		}
+		t.mut.Unlock()
+		t.cancel()
+	}
+}
+
+// connected stops the connect timeout and starts the idle and total ones,
+// once the response headers are received and the size of the file to
+// download is known, which is -1 if it's not.
+func (t *downloadTimer) connected(size int64) {
+	if t.connect != nil {
+		t.connect.Stop()
+	}
+
+	if t.opts.IdleTimeout > 0 {
+		t.idle = time.AfterFunc(t.opts.IdleTimeout, t.expire("idle"))
+	}
+
+	total := t.opts.Timeout
+	if total > 0 && t.opts.TimeoutPerMB > 0 && size > 0 {
+		total += time.Duration(float64(t.opts.TimeoutPerMB) * float64(size) / (1 << 20))
+	}
+
+	if total > 0 {
+		t.total = time.AfterFunc(total-time.Since(t.start), t.expire("total"))
+	}
+}
+
+// reader returns a reader that resets the idle timeout every time data is
+// read from r.
+func (t *downloadTimer) reader(r io.Reader) io.Reader {
+	if t.idle == nil {
+		return r
+	}
+	return &idleReader{r, t}
+}
+
+// check returns an error explaining which timeout expired if err was
+// caused by one of them.
+func (t *downloadTimer) check(err error, url string) error {
+	t.mut.Lock()
+	expired := t.expired
+	t.mut.Unlock()
+
+	if err == nil || expired == "" {
+		return err
+	}
+
+	return newError(ErrUpstreamUnavailable, err, "datos: %s timeout downloading %q", expired, url)
+}
+
+func (t *downloadTimer) stop() {
+	for _, timer := range []*time.Timer{t.connect, t.idle, t.total} {
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+	t.cancel()
+}
+
+type idleReader struct {
+	r io.Reader
+	t *downloadTimer
+}
+
+func (r *idleReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	if n > 0 {
+		r.t.idle.Reset(r.t.opts.IdleTimeout)
+	}
+	return n, err
+}
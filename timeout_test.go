@@ -0,0 +1,76 @@
+package datos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stalledBody returns some data and then blocks until the request is
+// cancelled.
+type stalledBody struct {
+	ctx  context.Context
+	sent bool
+}
+
+func (b *stalledBody) Read(p []byte) (int, error) {
+	if !b.sent {
+		b.sent = true
+		return copy(p, "a,b\n"), nil
+	}
+
+	<-b.ctx.Done()
+	return 0, b.ctx.Err()
+}
+
+func (b *stalledBody) Close() error { return nil }
+
+func TestDownloadIdleTimeout(t *testing.T) {
+	c := &Client{c: &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode:    http.StatusOK,
+				Header:        make(http.Header),
+				Body:          &stalledBody{ctx: r.Context()},
+				ContentLength: -1,
+				Request:       r,
+			}, nil
+		}),
+	}}
+
+	var buf bytes.Buffer
+	_, err := c.DownloadDistribution(
+		context.Background(),
+		Distribution{AccessURL: "http://example.com/foo.csv"},
+		&buf,
+		DownloadOptions{IdleTimeout: 10 * time.Millisecond},
+	)
+
+	if !errors.Is(err, ErrUpstreamUnavailable) || !strings.Contains(err.Error(), "idle timeout") {
+		t.Errorf("expected idle timeout error, got: %v", err)
+	}
+
+	if buf.String() != "a,b\n" {
+		t.Errorf("expected data before the timeout to be written, got: %q", buf.String())
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	hc := &http.Client{Timeout: time.Minute}
+	c, err := NewClient(WithHTTPClient(hc), WithTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if hc.Timeout != time.Minute {
+		t.Errorf("expected given HTTP client not to be modified, got timeout: %s", hc.Timeout)
+	}
+
+	if c.c.Timeout != time.Second {
+		t.Errorf("wrong timeout, expected: %s, got: %s", time.Second, c.c.Timeout)
+	}
+}